@@ -0,0 +1,290 @@
+package actor
+
+import (
+	"context"
+	"errors"
+	"gotest/repository"
+	"gotest/service"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultShards    = 64
+	DefaultQueueSize = 256
+)
+
+// ErrClosed is the cause of the unavailable error returned once Close has
+// been called.
+var ErrClosed = errors.New("wallet actors closed")
+
+type command struct {
+	ctx   context.Context
+	run   func() error
+	outer bool
+	done  chan struct{}
+	err   error
+}
+
+// walletService runs every command that changes a wallet on the goroutine
+// of the wallet's shard, so commands for one wallet never overlap and
+// wallets on different shards proceed in parallel. Commands spanning
+// several wallets hold each of their shards, taken in ascending order so
+// two of them cannot wait on each other. Reads and OpenAccount go straight
+// to next.
+type walletService struct {
+	next      service.WalletService
+	shards    int
+	queueSize int
+
+	mu        sync.RWMutex
+	closed    bool
+	outer     sync.WaitGroup
+	closeOnce sync.Once
+	queues    []chan *command
+	wg        sync.WaitGroup
+}
+
+type Option func(s *walletService)
+
+func WithShards(shards int) Option {
+	return func(s *walletService) {
+		if shards > 0 {
+			s.shards = shards
+		}
+	}
+}
+
+// WithQueueSize bounds the commands waiting on each shard; once it is full
+// further commands fail with service.ErrBusy instead of waiting.
+func WithQueueSize(size int) Option {
+	return func(s *walletService) {
+		if size > 0 {
+			s.queueSize = size
+		}
+	}
+}
+
+func NewWalletService(next service.WalletService, opts ...Option) *walletService {
+	s := &walletService{next: next, shards: DefaultShards, queueSize: DefaultQueueSize}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.queues = make([]chan *command, s.shards)
+	for i := range s.queues {
+		s.queues[i] = make(chan *command, s.queueSize)
+		s.wg.Add(1)
+		go s.loop(s.queues[i])
+	}
+	return s
+}
+
+// loop skips commands whose caller gave up while they were queued.
+func (s *walletService) loop(queue chan *command) {
+	defer s.wg.Done()
+	for c := range queue {
+		if err := c.ctx.Err(); err != nil {
+			c.err = contextError(err)
+		} else {
+			c.err = c.run()
+		}
+		close(c.done)
+		if c.outer {
+			s.outer.Done()
+		}
+	}
+}
+
+// Close stops accepting commands and waits until the queued ones have run
+// or ctx ends. The queues stay open until every accepted command has
+// finished, since commands spanning several shards still enqueue on the
+// others while they run.
+func (s *walletService) Close(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.outer.Wait()
+		s.closeOnce.Do(func() {
+			for _, queue := range s.queues {
+				close(queue)
+			}
+		})
+		s.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue queues run on shard. Only outer commands, the ones callers submit,
+// are refused once Close has been called; the inner ones hold enqueues for
+// an outer command already accepted.
+func (s *walletService) enqueue(ctx context.Context, shard int, run func() error, outer bool) (*command, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if outer {
+		if s.closed {
+			return nil, service.ErrUnavailable.Wrap(ErrClosed)
+		}
+		s.outer.Add(1)
+	}
+
+	c := &command{ctx: ctx, run: run, outer: outer, done: make(chan struct{})}
+	select {
+	case s.queues[shard] <- c:
+		return c, nil
+	default:
+		if outer {
+			s.outer.Done()
+		}
+		return nil, service.NewErrorBusy()
+	}
+}
+
+// serial runs run once it holds the shards of every wallet in ids. The
+// caller stops waiting when ctx ends; the command is then skipped if it has
+// not started yet.
+func (s *walletService) serial(ctx context.Context, ids []uint64, run func() error) error {
+	shards := make([]int, 0, len(ids))
+	for _, id := range ids {
+		shards = append(shards, int(id%uint64(s.shards)))
+	}
+	slices.Sort(shards)
+	shards = slices.Compact(shards)
+	if len(shards) == 0 {
+		return run()
+	}
+
+	c, err := s.enqueue(ctx, shards[0], s.hold(ctx, shards[1:], run), true)
+	if err != nil {
+		return err
+	}
+	select {
+	case <-c.done:
+		return c.err
+	case <-ctx.Done():
+		return contextError(ctx.Err())
+	}
+}
+
+// hold wraps run so that, running on one shard, it keeps that shard busy
+// until run has finished on the remaining shards. It waits for the inner
+// command even after ctx ends, since releasing the shard earlier would let
+// another command overlap run.
+func (s *walletService) hold(ctx context.Context, shards []int, run func() error) func() error {
+	if len(shards) == 0 {
+		return run
+	}
+	return func() error {
+		c, err := s.enqueue(ctx, shards[0], s.hold(ctx, shards[1:], run), false)
+		if err != nil {
+			return err
+		}
+		<-c.done
+		return c.err
+	}
+}
+
+// submit is serial for calls with a result, which is only handed over once
+// run has returned.
+func submit[T any](s *walletService, ctx context.Context, ids []uint64, run func() (T, error)) (T, error) {
+	results := make(chan T, 1)
+	err := s.serial(ctx, ids, func() error {
+		result, err := run()
+		results <- result
+		return err
+	})
+
+	select {
+	case result := <-results:
+		return result, err
+	default:
+		var zero T
+		return zero, err
+	}
+}
+
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return service.ErrTimeout.Wrap(err)
+	}
+	return service.ErrCanceled.Wrap(err)
+}
+
+func (s *walletService) OpenAccount(ctx context.Context, wallet *repository.Wallet) error {
+	return s.next.OpenAccount(ctx, wallet)
+}
+
+func (s *walletService) GetAccount(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	return s.next.GetAccount(ctx, id)
+}
+
+func (s *walletService) ListAccounts(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) ([]repository.Wallet, string, error) {
+	return s.next.ListAccounts(ctx, filter, sort, cursor, limit)
+}
+
+func (s *walletService) Withdraw(ctx context.Context, id uint64, amount float32) (float32, error) {
+	return submit(s, ctx, []uint64{id}, func() (float32, error) {
+		return s.next.Withdraw(ctx, id, amount)
+	})
+}
+
+func (s *walletService) Deposit(ctx context.Context, id uint64, amount float32) (float32, error) {
+	return submit(s, ctx, []uint64{id}, func() (float32, error) {
+		return s.next.Deposit(ctx, id, amount)
+	})
+}
+
+func (s *walletService) Transfer(ctx context.Context, fromID uint64, toID uint64, amount float32) error {
+	return s.serial(ctx, []uint64{fromID, toID}, func() error {
+		return s.next.Transfer(ctx, fromID, toID, amount)
+	})
+}
+
+func (s *walletService) History(ctx context.Context, id uint64) ([]service.Transaction, error) {
+	return s.next.History(ctx, id)
+}
+
+func (s *walletService) GetBalanceAt(ctx context.Context, id uint64, at time.Time) (float32, error) {
+	return s.next.GetBalanceAt(ctx, id, at)
+}
+
+func (s *walletService) AdjustBalance(ctx context.Context, id uint64, balance float32, reason string) error {
+	return s.serial(ctx, []uint64{id}, func() error {
+		return s.next.AdjustBalance(ctx, id, balance, reason)
+	})
+}
+
+type statusChange struct {
+	wallet  *repository.Wallet
+	changed bool
+}
+
+func (s *walletService) SetStatus(ctx context.Context, id uint64, status string, reason string) (*repository.Wallet, bool, error) {
+	result, err := submit(s, ctx, []uint64{id}, func() (statusChange, error) {
+		wallet, changed, err := s.next.SetStatus(ctx, id, status, reason)
+		return statusChange{wallet: wallet, changed: changed}, err
+	})
+	return result.wallet, result.changed, err
+}
+
+// Batch holds the shard of every wallet it touches, so a large batch may
+// hold them all while it runs.
+func (s *walletService) Batch(ctx context.Context, ops []service.Operation, mode string) ([]service.OperationResult, error) {
+	ids := make([]uint64, len(ops))
+	for i, op := range ops {
+		ids[i] = op.WalletID
+	}
+	return submit(s, ctx, ids, func() ([]service.OperationResult, error) {
+		return s.next.Batch(ctx, ops, mode)
+	})
+}
@@ -0,0 +1,240 @@
+// go:build unit
+package actor_test
+
+import (
+	"context"
+	"gotest/actor"
+	"gotest/repository"
+	"gotest/service"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gatedService reports each deposit as it starts and holds it until
+// release is closed.
+type gatedService struct {
+	service.WalletService
+	started chan uint64
+	release chan struct{}
+}
+
+func newGatedService() gatedService {
+	return gatedService{
+		WalletService: service.NewWalletService(repository.NewMemoryWalletRepository()),
+		started:       make(chan uint64, 100),
+		release:       make(chan struct{}),
+	}
+}
+
+func (s gatedService) Deposit(ctx context.Context, id uint64, amount float32) (float32, error) {
+	s.started <- id
+	<-s.release
+	return s.WalletService.Deposit(ctx, id, amount)
+}
+
+// slowRepository widens the gap between reading a wallet and writing it
+// back, where unserialized commands lose each other's updates.
+type slowRepository struct {
+	repository.WalletRepository
+}
+
+func (r slowRepository) Get(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	wallet, err := r.WalletRepository.Get(ctx, id)
+	time.Sleep(time.Millisecond)
+	return wallet, err
+}
+
+func newSlowService() service.WalletService {
+	return service.NewWalletService(slowRepository{repository.NewMemoryWalletRepository()})
+}
+
+func openWallets(t *testing.T, serv service.WalletService, n int) {
+	for i := 0; i < n; i++ {
+		if err := serv.OpenAccount(context.Background(), &repository.Wallet{Name: "John Doe", Balance: 100}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSerialExecution(t *testing.T) {
+	t.Run("Concurrent Deposits", func(t *testing.T) {
+		// arrange
+		serv := actor.NewWalletService(newSlowService(), actor.WithShards(4))
+		openWallets(t, serv, 3)
+		// act
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			for id := uint64(1); id <= 3; id++ {
+				wg.Add(1)
+				go func(id uint64) {
+					defer wg.Done()
+					serv.Deposit(context.Background(), id, 1)
+				}(id)
+			}
+		}
+		wg.Wait()
+		// assert
+		for id := uint64(1); id <= 3; id++ {
+			wallet, _ := serv.GetAccount(context.Background(), id)
+			assert.Equal(t, float32(150), wallet.Balance)
+		}
+	})
+
+	t.Run("Concurrent Transfers", func(t *testing.T) {
+		// arrange
+		serv := actor.NewWalletService(newSlowService(), actor.WithShards(4))
+		openWallets(t, serv, 4)
+		// act
+		var wg sync.WaitGroup
+		for i := 0; i < 40; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				serv.Transfer(context.Background(), uint64(i%4)+1, uint64((i+1)%4)+1, 1)
+			}(i)
+			go func(i int) {
+				defer wg.Done()
+				serv.Deposit(context.Background(), uint64(i%4)+1, 1)
+			}(i)
+		}
+		wg.Wait()
+		// assert
+		total := float32(0)
+		for id := uint64(1); id <= 4; id++ {
+			wallet, _ := serv.GetAccount(context.Background(), id)
+			total += wallet.Balance
+		}
+		assert.Equal(t, float32(440), total)
+	})
+
+	t.Run("Wallets On Other Shards Proceed", func(t *testing.T) {
+		// arrange
+		next := newGatedService()
+		serv := actor.NewWalletService(next, actor.WithShards(2))
+		openWallets(t, serv, 2)
+		// act
+		go serv.Deposit(context.Background(), 1, 10)
+		go serv.Deposit(context.Background(), 2, 10)
+		first, second := <-next.started, <-next.started
+		close(next.release)
+		// assert
+		assert.ElementsMatch(t, []uint64{1, 2}, []uint64{first, second})
+	})
+
+	t.Run("Transfer Holds Both Wallets", func(t *testing.T) {
+		// arrange
+		next := newGatedService()
+		serv := actor.NewWalletService(next, actor.WithShards(2))
+		openWallets(t, serv, 2)
+		go serv.Deposit(context.Background(), 1, 10)
+		<-next.started
+		// act
+		transferred := make(chan error)
+		go func() { transferred <- serv.Transfer(context.Background(), 2, 1, 50) }()
+		time.Sleep(10 * time.Millisecond)
+		go serv.Deposit(context.Background(), 2, 10)
+		time.Sleep(20 * time.Millisecond)
+		var started []uint64
+		select {
+		case id := <-next.started:
+			started = append(started, id)
+		default:
+		}
+		close(next.release)
+		err := <-transferred
+		// assert
+		assert.NoError(t, err)
+		assert.Empty(t, started)
+	})
+}
+
+func TestBackpressure(t *testing.T) {
+	// arrange
+	next := newGatedService()
+	serv := actor.NewWalletService(next, actor.WithShards(1), actor.WithQueueSize(1))
+	openWallets(t, serv, 1)
+	go serv.Deposit(context.Background(), 1, 10)
+	<-next.started
+	go serv.Deposit(context.Background(), 1, 10)
+	time.Sleep(20 * time.Millisecond)
+	// act
+	_, err := serv.Withdraw(context.Background(), 1, 10)
+	close(next.release)
+	// assert
+	assert.ErrorIs(t, err, service.ErrBusy)
+}
+
+func TestCanceledWhileQueued(t *testing.T) {
+	// arrange
+	next := newGatedService()
+	serv := actor.NewWalletService(next, actor.WithShards(1))
+	openWallets(t, serv, 1)
+	go serv.Deposit(context.Background(), 1, 10)
+	<-next.started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	// act
+	_, err := serv.Withdraw(ctx, 1, 100)
+	close(next.release)
+	serv.Close(context.Background())
+	// assert
+	assert.ErrorIs(t, err, service.ErrTimeout)
+	wallet, _ := serv.GetAccount(context.Background(), 1)
+	assert.Equal(t, float32(110), wallet.Balance)
+}
+
+func TestClose(t *testing.T) {
+	// arrange
+	next := newGatedService()
+	serv := actor.NewWalletService(next, actor.WithShards(1))
+	openWallets(t, serv, 1)
+	balances := make(chan float32, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			balance, _ := serv.Deposit(context.Background(), 1, 10)
+			balances <- balance
+		}()
+	}
+	<-next.started
+	time.Sleep(20 * time.Millisecond)
+	// act
+	closed := make(chan error)
+	go func() { closed <- serv.Close(context.Background()) }()
+	time.Sleep(20 * time.Millisecond)
+	_, rejected := serv.Deposit(context.Background(), 1, 10)
+	close(next.release)
+	err := <-closed
+	// assert
+	assert.NoError(t, err)
+	assert.ErrorIs(t, rejected, service.ErrUnavailable)
+	assert.ErrorIs(t, rejected, actor.ErrClosed)
+	assert.ElementsMatch(t, []float32{110, 120, 130}, []float32{<-balances, <-balances, <-balances})
+}
+
+func TestCloseWithQueuedTransfer(t *testing.T) {
+	// arrange
+	next := newGatedService()
+	serv := actor.NewWalletService(next, actor.WithShards(2))
+	openWallets(t, serv, 2)
+	go serv.Deposit(context.Background(), 2, 10)
+	<-next.started
+	transferred := make(chan error)
+	go func() { transferred <- serv.Transfer(context.Background(), 1, 2, 50) }()
+	time.Sleep(20 * time.Millisecond)
+	// act
+	closed := make(chan error)
+	go func() { closed <- serv.Close(context.Background()) }()
+	time.Sleep(20 * time.Millisecond)
+	close(next.release)
+	err := <-transferred
+	// assert
+	assert.NoError(t, <-closed)
+	assert.NoError(t, err)
+	from, _ := serv.GetAccount(context.Background(), 1)
+	to, _ := serv.GetAccount(context.Background(), 2)
+	assert.Equal(t, float32(50), from.Balance)
+	assert.Equal(t, float32(160), to.Balance)
+}
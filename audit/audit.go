@@ -0,0 +1,45 @@
+package audit
+
+import "time"
+
+const (
+	ActionOpenAccount   = "OPEN_ACCOUNT"
+	ActionWithdraw      = "WITHDRAW"
+	ActionDeposit       = "DEPOSIT"
+	ActionStatusChange  = "STATUS_CHANGE"
+	ActionAdmin         = "ADMIN"
+	ActionAdjustBalance = "ADJUST_BALANCE"
+)
+
+// Event is one audit log entry. Actor is the authenticated principal, or
+// "anonymous"; ClaimedActor is whatever name the caller gave for itself,
+// kept for reference but never verified.
+type Event struct {
+	Seq           uint64    `json:"seq"`
+	Time          time.Time `json:"time"`
+	Actor         string    `json:"actor"`
+	ClaimedActor  string    `json:"claimed_actor,omitempty"`
+	Action        string    `json:"action"`
+	WalletID      uint64    `json:"wallet_id"`
+	BalanceBefore float32   `json:"balance_before"`
+	BalanceAfter  float32   `json:"balance_after"`
+	RequestID     string    `json:"request_id"`
+	ClientIP      string    `json:"client_ip"`
+	Detail        string    `json:"detail,omitempty"`
+	PrevHash      string    `json:"prev_hash"`
+	Hash          string    `json:"hash"`
+}
+
+type Logger interface {
+	Record(event Event) error
+}
+
+type nopLogger struct{}
+
+func Nop() Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) Record(event Event) error {
+	return nil
+}
@@ -0,0 +1,16 @@
+package audit
+
+import "github.com/stretchr/testify/mock"
+
+type auditLoggerMock struct {
+	mock.Mock
+}
+
+func NewLoggerMock() *auditLoggerMock {
+	return &auditLoggerMock{}
+}
+
+func (m *auditLoggerMock) Record(event Event) error {
+	c := m.Called(event)
+	return c.Error(0)
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileSink appends one JSON event per line. Every event carries the hash of
+// the previous one, so editing or removing a line breaks the chain; the head
+// file written after each event catches lines cut from the end.
+type fileSink struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	seq      uint64
+	prevHash string
+	now      func() time.Time
+}
+
+func NewFileSink(path string) (*fileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := readHead(path)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	last, err := verify(file, head)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &fileSink{
+		path:     path,
+		file:     file,
+		seq:      last.Seq,
+		prevHash: last.Hash,
+		now:      time.Now,
+	}, nil
+}
+
+func (s *fileSink) Record(event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event.Seq = s.seq + 1
+	if event.Time.IsZero() {
+		event.Time = s.now().UTC()
+	}
+	event.PrevHash = s.prevHash
+	event.Hash = hashEvent(event)
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err := s.file.Write(line); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+
+	s.seq = event.Seq
+	s.prevHash = event.Hash
+	return writeHead(s.path, Head{Seq: event.Seq, Hash: event.Hash})
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+func hashEvent(event Event) string {
+	event.Hash = ""
+	body, _ := json.Marshal(event)
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,131 @@
+// go:build unit
+package audit_test
+
+import (
+	"bytes"
+	"gotest/audit"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeEvents(t *testing.T, path string, events ...audit.Event) {
+	sink, err := audit.NewFileSink(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer sink.Close()
+
+	for _, event := range events {
+		assert.NoError(t, sink.Record(event))
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "audit.log")
+		writeEvents(t, path,
+			audit.Event{Actor: "alice", Action: audit.ActionOpenAccount, WalletID: 1, BalanceAfter: 1000},
+			audit.Event{Actor: "alice", Action: audit.ActionWithdraw, WalletID: 1, BalanceBefore: 1000, BalanceAfter: 800},
+		)
+		file, _ := os.Open(path)
+		defer file.Close()
+		// act
+		last, err := audit.Verify(file)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), last.Seq)
+	})
+
+	t.Run("Continue Chain After Reopen", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "audit.log")
+		writeEvents(t, path, audit.Event{Actor: "alice", Action: audit.ActionDeposit, WalletID: 1})
+		writeEvents(t, path, audit.Event{Actor: "bob", Action: audit.ActionDeposit, WalletID: 2})
+		file, _ := os.Open(path)
+		defer file.Close()
+		// act
+		last, err := audit.Verify(file)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), last.Seq)
+		assert.Equal(t, "bob", last.Actor)
+	})
+
+	t.Run("Tampered Entry", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "audit.log")
+		writeEvents(t, path,
+			audit.Event{Actor: "alice", Action: audit.ActionDeposit, WalletID: 1, BalanceAfter: 100},
+			audit.Event{Actor: "alice", Action: audit.ActionDeposit, WalletID: 1, BalanceAfter: 200},
+		)
+		content, _ := os.ReadFile(path)
+		content = bytes.Replace(content, []byte(`"balance_after":100`), []byte(`"balance_after":900`), 1)
+		// act
+		_, err := audit.Verify(bytes.NewReader(content))
+		// assert
+		assert.ErrorIs(t, err, audit.ChainError{Line: 1, Message: "hash does not match content"})
+	})
+
+	t.Run("Removed Entry", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "audit.log")
+		writeEvents(t, path,
+			audit.Event{Actor: "alice", Action: audit.ActionDeposit, WalletID: 1},
+			audit.Event{Actor: "alice", Action: audit.ActionDeposit, WalletID: 1},
+			audit.Event{Actor: "alice", Action: audit.ActionDeposit, WalletID: 1},
+		)
+		content, _ := os.ReadFile(path)
+		lines := bytes.SplitAfter(content, []byte("\n"))
+		tampered := append(append([]byte{}, lines[0]...), lines[2]...)
+		// act
+		_, err := audit.Verify(bytes.NewReader(tampered))
+		// assert
+		var chainErr audit.ChainError
+		assert.ErrorAs(t, err, &chainErr)
+		assert.Equal(t, 2, chainErr.Line)
+	})
+
+	t.Run("Truncated Log", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "audit.log")
+		writeEvents(t, path,
+			audit.Event{Actor: "alice", Action: audit.ActionDeposit, WalletID: 1},
+			audit.Event{Actor: "alice", Action: audit.ActionDeposit, WalletID: 1},
+			audit.Event{Actor: "alice", Action: audit.ActionDeposit, WalletID: 1},
+		)
+		content, _ := os.ReadFile(path)
+		lines := bytes.SplitAfter(content, []byte("\n"))
+		os.WriteFile(path, bytes.Join(lines[:2], nil), 0o600)
+		// act
+		_, err := audit.VerifyFile(path)
+		_, sinkErr := audit.NewFileSink(path)
+		// assert
+		assert.ErrorIs(t, err, audit.ChainError{Line: 3, Message: "log ends at seq 2, head is seq 3"})
+		assert.Error(t, sinkErr)
+	})
+
+	t.Run("Missing Head", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "audit.log")
+		writeEvents(t, path, audit.Event{Actor: "alice", Action: audit.ActionDeposit, WalletID: 1})
+		os.Remove(audit.HeadPath(path))
+		// act
+		_, err := audit.VerifyFile(path)
+		// assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Refuse To Append To Broken Log", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "audit.log")
+		os.WriteFile(path, []byte("not json\n"), 0o600)
+		// act
+		_, err := audit.NewFileSink(path)
+		// assert
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"context"
+	"gotest/auth"
+	"gotest/logging"
+	"gotest/repository"
+	"gotest/service"
+	"log/slog"
+	"time"
+)
+
+// Origin is where a call came from, as far as the transport can tell.
+type Origin struct {
+	ClaimedActor string
+	ClientIP     string
+}
+
+type originKey struct{}
+
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+// OriginFrom returns the origin set by WithOrigin, or the zero Origin.
+func OriginFrom(ctx context.Context) Origin {
+	origin, _ := ctx.Value(originKey{}).(Origin)
+	return origin
+}
+
+type walletService struct {
+	next      service.WalletService
+	logger    Logger
+	onFailure func(ctx context.Context, event Event, err error)
+}
+
+type Option func(s *walletService)
+
+// WithFailureHook calls hook for every event that could not be recorded,
+// in place of logging it. It should alert: the change stands unaudited.
+func WithFailureHook(hook func(ctx context.Context, event Event, err error)) Option {
+	return func(s *walletService) {
+		s.onFailure = hook
+	}
+}
+
+// NewWalletService records every change next makes, so HTTP, gRPC and
+// walletctl calls end up in the same audit log. The actor is the
+// authenticated identity in the call's context, or "anonymous". Transfer,
+// which no transport exposes, and AdjustBalance, which the reconciler
+// records itself, are not audited here.
+//
+// A change is recorded once next has committed it, so a failed record does
+// not fail the call: reporting a committed withdrawal as failed would have
+// the client retry it and withdraw twice.
+func NewWalletService(next service.WalletService, logger Logger, opts ...Option) service.WalletService {
+	s := walletService{next: next, logger: logger, onFailure: logFailure}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+func logFailure(ctx context.Context, event Event, err error) {
+	slog.ErrorContext(ctx, "audit record lost",
+		slog.String("action", event.Action),
+		slog.Uint64("wallet_id", event.WalletID),
+		slog.Any("error", err),
+	)
+}
+
+func (s walletService) record(ctx context.Context, event Event) {
+	event.Actor = "anonymous"
+	if identity, ok := auth.IdentityFrom(ctx); ok {
+		event.Actor = identity.Name
+	}
+	origin := OriginFrom(ctx)
+	event.ClaimedActor = origin.ClaimedActor
+	event.ClientIP = origin.ClientIP
+	event.RequestID = logging.RequestID(ctx)
+	if err := s.logger.Record(event); err != nil {
+		s.onFailure(ctx, event, err)
+	}
+}
+
+func (s walletService) OpenAccount(ctx context.Context, wallet *repository.Wallet) error {
+	if err := s.next.OpenAccount(ctx, wallet); err != nil {
+		return err
+	}
+	s.record(ctx, Event{Action: ActionOpenAccount, WalletID: wallet.ID, BalanceAfter: wallet.Balance})
+	return nil
+}
+
+func (s walletService) GetAccount(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	return s.next.GetAccount(ctx, id)
+}
+
+func (s walletService) ListAccounts(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) ([]repository.Wallet, string, error) {
+	return s.next.ListAccounts(ctx, filter, sort, cursor, limit)
+}
+
+func (s walletService) Withdraw(ctx context.Context, id uint64, amount float32) (float32, error) {
+	balance, err := s.next.Withdraw(ctx, id, amount)
+	if err != nil {
+		return 0, err
+	}
+	s.record(ctx, Event{Action: ActionWithdraw, WalletID: id, BalanceBefore: balance + amount, BalanceAfter: balance})
+	return balance, nil
+}
+
+func (s walletService) Deposit(ctx context.Context, id uint64, amount float32) (float32, error) {
+	balance, err := s.next.Deposit(ctx, id, amount)
+	if err != nil {
+		return 0, err
+	}
+	s.record(ctx, Event{Action: ActionDeposit, WalletID: id, BalanceBefore: balance - amount, BalanceAfter: balance})
+	return balance, nil
+}
+
+func (s walletService) Transfer(ctx context.Context, fromID uint64, toID uint64, amount float32) error {
+	return s.next.Transfer(ctx, fromID, toID, amount)
+}
+
+func (s walletService) History(ctx context.Context, id uint64) ([]service.Transaction, error) {
+	return s.next.History(ctx, id)
+}
+
+func (s walletService) GetBalanceAt(ctx context.Context, id uint64, at time.Time) (float32, error) {
+	return s.next.GetBalanceAt(ctx, id, at)
+}
+
+func (s walletService) AdjustBalance(ctx context.Context, id uint64, balance float32, reason string) error {
+	return s.next.AdjustBalance(ctx, id, balance, reason)
+}
+
+// SetStatus only records a status that actually changed.
+func (s walletService) SetStatus(ctx context.Context, id uint64, status string, reason string) (*repository.Wallet, bool, error) {
+	wallet, changed, err := s.next.SetStatus(ctx, id, status, reason)
+	if err != nil || !changed {
+		return wallet, changed, err
+	}
+
+	detail := wallet.Status
+	if reason != "" {
+		detail += ": " + reason
+	}
+	s.record(ctx, Event{
+		Action:        ActionStatusChange,
+		WalletID:      wallet.ID,
+		BalanceBefore: wallet.Balance,
+		BalanceAfter:  wallet.Balance,
+		Detail:        detail,
+	})
+	return wallet, changed, nil
+}
+
+// Batch records each operation that was applied.
+func (s walletService) Batch(ctx context.Context, ops []service.Operation, mode string) ([]service.OperationResult, error) {
+	results, err := s.next.Batch(ctx, ops, mode)
+	if err != nil {
+		return results, err
+	}
+
+	for _, result := range results {
+		if result.Status != service.ResultOK {
+			continue
+		}
+		action := ActionDeposit
+		if ops[result.Index].Type == service.OperationWithdraw {
+			action = ActionWithdraw
+		}
+		s.record(ctx, Event{Action: action, WalletID: result.WalletID, BalanceBefore: result.Before, BalanceAfter: result.Balance})
+	}
+	return results, nil
+}
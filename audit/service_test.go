@@ -0,0 +1,118 @@
+// go:build unit
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"gotest/audit"
+	"gotest/auth"
+	"gotest/logging"
+	"gotest/repository"
+	"gotest/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// memoryLogger keeps recorded events in order.
+type memoryLogger struct {
+	events []audit.Event
+}
+
+func (l *memoryLogger) Record(event audit.Event) error {
+	l.events = append(l.events, event)
+	return nil
+}
+
+func TestWalletService(t *testing.T) {
+	t.Run("Records Changes", func(t *testing.T) {
+		// arrange
+		logger := &memoryLogger{}
+		serv := audit.NewWalletService(service.NewWalletService(repository.NewMemoryWalletRepository()), logger)
+		ctx := audit.WithOrigin(logging.WithRequestID(context.Background(), "req-1"), audit.Origin{ClaimedActor: "mallory", ClientIP: "10.0.0.7"})
+		ctx = auth.WithIdentity(ctx, auth.Identity{Name: "alice"})
+		wallet := repository.Wallet{Name: "John Doe", Balance: 100}
+		// act
+		serv.OpenAccount(ctx, &wallet)
+		serv.Deposit(ctx, wallet.ID, 50)
+		serv.Withdraw(ctx, wallet.ID, 30)
+		serv.SetStatus(ctx, wallet.ID, repository.StatusActive, "")
+		serv.SetStatus(ctx, wallet.ID, repository.StatusFrozen, "fraud check")
+		// assert
+		assert.Equal(t, []audit.Event{
+			{Actor: "alice", ClaimedActor: "mallory", Action: audit.ActionOpenAccount, WalletID: 1, BalanceAfter: 100, RequestID: "req-1", ClientIP: "10.0.0.7"},
+			{Actor: "alice", ClaimedActor: "mallory", Action: audit.ActionDeposit, WalletID: 1, BalanceBefore: 100, BalanceAfter: 150, RequestID: "req-1", ClientIP: "10.0.0.7"},
+			{Actor: "alice", ClaimedActor: "mallory", Action: audit.ActionWithdraw, WalletID: 1, BalanceBefore: 150, BalanceAfter: 120, RequestID: "req-1", ClientIP: "10.0.0.7"},
+			{Actor: "alice", ClaimedActor: "mallory", Action: audit.ActionStatusChange, WalletID: 1, BalanceBefore: 120, BalanceAfter: 120, RequestID: "req-1", ClientIP: "10.0.0.7", Detail: "FROZEN: fraud check"},
+		}, logger.events)
+	})
+
+	t.Run("Anonymous Caller", func(t *testing.T) {
+		// arrange
+		logger := &memoryLogger{}
+		serv := audit.NewWalletService(service.NewWalletService(repository.NewMemoryWalletRepository()), logger)
+		// act
+		serv.OpenAccount(context.Background(), &repository.Wallet{Name: "John Doe", Balance: 100})
+		// assert
+		assert.Len(t, logger.events, 1)
+		assert.Equal(t, "anonymous", logger.events[0].Actor)
+	})
+
+	t.Run("Skips Failed Changes", func(t *testing.T) {
+		// arrange
+		auditLog := audit.NewLoggerMock()
+		next := service.NewWalletServiceMock()
+		next.On("Withdraw", uint64(1), float32(500)).Return(float32(0), service.NewErrorNotEnoughMoney())
+		serv := audit.NewWalletService(next, auditLog)
+		// act
+		_, err := serv.Withdraw(context.Background(), 1, 500)
+		// assert
+		assert.ErrorIs(t, err, service.ErrNotEnoughMoney)
+		auditLog.AssertNotCalled(t, "Record", mock.Anything)
+	})
+
+	t.Run("Record Failure Does Not Fail The Change", func(t *testing.T) {
+		// arrange
+		auditLog := audit.NewLoggerMock()
+		auditLog.On("Record", mock.Anything).Return(errors.New("disk full"))
+		next := service.NewWalletServiceMock()
+		next.On("Withdraw", uint64(1), float32(30)).Return(float32(70), nil)
+		var failed []audit.Event
+		serv := audit.NewWalletService(next, auditLog, audit.WithFailureHook(func(ctx context.Context, event audit.Event, err error) {
+			failed = append(failed, event)
+		}))
+		// act
+		balance, err := serv.Withdraw(context.Background(), 1, 30)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, float32(70), balance)
+		assert.Equal(t, []audit.Event{
+			{Actor: "anonymous", Action: audit.ActionWithdraw, WalletID: 1, BalanceBefore: 100, BalanceAfter: 70},
+		}, failed)
+	})
+
+	t.Run("Batch Records Applied Operations", func(t *testing.T) {
+		// arrange
+		logger := &memoryLogger{}
+		ops := []service.Operation{
+			{WalletID: 1, Type: service.OperationDeposit, Amount: 10},
+			{WalletID: 1, Type: service.OperationWithdraw, Amount: 500},
+			{WalletID: 2, Type: service.OperationWithdraw, Amount: 5},
+		}
+		next := service.NewWalletServiceMock()
+		next.On("Batch", ops, service.BatchBestEffort).Return([]service.OperationResult{
+			{Index: 0, WalletID: 1, Status: service.ResultOK, Before: 100, Balance: 110},
+			{Index: 1, WalletID: 1, Status: service.ResultFailed},
+			{Index: 2, WalletID: 2, Status: service.ResultOK, Before: 50, Balance: 45},
+		}, nil)
+		serv := audit.NewWalletService(next, logger)
+		// act
+		serv.Batch(context.Background(), ops, service.BatchBestEffort)
+		// assert
+		assert.Equal(t, []audit.Event{
+			{Actor: "anonymous", Action: audit.ActionDeposit, WalletID: 1, BalanceBefore: 100, BalanceAfter: 110},
+			{Actor: "anonymous", Action: audit.ActionWithdraw, WalletID: 2, BalanceBefore: 50, BalanceAfter: 45},
+		}, logger.events)
+	})
+}
@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+type ChainError struct {
+	Line    int
+	Message string
+}
+
+func (e ChainError) Error() string {
+	return fmt.Sprintf("audit log line %d: %s", e.Line, e.Message)
+}
+
+// Head identifies the last event a sink wrote. It is kept in a file beside
+// the log, since the chain alone cannot tell a log cut short from a short log.
+type Head struct {
+	Seq  uint64 `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+func HeadPath(path string) string {
+	return path + ".head"
+}
+
+// Verify walks the whole log and checks sequence numbers and hash links. It
+// returns the last valid event so a sink can continue the chain.
+func Verify(r io.Reader) (Event, error) {
+	return verify(r, Head{})
+}
+
+// VerifyFile verifies the log at path and checks that it reaches the head
+// recorded beside it. A log ahead of its head is accepted: the sink writes the
+// head after the entry, so a crash in between leaves the head one behind.
+func VerifyFile(path string) (Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Event{}, err
+	}
+	defer file.Close()
+
+	head, err := readHead(path)
+	if err != nil {
+		return Event{}, err
+	}
+	return verify(file, head)
+}
+
+func verify(r io.Reader, head Head) (Event, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	last := Event{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		event := Event{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return last, ChainError{Line: line, Message: "malformed entry"}
+		}
+		if event.Seq != last.Seq+1 {
+			return last, ChainError{Line: line, Message: fmt.Sprintf("expected seq %d, got %d", last.Seq+1, event.Seq)}
+		}
+		if event.PrevHash != last.Hash {
+			return last, ChainError{Line: line, Message: "previous hash does not match"}
+		}
+		if event.Hash != hashEvent(event) {
+			return last, ChainError{Line: line, Message: "hash does not match content"}
+		}
+		if event.Seq == head.Seq && event.Hash != head.Hash {
+			return last, ChainError{Line: line, Message: "hash does not match the recorded head"}
+		}
+		last = event
+	}
+	if err := scanner.Err(); err != nil {
+		return last, err
+	}
+	if last.Seq < head.Seq {
+		return last, ChainError{Line: line + 1, Message: fmt.Sprintf("log ends at seq %d, head is seq %d", last.Seq, head.Seq)}
+	}
+
+	return last, nil
+}
+
+// readHead returns the zero Head for a log that has never been written to. A
+// log with entries but no head has lost it, which hides truncation as well.
+func readHead(path string) (Head, error) {
+	head := Head{}
+	body, err := os.ReadFile(HeadPath(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		info, statErr := os.Stat(path)
+		if statErr == nil && info.Size() > 0 {
+			return head, fmt.Errorf("audit log %s has no head file", path)
+		}
+		return head, nil
+	}
+	if err != nil {
+		return head, err
+	}
+	if err := json.Unmarshal(body, &head); err != nil {
+		return head, fmt.Errorf("audit head %s: %w", HeadPath(path), err)
+	}
+	return head, nil
+}
+
+// writeHead replaces the head file in one rename, so a crash leaves either
+// the old head or the new one.
+func writeHead(path string, head Head) error {
+	body, err := json.Marshal(head)
+	if err != nil {
+		return err
+	}
+
+	tmp := HeadPath(path) + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(body); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, HeadPath(path))
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ScopeAdmin allows changing wallet statuses and managing webhooks.
+const ScopeAdmin = "admin"
+
+type identityKey struct{}
+
+type Authorizer interface {
+	CanReadWallet(token string, walletID uint64) bool
+}
+
+type Authenticator interface {
+	Authenticate(token string) (Identity, bool)
+}
+
+// Identity is who an access token belongs to and what it may do.
+type Identity struct {
+	Name   string
+	Scopes []string
+}
+
+func (i Identity) Has(scope string) bool {
+	return slices.Contains(i.Scopes, scope)
+}
+
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFrom returns the identity set by WithIdentity, if any.
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// Tokens maps an access token to its identity.
+type Tokens map[string]Identity
+
+func (t Tokens) Authenticate(token string) (Identity, bool) {
+	if token == "" {
+		return Identity{}, false
+	}
+	identity, ok := t[token]
+	return identity, ok
+}
+
+// ParseTokens reads "token=name:scope,scope;token2=name2" style definitions.
+func ParseTokens(s string) (Tokens, error) {
+	tokens := Tokens{}
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		token, rest, ok := strings.Cut(entry, "=")
+		name, scopes, _ := strings.Cut(rest, ":")
+		if !ok || token == "" || name == "" {
+			return nil, fmt.Errorf("invalid token %q", entry)
+		}
+		if _, ok := tokens[token]; ok {
+			return nil, fmt.Errorf("duplicate token in %q", entry)
+		}
+
+		identity := Identity{Name: name}
+		for _, scope := range strings.Split(scopes, ",") {
+			if scope != "" {
+				identity.Scopes = append(identity.Scopes, scope)
+			}
+		}
+		tokens[token] = identity
+	}
+	return tokens, nil
+}
+
+// Grants maps an access token to the wallets it may read. A grant for wallet
+// 0 covers every wallet.
+type Grants map[string][]uint64
+
+func (g Grants) CanReadWallet(token string, walletID uint64) bool {
+	if token == "" {
+		return false
+	}
+	for _, id := range g[token] {
+		if id == 0 || id == walletID {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseGrants reads "token=1,2;admin=*" style definitions.
+func ParseGrants(s string) (Grants, error) {
+	grants := Grants{}
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		token, wallets, ok := strings.Cut(entry, "=")
+		if !ok || token == "" {
+			return nil, fmt.Errorf("invalid grant %q", entry)
+		}
+
+		for _, wallet := range strings.Split(wallets, ",") {
+			if wallet == "*" {
+				grants[token] = append(grants[token], 0)
+				continue
+			}
+			id, err := strconv.ParseUint(wallet, 10, 64)
+			if err != nil || id == 0 {
+				return nil, fmt.Errorf("invalid wallet id %q in grant %q", wallet, entry)
+			}
+			grants[token] = append(grants[token], id)
+		}
+	}
+	return grants, nil
+}
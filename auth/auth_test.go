@@ -0,0 +1,53 @@
+// go:build unit
+package auth_test
+
+import (
+	"gotest/auth"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGrants(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// act
+		grants, err := auth.ParseGrants("alice=1,2; ops=*")
+		// assert
+		assert.NoError(t, err)
+		assert.True(t, grants.CanReadWallet("alice", 2))
+		assert.False(t, grants.CanReadWallet("alice", 3))
+		assert.True(t, grants.CanReadWallet("ops", 3))
+		assert.False(t, grants.CanReadWallet("", 1))
+	})
+
+	t.Run("Invalid Wallet", func(t *testing.T) {
+		// act
+		_, err := auth.ParseGrants("alice=abc")
+		// assert
+		assert.Error(t, err)
+	})
+}
+
+func TestParseTokens(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// act
+		tokens, err := auth.ParseTokens("s3cret=alice:admin; r34d=bob")
+		// assert
+		assert.NoError(t, err)
+		alice, ok := tokens.Authenticate("s3cret")
+		assert.True(t, ok)
+		assert.Equal(t, "alice", alice.Name)
+		assert.True(t, alice.Has(auth.ScopeAdmin))
+		bob, _ := tokens.Authenticate("r34d")
+		assert.False(t, bob.Has(auth.ScopeAdmin))
+		_, ok = tokens.Authenticate("")
+		assert.False(t, ok)
+	})
+
+	t.Run("Missing Name", func(t *testing.T) {
+		// act
+		_, err := auth.ParseTokens("s3cret=:admin")
+		// assert
+		assert.Error(t, err)
+	})
+}
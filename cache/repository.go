@@ -0,0 +1,230 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"gotest/repository"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTTL  = time.Second
+	DefaultSize = 10000
+)
+
+type entry struct {
+	id      uint64
+	wallet  repository.Wallet
+	expires time.Time
+}
+
+// call is a storage read shared by every Get that misses on the same wallet
+// while it runs.
+type call struct {
+	done   chan struct{}
+	wallet repository.Wallet
+	err    error
+}
+
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+}
+
+// walletRepository is a read-through cache in front of Get. Wallets are
+// kept for ttl and at most size of them, dropping the least recently used
+// first. Writes made through it invalidate the wallets they touch; writes
+// made around it show up once the ttl has passed.
+type walletRepository struct {
+	repository.WalletRepository
+	ttl  time.Duration
+	size int
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[uint64]*list.Element
+	order   *list.List
+	calls   map[uint64]*call
+	stats   Stats
+}
+
+type Option func(r *walletRepository)
+
+func WithTTL(ttl time.Duration) Option {
+	return func(r *walletRepository) {
+		if ttl > 0 {
+			r.ttl = ttl
+		}
+	}
+}
+
+func WithSize(size int) Option {
+	return func(r *walletRepository) {
+		if size > 0 {
+			r.size = size
+		}
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(r *walletRepository) {
+		r.now = now
+	}
+}
+
+func NewWalletRepository(next repository.WalletRepository, opts ...Option) *walletRepository {
+	r := &walletRepository{
+		WalletRepository: next,
+		ttl:              DefaultTTL,
+		size:             DefaultSize,
+		now:              time.Now,
+		entries:          map[uint64]*list.Element{},
+		order:            list.New(),
+		calls:            map[uint64]*call{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Get returns a copy of the cached wallet, so callers may change it freely.
+// Concurrent misses on one wallet share a single read of next, which runs
+// detached from any one caller's cancellation; each caller still stops
+// waiting when its own ctx ends.
+func (r *walletRepository) Get(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if wallet, ok := r.lookup(id); ok {
+		r.stats.Hits++
+		r.mu.Unlock()
+		return &wallet, nil
+	}
+	r.stats.Misses++
+	c, ok := r.calls[id]
+	if !ok {
+		c = &call{done: make(chan struct{})}
+		r.calls[id] = c
+		go r.load(context.WithoutCancel(ctx), id, c)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-c.done:
+		if c.err != nil {
+			return nil, c.err
+		}
+		wallet := c.wallet
+		return &wallet, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load keeps the result only if no write invalidated the wallet while it
+// was being read. Wallets that do not exist are not cached.
+func (r *walletRepository) load(ctx context.Context, id uint64, c *call) {
+	wallet, err := r.WalletRepository.Get(ctx, id)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c.err = err
+	if err == nil {
+		c.wallet = *wallet
+	}
+	if r.calls[id] == c {
+		delete(r.calls, id)
+		if err == nil && c.wallet.ID != 0 {
+			r.store(c.wallet)
+		}
+	}
+	close(c.done)
+}
+
+func (r *walletRepository) lookup(id uint64) (repository.Wallet, bool) {
+	element, ok := r.entries[id]
+	if !ok {
+		return repository.Wallet{}, false
+	}
+
+	e := element.Value.(*entry)
+	if !r.now().Before(e.expires) {
+		r.remove(element)
+		return repository.Wallet{}, false
+	}
+	r.order.MoveToFront(element)
+	return e.wallet, true
+}
+
+func (r *walletRepository) store(wallet repository.Wallet) {
+	expires := r.now().Add(r.ttl)
+	if element, ok := r.entries[wallet.ID]; ok {
+		element.Value = &entry{id: wallet.ID, wallet: wallet, expires: expires}
+		r.order.MoveToFront(element)
+		return
+	}
+
+	r.entries[wallet.ID] = r.order.PushFront(&entry{id: wallet.ID, wallet: wallet, expires: expires})
+	for r.order.Len() > r.size {
+		r.remove(r.order.Back())
+		r.stats.Evictions++
+	}
+}
+
+func (r *walletRepository) remove(element *list.Element) {
+	r.order.Remove(element)
+	delete(r.entries, element.Value.(*entry).id)
+}
+
+// invalidate runs after a write. It drops the wallets and detaches reads of
+// them still in flight, so a read that started before the write cannot
+// cache what it saw.
+func (r *walletRepository) invalidate(ids ...uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		if element, ok := r.entries[id]; ok {
+			r.remove(element)
+		}
+		delete(r.calls, id)
+	}
+}
+
+func (r *walletRepository) Create(ctx context.Context, wallet *repository.Wallet, events ...repository.Event) error {
+	err := r.WalletRepository.Create(ctx, wallet, events...)
+	r.invalidate(wallet.ID)
+	return err
+}
+
+// Update invalidates the wallet even when the write fails: a version
+// conflict means the cached copy is already stale.
+func (r *walletRepository) Update(ctx context.Context, id uint64, wallet *repository.Wallet, events ...repository.Event) error {
+	err := r.WalletRepository.Update(ctx, id, wallet, events...)
+	r.invalidate(id)
+	return err
+}
+
+func (r *walletRepository) UpdateMany(ctx context.Context, wallets []*repository.Wallet, events ...repository.Event) error {
+	ids := make([]uint64, len(wallets))
+	for i, wallet := range wallets {
+		ids[i] = wallet.ID
+	}
+
+	err := r.WalletRepository.UpdateMany(ctx, wallets, events...)
+	r.invalidate(ids...)
+	return err
+}
+
+func (r *walletRepository) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.stats
+	stats.Size = r.order.Len()
+	return stats
+}
@@ -0,0 +1,330 @@
+// go:build unit
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotest/cache"
+	"gotest/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func TestGet(t *testing.T) {
+	wallet := repository.Wallet{ID: 1, Name: "John Doe", Balance: 100}
+
+	t.Run("Hit After Miss", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return(&wallet, nil)
+		repo := cache.NewWalletRepository(next)
+		// act
+		first, _ := repo.Get(context.Background(), 1)
+		second, err := repo.Get(context.Background(), 1)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, wallet, *first)
+		assert.Equal(t, wallet, *second)
+		next.AssertNumberOfCalls(t, "Get", 1)
+		assert.Equal(t, cache.Stats{Hits: 1, Misses: 1, Size: 1}, repo.Stats())
+	})
+
+	t.Run("Returns Copies", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return(&repository.Wallet{ID: 1, Balance: 100}, nil)
+		repo := cache.NewWalletRepository(next)
+		first, _ := repo.Get(context.Background(), 1)
+		// act
+		first.Balance = 0
+		second, _ := repo.Get(context.Background(), 1)
+		// assert
+		assert.Equal(t, float32(100), second.Balance)
+	})
+
+	t.Run("Expires After TTL", func(t *testing.T) {
+		// arrange
+		c := &clock{now: time.Unix(1700000000, 0)}
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return(&wallet, nil)
+		repo := cache.NewWalletRepository(next, cache.WithTTL(time.Second), cache.WithClock(c.Now))
+		repo.Get(context.Background(), 1)
+		// act
+		c.now = c.now.Add(999 * time.Millisecond)
+		repo.Get(context.Background(), 1)
+		c.now = c.now.Add(time.Millisecond)
+		repo.Get(context.Background(), 1)
+		// assert
+		next.AssertNumberOfCalls(t, "Get", 2)
+	})
+
+	t.Run("Evicts Least Recently Used", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		for id := uint64(1); id <= 3; id++ {
+			next.On("Get", id).Return(&repository.Wallet{ID: id}, nil)
+		}
+		repo := cache.NewWalletRepository(next, cache.WithSize(2))
+		repo.Get(context.Background(), 1)
+		repo.Get(context.Background(), 2)
+		repo.Get(context.Background(), 1)
+		// act
+		repo.Get(context.Background(), 3)
+		repo.Get(context.Background(), 1)
+		repo.Get(context.Background(), 2)
+		// assert
+		next.AssertNumberOfCalls(t, "Get", 4)
+		assert.Equal(t, uint64(2), repo.Stats().Evictions)
+		assert.Equal(t, 2, repo.Stats().Size)
+	})
+
+	t.Run("Skips Missing Wallets And Errors", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return(&repository.Wallet{}, nil)
+		next.On("Get", uint64(2)).Return((*repository.Wallet)(nil), errors.New("database down"))
+		repo := cache.NewWalletRepository(next)
+		// act
+		repo.Get(context.Background(), 1)
+		repo.Get(context.Background(), 1)
+		_, err := repo.Get(context.Background(), 2)
+		repo.Get(context.Background(), 2)
+		// assert
+		assert.EqualError(t, err, "database down")
+		next.AssertNumberOfCalls(t, "Get", 4)
+		assert.Equal(t, 0, repo.Stats().Size)
+	})
+
+	t.Run("Canceled Context", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return(&wallet, nil)
+		repo := cache.NewWalletRepository(next)
+		repo.Get(context.Background(), 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		// act
+		_, err := repo.Get(ctx, 1)
+		// assert
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// gatedRepository reads the wallet, then holds the result until release is
+// closed.
+type gatedRepository struct {
+	repository.WalletRepository
+	release chan struct{}
+	mu      sync.Mutex
+	gets    int
+}
+
+func (r *gatedRepository) Get(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	r.mu.Lock()
+	r.gets++
+	r.mu.Unlock()
+	wallet, err := r.WalletRepository.Get(ctx, id)
+	<-r.release
+	return wallet, err
+}
+
+func (r *gatedRepository) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.gets
+}
+
+func TestSingleflight(t *testing.T) {
+	t.Run("Concurrent Misses Share One Read", func(t *testing.T) {
+		// arrange
+		memory := repository.NewMemoryWalletRepository()
+		memory.Create(context.Background(), &repository.Wallet{Name: "John Doe", Balance: 100})
+		next := &gatedRepository{WalletRepository: memory, release: make(chan struct{})}
+		repo := cache.NewWalletRepository(next)
+		// act
+		var wg sync.WaitGroup
+		balances := make([]float32, 10)
+		for i := range balances {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				wallet, _ := repo.Get(context.Background(), 1)
+				balances[i] = wallet.Balance
+			}(i)
+		}
+		for repo.Stats().Misses < 10 {
+			time.Sleep(time.Millisecond)
+		}
+		close(next.release)
+		wg.Wait()
+		// assert
+		assert.Equal(t, 1, next.count())
+		for _, balance := range balances {
+			assert.Equal(t, float32(100), balance)
+		}
+	})
+
+	t.Run("Waiter Gives Up On Its Own Context", func(t *testing.T) {
+		// arrange
+		memory := repository.NewMemoryWalletRepository()
+		memory.Create(context.Background(), &repository.Wallet{Name: "John Doe", Balance: 100})
+		next := &gatedRepository{WalletRepository: memory, release: make(chan struct{})}
+		repo := cache.NewWalletRepository(next)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		// act
+		_, err := repo.Get(ctx, 1)
+		close(next.release)
+		wallet, otherErr := repo.Get(context.Background(), 1)
+		// assert
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NoError(t, otherErr)
+		assert.Equal(t, float32(100), wallet.Balance)
+	})
+}
+
+func TestInvalidation(t *testing.T) {
+	t.Run("Update", func(t *testing.T) {
+		// arrange
+		memory := repository.NewMemoryWalletRepository()
+		repo := cache.NewWalletRepository(memory)
+		wallet := repository.Wallet{Name: "John Doe", Balance: 100}
+		repo.Create(context.Background(), &wallet)
+		repo.Get(context.Background(), wallet.ID)
+		// act
+		wallet.Balance = 150
+		repo.Update(context.Background(), wallet.ID, &wallet)
+		result, _ := repo.Get(context.Background(), wallet.ID)
+		// assert
+		assert.Equal(t, float32(150), result.Balance)
+	})
+
+	t.Run("Failed Update", func(t *testing.T) {
+		// arrange
+		wallet := repository.Wallet{ID: 1, Balance: 100}
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return(&wallet, nil)
+		next.On("Update", uint64(1), mock.Anything).Return(repository.ErrVersionConflict)
+		repo := cache.NewWalletRepository(next)
+		repo.Get(context.Background(), 1)
+		// act
+		err := repo.Update(context.Background(), 1, &wallet)
+		repo.Get(context.Background(), 1)
+		// assert
+		assert.ErrorIs(t, err, repository.ErrVersionConflict)
+		next.AssertNumberOfCalls(t, "Get", 2)
+	})
+
+	t.Run("Update Many", func(t *testing.T) {
+		// arrange
+		memory := repository.NewMemoryWalletRepository()
+		repo := cache.NewWalletRepository(memory)
+		john := repository.Wallet{Name: "John Doe", Balance: 100}
+		jane := repository.Wallet{Name: "Jane Doe", Balance: 0}
+		repo.Create(context.Background(), &john)
+		repo.Create(context.Background(), &jane)
+		repo.Get(context.Background(), john.ID)
+		repo.Get(context.Background(), jane.ID)
+		// act
+		john.Balance, jane.Balance = 70, 30
+		repo.UpdateMany(context.Background(), []*repository.Wallet{&john, &jane})
+		from, _ := repo.Get(context.Background(), john.ID)
+		to, _ := repo.Get(context.Background(), jane.ID)
+		// assert
+		assert.Equal(t, float32(70), from.Balance)
+		assert.Equal(t, float32(30), to.Balance)
+	})
+
+	t.Run("Read In Flight During Write", func(t *testing.T) {
+		// arrange
+		memory := repository.NewMemoryWalletRepository()
+		wallet := repository.Wallet{Name: "John Doe", Balance: 100}
+		memory.Create(context.Background(), &wallet)
+		next := &gatedRepository{WalletRepository: memory, release: make(chan struct{})}
+		repo := cache.NewWalletRepository(next)
+		inFlight := make(chan *repository.Wallet)
+		go func() {
+			result, _ := repo.Get(context.Background(), wallet.ID)
+			inFlight <- result
+		}()
+		for next.count() < 1 {
+			time.Sleep(time.Millisecond)
+		}
+		// act
+		wallet.Balance = 150
+		repo.Update(context.Background(), wallet.ID, &wallet)
+		close(next.release)
+		stale := <-inFlight
+		result, _ := repo.Get(context.Background(), wallet.ID)
+		// assert
+		assert.Equal(t, float32(100), stale.Balance)
+		assert.Equal(t, float32(150), result.Balance)
+	})
+}
+
+// slowRepository adds a fixed latency to every Get, standing in for
+// storage across a network. The memory repository is there for contrast:
+// its Get is already cheaper than a cache lookup.
+type slowRepository struct {
+	repository.WalletRepository
+	latency time.Duration
+}
+
+func (r slowRepository) Get(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	time.Sleep(r.latency)
+	return r.WalletRepository.Get(ctx, id)
+}
+
+func benchmarkRepositories(wallets int) map[string]repository.WalletRepository {
+	memory := repository.NewMemoryWalletRepository()
+	for i := 0; i < wallets; i++ {
+		memory.Create(context.Background(), &repository.Wallet{Name: fmt.Sprintf("wallet %d", i), Balance: 100})
+	}
+	slow := slowRepository{WalletRepository: memory, latency: 100 * time.Microsecond}
+
+	return map[string]repository.WalletRepository{
+		"Memory":        memory,
+		"Memory Cached": cache.NewWalletRepository(memory, cache.WithTTL(time.Minute)),
+		"Slow":          slow,
+		"Slow Cached":   cache.NewWalletRepository(slow, cache.WithTTL(time.Minute)),
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	for name, repo := range benchmarkRepositories(100) {
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			for i := 0; i < b.N; i++ {
+				repo.Get(ctx, uint64(i%100)+1)
+			}
+		})
+	}
+}
+
+func BenchmarkGetParallel(b *testing.B) {
+	for name, repo := range benchmarkRepositories(100) {
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					repo.Get(ctx, uint64(i%100)+1)
+					i++
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotest/walletctl"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := walletctl.Run(ctx, os.Args[1:], os.Environ(), os.Stdout)
+	if errors.Is(err, walletctl.ErrUsage) {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, walletctl.Usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"gotest/actor"
+	"gotest/cache"
+	"gotest/eventstore"
+	"gotest/repository"
+	"gotest/resilience"
+	"gotest/service"
+	"time"
+)
+
+const (
+	StorageMemory = "memory"
+	StorageEvents = "events"
+)
+
+// Config is everything serve can be configured with. Keys in files and
+// environment variables follow the yaml tags: storage.dsn is set by
+// WALLET_STORAGE_DSN. Fields with a flag tag can also be set on the command
+// line. Sections tagged reload:"safe" are applied again on SIGHUP.
+type Config struct {
+	Server     Server     `yaml:"server"`
+	Storage    Storage    `yaml:"storage"`
+	Cache      Cache      `yaml:"cache"`
+	Resilience Resilience `yaml:"resilience"`
+	Service    Service    `yaml:"service"`
+	Actors     Actors     `yaml:"actors"`
+	Log        Log        `yaml:"log" reload:"safe"`
+	Audit      Audit      `yaml:"audit"`
+	Auth       Auth       `yaml:"auth"`
+	Limits     Limits     `yaml:"limits" reload:"safe"`
+	Fees       Fees       `yaml:"fees" reload:"safe"`
+	Features   Features   `yaml:"features"`
+}
+
+type Server struct {
+	Addr           string        `yaml:"addr" flag:"addr" usage:"listen address"`
+	GRPCAddr       string        `yaml:"grpc_addr" flag:"grpc-addr" usage:"gRPC listen address"`
+	RequestTimeout time.Duration `yaml:"request_timeout" flag:"request-timeout" usage:"deadline for each HTTP request"`
+	DrainDelay     time.Duration `yaml:"drain_delay" flag:"drain-delay" usage:"how long /readyz reports draining before shutdown"`
+}
+
+type Storage struct {
+	Driver string `yaml:"driver" flag:"store" usage:"wallet storage: memory or events"`
+	DSN    string `yaml:"dsn" flag:"db" usage:"wallet data file, in-memory when empty"`
+}
+
+// Cache keeps wallets read by the service for TTL, at most Size of them. A
+// zero TTL turns the cache off.
+type Cache struct {
+	TTL  time.Duration `yaml:"ttl"`
+	Size int           `yaml:"size"`
+}
+
+// Resilience retries transient storage errors, Attempts calls in all with
+// backoff from BaseDelay up to MaxDelay, and stops calling storage for
+// Cooldown after FailureThreshold failures in a row.
+type Resilience struct {
+	Attempts         int           `yaml:"attempts"`
+	BaseDelay        time.Duration `yaml:"base_delay"`
+	MaxDelay         time.Duration `yaml:"max_delay"`
+	FailureThreshold int           `yaml:"failure_threshold"`
+	Cooldown         time.Duration `yaml:"cooldown"`
+}
+
+type Service struct {
+	BatchConcurrency int           `yaml:"batch_concurrency"`
+	ReadTimeout      time.Duration `yaml:"read_timeout"`
+	WriteTimeout     time.Duration `yaml:"write_timeout"`
+	BatchTimeout     time.Duration `yaml:"batch_timeout"`
+}
+
+// Actors runs the commands for each wallet one at a time on the goroutine
+// of its shard, instead of leaving storage to order them. Each shard queues
+// at most QueueSize commands.
+type Actors struct {
+	Enabled   bool `yaml:"enabled"`
+	Shards    int  `yaml:"shards"`
+	QueueSize int  `yaml:"queue_size"`
+}
+
+type Log struct {
+	Level string `yaml:"level" flag:"log-level" usage:"log level: debug, info, warn or error"`
+}
+
+type Audit struct {
+	Path string `yaml:"path" flag:"audit-log" usage:"audit log file"`
+}
+
+type Auth struct {
+	StreamTokens string `yaml:"stream_tokens" flag:"stream-tokens" usage:"balance stream grants, e.g. \"alice=1,2;ops=*\""`
+	Tokens       string `yaml:"tokens" flag:"tokens" usage:"API tokens and their scopes, e.g. \"s3cret=alice:admin\""`
+}
+
+// Limits caps single operations; zero leaves an operation uncapped.
+type Limits struct {
+	MaxDeposit    float32 `yaml:"max_deposit"`
+	MaxWithdrawal float32 `yaml:"max_withdrawal"`
+	MaxTransfer   float32 `yaml:"max_transfer"`
+}
+
+type Fees struct {
+	Transfer float32 `yaml:"transfer"`
+}
+
+type Features struct {
+	Webhooks    bool `yaml:"webhooks"`
+	TraceStdout bool `yaml:"trace_stdout" flag:"trace-stdout" usage:"print finished trace spans to stdout"`
+}
+
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr:           ":8000",
+			GRPCAddr:       ":9000",
+			RequestTimeout: 30 * time.Second,
+			DrainDelay:     5 * time.Second,
+		},
+		Storage: Storage{Driver: StorageMemory},
+		Cache:   Cache{TTL: cache.DefaultTTL, Size: cache.DefaultSize},
+		Resilience: Resilience{
+			Attempts:         resilience.DefaultAttempts,
+			BaseDelay:        resilience.DefaultBaseDelay,
+			MaxDelay:         resilience.DefaultMaxDelay,
+			FailureThreshold: resilience.DefaultFailureThreshold,
+			Cooldown:         resilience.DefaultCooldown,
+		},
+		Service: Service{
+			BatchConcurrency: service.DefaultBatchConcurrency,
+			ReadTimeout:      service.DefaultTimeouts.Read,
+			WriteTimeout:     service.DefaultTimeouts.Write,
+			BatchTimeout:     service.DefaultTimeouts.Batch,
+		},
+		Actors:   Actors{Shards: actor.DefaultShards, QueueSize: actor.DefaultQueueSize},
+		Log:      Log{Level: "info"},
+		Audit:    Audit{Path: "audit.log"},
+		Features: Features{Webhooks: true},
+	}
+}
+
+// Open returns the wallet repository the storage settings describe; an
+// empty DSN keeps it in memory.
+func (s Storage) Open() (repository.WalletRepository, error) {
+	switch {
+	case s.Driver == StorageEvents && s.DSN == "":
+		return eventstore.NewRepository(), nil
+	case s.Driver == StorageEvents:
+		return eventstore.NewFileRepository(s.DSN)
+	case s.Driver != StorageMemory:
+		return nil, fmt.Errorf("unknown store %q", s.Driver)
+	case s.DSN == "":
+		return repository.NewMemoryWalletRepository(), nil
+	default:
+		return repository.NewFileWalletRepository(s.DSN)
+	}
+}
+
+func (c Config) Timeouts() service.Timeouts {
+	return service.Timeouts{Read: c.Service.ReadTimeout, Write: c.Service.WriteTimeout, Batch: c.Service.BatchTimeout}
+}
+
+func (c Config) Policy() service.Policy {
+	return service.Policy{
+		Limits: service.Limits{
+			MaxDeposit:    c.Limits.MaxDeposit,
+			MaxWithdrawal: c.Limits.MaxWithdrawal,
+			MaxTransfer:   c.Limits.MaxTransfer,
+		},
+		Fees: service.Fees{Transfer: c.Fees.Transfer},
+	}
+}
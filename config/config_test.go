@@ -0,0 +1,162 @@
+// go:build unit
+package config_test
+
+import (
+	"gotest/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte(content), 0o600)
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		// arrange
+		// act
+		cfg, err := config.Load("serve", nil, nil)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, config.Default(), cfg)
+	})
+
+	t.Run("Example File", func(t *testing.T) {
+		// arrange
+		// act
+		cfg, err := config.Load("serve", []string{"-config", "example.yaml"}, nil)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, config.StorageEvents, cfg.Storage.Driver)
+		assert.Equal(t, float32(5000), cfg.Limits.MaxWithdrawal)
+		assert.Equal(t, float32(0.5), cfg.Policy().Fees.Transfer)
+		assert.Equal(t, config.Default().Resilience, cfg.Resilience)
+		assert.Equal(t, config.Default().Actors, cfg.Actors)
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		// arrange
+		path := writeFile(t, "server:\n  addr: \":7000\"\n  drain_delay: 1s\nlimits:\n  max_deposit: 100\nlog:\n  level: debug\n")
+		environ := []string{
+			"WALLET_CONFIG=" + path,
+			"WALLET_SERVER_ADDR=:7100",
+			"WALLET_LIMITS_MAX_DEPOSIT=200",
+			"WALLET_FEATURES_TRACE_STDOUT=true",
+		}
+		args := []string{"-addr", ":7200"}
+		// act
+		cfg, err := config.Load("serve", args, environ)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, ":7200", cfg.Server.Addr)
+		assert.Equal(t, time.Second, cfg.Server.DrainDelay)
+		assert.Equal(t, float32(200), cfg.Limits.MaxDeposit)
+		assert.Equal(t, "debug", cfg.Log.Level)
+		assert.True(t, cfg.Features.TraceStdout)
+		assert.Equal(t, ":9000", cfg.Server.GRPCAddr)
+	})
+
+	t.Run("Flag Overrides Config Env", func(t *testing.T) {
+		// arrange
+		fromEnv := writeFile(t, "log:\n  level: warn\n")
+		fromFlag := writeFile(t, "log:\n  level: error\n")
+		// act
+		cfg, err := config.Load("serve", []string{"-config", fromFlag}, []string{"WALLET_CONFIG=" + fromEnv})
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "error", cfg.Log.Level)
+	})
+
+	t.Run("Bool Flag", func(t *testing.T) {
+		// arrange
+		// act
+		cfg, err := config.Load("serve", []string{"-trace-stdout"}, []string{"WALLET_FEATURES_TRACE_STDOUT=false"})
+		// assert
+		assert.NoError(t, err)
+		assert.True(t, cfg.Features.TraceStdout)
+	})
+
+	t.Run("Unknown Key In File", func(t *testing.T) {
+		// arrange
+		path := writeFile(t, "server:\n  adress: \":7000\"\n")
+		// act
+		_, err := config.Load("serve", []string{"-config", path}, nil)
+		// assert
+		assert.ErrorContains(t, err, "field adress not found")
+	})
+
+	t.Run("Invalid Values", func(t *testing.T) {
+		// arrange
+		environ := []string{
+			"WALLET_SERVICE_READ_TIMEOUT=soon",
+			"WALLET_LIMITS_MAX_TRANSFER=lots",
+		}
+		// act
+		_, err := config.Load("serve", nil, environ)
+		// assert
+		assert.ErrorContains(t, err, `WALLET_SERVICE_READ_TIMEOUT: invalid duration "soon"`)
+		assert.ErrorContains(t, err, `WALLET_LIMITS_MAX_TRANSFER: invalid number "lots"`)
+	})
+
+	t.Run("Unexpected Arguments", func(t *testing.T) {
+		// arrange
+		// act
+		_, err := config.Load("serve", []string{"extra"}, nil)
+		// assert
+		assert.ErrorContains(t, err, "unexpected arguments: extra")
+	})
+}
+
+func TestValidate(t *testing.T) {
+	// arrange
+	cfg := config.Default()
+	cfg.Server.Addr = "8000"
+	cfg.Storage.Driver = "postgres"
+	cfg.Log.Level = "loud"
+	cfg.Auth.StreamTokens = "alice"
+	cfg.Auth.Tokens = "s3cret"
+	cfg.Fees.Transfer = -1
+	cfg.Cache.Size = 0
+	cfg.Resilience.MaxDelay = time.Millisecond
+	cfg.Actors.QueueSize = 0
+	// act
+	err := cfg.Validate()
+	// assert
+	assert.ErrorContains(t, err, `server.addr: must be host:port, got "8000"`)
+	assert.ErrorContains(t, err, `storage.driver: must be "memory" or "events", got "postgres"`)
+	assert.ErrorContains(t, err, `log.level: must be debug, info, warn or error, got "loud"`)
+	assert.ErrorContains(t, err, "auth.stream_tokens: ")
+	assert.ErrorContains(t, err, "auth.tokens: ")
+	assert.ErrorContains(t, err, "fees.transfer: must not be negative")
+	assert.ErrorContains(t, err, "cache.size: must be positive")
+	assert.ErrorContains(t, err, "resilience.max_delay: must not be less than resilience.base_delay")
+	assert.ErrorContains(t, err, "actors.queue_size: must be positive")
+	assert.NoError(t, config.Default().Validate())
+}
+
+func TestLiveReload(t *testing.T) {
+	// arrange
+	live := config.NewLive(config.Default())
+	next := config.Default()
+	next.Limits.MaxWithdrawal = 50
+	next.Fees.Transfer = 1
+	next.Log.Level = "debug"
+	next.Server.Addr = ":7000"
+	next.Storage.DSN = "wallets.json"
+	// act
+	ignored := live.Reload(next)
+	// assert
+	assert.Equal(t, []string{"server.addr", "storage.dsn"}, ignored)
+	cfg := live.Get()
+	assert.Equal(t, float32(50), cfg.Policy().Limits.MaxWithdrawal)
+	assert.Equal(t, float32(1), cfg.Policy().Fees.Transfer)
+	assert.Equal(t, "debug", cfg.Log.Level)
+	assert.Equal(t, ":8000", cfg.Server.Addr)
+	assert.Equal(t, "", cfg.Storage.DSN)
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Live holds the running configuration. Reload only changes sections tagged
+// reload:"safe"; everything else keeps its startup value until a restart.
+type Live struct {
+	mu      sync.Mutex
+	current atomic.Pointer[Config]
+}
+
+func NewLive(c Config) *Live {
+	l := &Live{}
+	l.current.Store(&c)
+	return l
+}
+
+func (l *Live) Get() Config {
+	return *l.current.Load()
+}
+
+// Reload applies next's safe settings and returns the keys of the other
+// settings that differ, which need a restart to take effect. next must
+// already be valid.
+func (l *Live) Reload(next Config) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	applied := l.Get()
+	ignored := []string{}
+	current, incoming := settings(&applied), settings(&next)
+	for i, s := range current {
+		if s.safe {
+			s.value.Set(incoming[i].value)
+			continue
+		}
+		if !reflect.DeepEqual(s.value.Interface(), incoming[i].value.Interface()) {
+			ignored = append(ignored, s.key)
+		}
+	}
+
+	l.current.Store(&applied)
+	return ignored
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	EnvPrefix = "WALLET_"
+	EnvFile   = EnvPrefix + "CONFIG"
+)
+
+// setting is one leaf of Config: its dotted yaml key and where it lives.
+type setting struct {
+	key   string
+	field reflect.StructField
+	value reflect.Value
+	safe  bool
+}
+
+func (s setting) env() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))
+}
+
+func settings(c *Config) []setting {
+	return walk(reflect.ValueOf(c).Elem(), "", false)
+}
+
+func walk(v reflect.Value, prefix string, safe bool) []setting {
+	result := []setting{}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := prefix + field.Tag.Get("yaml")
+		if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+			result = append(result, walk(v.Field(i), key+".", safe || field.Tag.Get("reload") == "safe")...)
+			continue
+		}
+		result = append(result, setting{key: key, field: field, value: v.Field(i), safe: safe})
+	}
+	return result
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func set(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// Load builds the configuration from, in increasing precedence: defaults,
+// the YAML file named by -config or WALLET_CONFIG, WALLET_* environment
+// variables, and the flags set in args. The result is validated; every
+// problem found is reported, not just the first.
+func Load(name string, args []string, environ []string) (Config, error) {
+	c := Default()
+	all := settings(&c)
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	file := flags.String("config", lookup(environ, EnvFile), "YAML configuration file, also "+EnvFile)
+	fromFlags := map[string]string{}
+	for _, s := range all {
+		name := s.field.Tag.Get("flag")
+		if name == "" {
+			continue
+		}
+		key := s.key
+		usage := s.field.Tag.Get("usage") + ", also " + s.env()
+		record := func(raw string) error {
+			fromFlags[key] = raw
+			return nil
+		}
+		if s.value.Kind() == reflect.Bool {
+			flags.BoolFunc(name, usage, record)
+		} else {
+			flags.Func(name, usage+fmt.Sprintf(" (default %q)", fmt.Sprint(s.value.Interface())), record)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return c, err
+	}
+	if flags.NArg() > 0 {
+		return c, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	if *file != "" {
+		if err := loadFile(&c, *file); err != nil {
+			return c, err
+		}
+	}
+
+	errs := []error{}
+	for _, s := range all {
+		if raw, ok := lookupOK(environ, s.env()); ok {
+			if err := set(s.value, raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env(), err))
+			}
+		}
+	}
+	for _, s := range all {
+		if raw, ok := fromFlags[s.key]; ok {
+			if err := set(s.value, raw); err != nil {
+				errs = append(errs, fmt.Errorf("-%s: %w", s.field.Tag.Get("flag"), err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return c, errors.Join(errs...)
+	}
+
+	return c, c.Validate()
+}
+
+func loadFile(c *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func lookup(environ []string, key string) string {
+	value, _ := lookupOK(environ, key)
+	return value
+}
+
+func lookupOK(environ []string, key string) (string, bool) {
+	for i := len(environ) - 1; i >= 0; i-- {
+		if k, v, ok := strings.Cut(environ[i], "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
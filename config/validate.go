@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"gotest/auth"
+	"log/slog"
+	"net"
+)
+
+// Validate reports every invalid setting, each prefixed with its key.
+func (c Config) Validate() error {
+	errs := []error{}
+	check := func(ok bool, key string, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	_, _, err := net.SplitHostPort(c.Server.Addr)
+	check(err == nil, "server.addr", "must be host:port, got %q", c.Server.Addr)
+	_, _, err = net.SplitHostPort(c.Server.GRPCAddr)
+	check(err == nil, "server.grpc_addr", "must be host:port, got %q", c.Server.GRPCAddr)
+	check(c.Server.Addr != c.Server.GRPCAddr, "server.grpc_addr", "must differ from server.addr")
+	check(c.Server.RequestTimeout > 0, "server.request_timeout", "must be positive")
+	check(c.Server.DrainDelay >= 0, "server.drain_delay", "must not be negative")
+
+	check(c.Storage.Driver == StorageMemory || c.Storage.Driver == StorageEvents, "storage.driver", "must be %q or %q, got %q", StorageMemory, StorageEvents, c.Storage.Driver)
+
+	check(c.Cache.TTL >= 0, "cache.ttl", "must not be negative")
+	check(c.Cache.Size > 0, "cache.size", "must be positive")
+
+	check(c.Resilience.Attempts > 0, "resilience.attempts", "must be positive")
+	check(c.Resilience.BaseDelay > 0, "resilience.base_delay", "must be positive")
+	check(c.Resilience.MaxDelay >= c.Resilience.BaseDelay, "resilience.max_delay", "must not be less than resilience.base_delay")
+	check(c.Resilience.FailureThreshold > 0, "resilience.failure_threshold", "must be positive")
+	check(c.Resilience.Cooldown > 0, "resilience.cooldown", "must be positive")
+
+	check(c.Service.BatchConcurrency > 0, "service.batch_concurrency", "must be positive")
+	check(c.Service.ReadTimeout >= 0, "service.read_timeout", "must not be negative")
+	check(c.Service.WriteTimeout >= 0, "service.write_timeout", "must not be negative")
+	check(c.Service.BatchTimeout >= 0, "service.batch_timeout", "must not be negative")
+
+	check(c.Actors.Shards > 0, "actors.shards", "must be positive")
+	check(c.Actors.QueueSize > 0, "actors.queue_size", "must be positive")
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "log.level", "must be debug, info, warn or error, got %q", c.Log.Level)
+
+	check(c.Audit.Path != "", "audit.path", "must not be empty")
+
+	_, err = auth.ParseGrants(c.Auth.StreamTokens)
+	check(err == nil, "auth.stream_tokens", "%v", err)
+	_, err = auth.ParseTokens(c.Auth.Tokens)
+	check(err == nil, "auth.tokens", "%v", err)
+
+	check(c.Limits.MaxDeposit >= 0, "limits.max_deposit", "must not be negative")
+	check(c.Limits.MaxWithdrawal >= 0, "limits.max_withdrawal", "must not be negative")
+	check(c.Limits.MaxTransfer >= 0, "limits.max_transfer", "must not be negative")
+	check(c.Fees.Transfer >= 0, "fees.transfer", "must not be negative")
+
+	return errors.Join(errs...)
+}
+
+// LogLevel is the parsed log.level; Validate has already rejected bad ones.
+func (c Config) LogLevel() slog.Level {
+	var level slog.Level
+	level.UnmarshalText([]byte(c.Log.Level))
+	return level
+}
@@ -0,0 +1,143 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"gotest/repository"
+	"time"
+)
+
+const (
+	TypeWalletOpened      = "WalletOpened"
+	TypeFundsDeposited    = "FundsDeposited"
+	TypeFundsWithdrawn    = "FundsWithdrawn"
+	TypeTransferCompleted = "TransferCompleted"
+	TypeBalanceAdjusted   = "BalanceAdjusted"
+	TypeStatusChanged     = "StatusChanged"
+)
+
+// Types lists every event type the relay publishes.
+var Types = []string{
+	TypeWalletOpened,
+	TypeFundsDeposited,
+	TypeFundsWithdrawn,
+	TypeTransferCompleted,
+	TypeBalanceAdjusted,
+	TypeStatusChanged,
+}
+
+// DomainEvent is a typed wallet change. The wallet ID travels in the outbox
+// envelope rather than in the payload.
+type DomainEvent interface {
+	EventType() string
+	AggregateID() uint64
+}
+
+type WalletOpened struct {
+	WalletID uint64  `json:"-"`
+	Name     string  `json:"name"`
+	Balance  float32 `json:"balance"`
+}
+
+type FundsDeposited struct {
+	WalletID uint64  `json:"-"`
+	Amount   float32 `json:"amount"`
+	Balance  float32 `json:"balance"`
+}
+
+// FundsWithdrawn takes Amount plus Fee out of the wallet; Fee is only set
+// when the withdrawal carried one.
+type FundsWithdrawn struct {
+	WalletID uint64  `json:"-"`
+	Amount   float32 `json:"amount"`
+	Fee      float32 `json:"fee,omitempty"`
+	Balance  float32 `json:"balance"`
+}
+
+type TransferCompleted struct {
+	WalletID   uint64  `json:"-"`
+	ToWalletID uint64  `json:"to_wallet_id"`
+	Amount     float32 `json:"amount"`
+}
+
+// BalanceAdjusted corrects a stored balance that drifted from the ledger.
+// No money moves: Previous is the balance being replaced.
+type BalanceAdjusted struct {
+	WalletID uint64  `json:"-"`
+	Previous float32 `json:"previous"`
+	Balance  float32 `json:"balance"`
+	Reason   string  `json:"reason"`
+}
+
+// StatusChanged freezes or reactivates a wallet. The balance is untouched.
+type StatusChanged struct {
+	WalletID uint64 `json:"-"`
+	Previous string `json:"previous"`
+	Status   string `json:"status"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+func (e WalletOpened) EventType() string      { return TypeWalletOpened }
+func (e FundsDeposited) EventType() string    { return TypeFundsDeposited }
+func (e FundsWithdrawn) EventType() string    { return TypeFundsWithdrawn }
+func (e TransferCompleted) EventType() string { return TypeTransferCompleted }
+func (e BalanceAdjusted) EventType() string   { return TypeBalanceAdjusted }
+func (e StatusChanged) EventType() string     { return TypeStatusChanged }
+
+func (e WalletOpened) AggregateID() uint64      { return e.WalletID }
+func (e FundsDeposited) AggregateID() uint64    { return e.WalletID }
+func (e FundsWithdrawn) AggregateID() uint64    { return e.WalletID }
+func (e TransferCompleted) AggregateID() uint64 { return e.WalletID }
+func (e BalanceAdjusted) AggregateID() uint64   { return e.WalletID }
+func (e StatusChanged) AggregateID() uint64     { return e.WalletID }
+
+func Encode(e DomainEvent) (repository.Event, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return repository.Event{}, err
+	}
+
+	return repository.Event{
+		Type:       e.EventType(),
+		WalletID:   e.AggregateID(),
+		Payload:    payload,
+		OccurredAt: time.Now().UTC(),
+	}, nil
+}
+
+func Decode(record repository.Event) (DomainEvent, error) {
+	switch record.Type {
+	case TypeWalletOpened:
+		e := WalletOpened{}
+		err := json.Unmarshal(record.Payload, &e)
+		e.WalletID = record.WalletID
+		return e, err
+	case TypeFundsDeposited:
+		e := FundsDeposited{}
+		err := json.Unmarshal(record.Payload, &e)
+		e.WalletID = record.WalletID
+		return e, err
+	case TypeFundsWithdrawn:
+		e := FundsWithdrawn{}
+		err := json.Unmarshal(record.Payload, &e)
+		e.WalletID = record.WalletID
+		return e, err
+	case TypeTransferCompleted:
+		e := TransferCompleted{}
+		err := json.Unmarshal(record.Payload, &e)
+		e.WalletID = record.WalletID
+		return e, err
+	case TypeBalanceAdjusted:
+		e := BalanceAdjusted{}
+		err := json.Unmarshal(record.Payload, &e)
+		e.WalletID = record.WalletID
+		return e, err
+	case TypeStatusChanged:
+		e := StatusChanged{}
+		err := json.Unmarshal(record.Payload, &e)
+		e.WalletID = record.WalletID
+		return e, err
+	default:
+		return nil, fmt.Errorf("unknown event type %q", record.Type)
+	}
+}
@@ -0,0 +1,91 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gotest/repository"
+	"io"
+	"net/http"
+	"time"
+)
+
+type Publisher interface {
+	Publish(e repository.Event) error
+}
+
+type channelPublisher struct {
+	ch chan repository.Event
+}
+
+// NewChannelPublisher delivers events in-process. Publish blocks while the
+// buffer is full, which holds the relay back instead of dropping events.
+func NewChannelPublisher(buffer int) *channelPublisher {
+	return &channelPublisher{ch: make(chan repository.Event, buffer)}
+}
+
+func (p *channelPublisher) Publish(e repository.Event) error {
+	p.ch <- e
+	return nil
+}
+
+func (p *channelPublisher) Events() <-chan repository.Event {
+	return p.ch
+}
+
+type logPublisher struct {
+	w io.Writer
+}
+
+func NewLogPublisher(w io.Writer) logPublisher {
+	return logPublisher{w: w}
+}
+
+func (p logPublisher) Publish(e repository.Event) error {
+	_, err := fmt.Fprintf(p.w, "event id=%d type=%s wallet=%d payload=%s\n", e.ID, e.Type, e.WalletID, e.Payload)
+	return err
+}
+
+type webhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookPublisher(url string) webhookPublisher {
+	return webhookPublisher{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (p webhookPublisher) Publish(e repository.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Post(p.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type multiPublisher []Publisher
+
+// NewMultiPublisher fans an event out to every publisher. It fails if any of
+// them fails, so the relay retries and some publishers may see duplicates.
+func NewMultiPublisher(publishers ...Publisher) multiPublisher {
+	return multiPublisher(publishers)
+}
+
+func (p multiPublisher) Publish(e repository.Event) error {
+	for _, publisher := range p {
+		if err := publisher.Publish(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
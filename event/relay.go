@@ -0,0 +1,100 @@
+package event
+
+import (
+	"context"
+	"gotest/repository"
+	"log/slog"
+	"time"
+)
+
+// Relay moves outbox events to a publisher. An event is marked published only
+// after Publish succeeds, so delivery is at-least-once and consumers should
+// deduplicate on the event ID.
+type Relay struct {
+	repo        repository.WalletRepository
+	publisher   Publisher
+	BatchSize   int
+	Interval    time.Duration
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Logger      *slog.Logger
+}
+
+func NewRelay(repo repository.WalletRepository, publisher Publisher) *Relay {
+	return &Relay{
+		repo:        repo,
+		publisher:   publisher,
+		BatchSize:   100,
+		Interval:    time.Second,
+		MaxAttempts: 5,
+		Backoff:     100 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+		Logger:      slog.Default(),
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		// Errors are retried on the next tick; the events stay pending.
+		if _, err := r.Flush(ctx); err != nil && ctx.Err() == nil {
+			r.Logger.WarnContext(ctx, "outbox relay flush failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush publishes pending events in order and stops at the first event that
+// still fails after all attempts, so later events are never published ahead
+// of it.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	events, err := r.repo.PendingEvents(ctx, r.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, e := range events {
+		if err := r.publish(ctx, e); err != nil {
+			return published, err
+		}
+		if err := r.repo.MarkEventPublished(ctx, e.ID); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+func (r *Relay) publish(ctx context.Context, e repository.Event) error {
+	backoff := r.Backoff
+	var err error
+	for attempt := 1; attempt <= r.MaxAttempts; attempt++ {
+		if err = r.publisher.Publish(e); err == nil {
+			return nil
+		}
+		if attempt == r.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > r.MaxBackoff {
+			backoff = r.MaxBackoff
+		}
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+// go:build unit
+package event_test
+
+import (
+	"context"
+	"errors"
+	"gotest/event"
+	"gotest/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type publisherMock struct {
+	mock.Mock
+}
+
+func (m *publisherMock) Publish(e repository.Event) error {
+	c := m.Called(e.ID)
+	return c.Error(0)
+}
+
+func newRelay(repo repository.WalletRepository, publisher event.Publisher) *event.Relay {
+	relay := event.NewRelay(repo, publisher)
+	relay.MaxAttempts = 3
+	relay.Backoff = time.Millisecond
+	return relay
+}
+
+func TestRelayFlush(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		repo.Create(context.Background(), &repository.Wallet{Name: "John Doe"}, repository.Event{Type: event.TypeWalletOpened})
+		repo.Create(context.Background(), &repository.Wallet{Name: "Jane Doe"}, repository.Event{Type: event.TypeWalletOpened})
+		publisher := event.NewChannelPublisher(10)
+		relay := newRelay(repo, publisher)
+		// act
+		published, err := relay.Flush(context.Background())
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 2, published)
+		assert.Equal(t, uint64(1), (<-publisher.Events()).WalletID)
+		assert.Equal(t, uint64(2), (<-publisher.Events()).WalletID)
+		pending, _ := repo.PendingEvents(context.Background(), 10)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("Retry Until Published", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		repo.Create(context.Background(), &repository.Wallet{Name: "John Doe"}, repository.Event{Type: event.TypeWalletOpened})
+		publisher := &publisherMock{}
+		publisher.On("Publish", uint64(1)).Return(errors.New("broker down")).Twice()
+		publisher.On("Publish", uint64(1)).Return(nil).Once()
+		relay := newRelay(repo, publisher)
+		// act
+		published, err := relay.Flush(context.Background())
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, published)
+		publisher.AssertNumberOfCalls(t, "Publish", 3)
+	})
+
+	t.Run("Keep Pending After Attempts Exhausted", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		repo.Create(context.Background(), &repository.Wallet{Name: "John Doe"}, repository.Event{Type: event.TypeWalletOpened})
+		repo.Create(context.Background(), &repository.Wallet{Name: "Jane Doe"}, repository.Event{Type: event.TypeWalletOpened})
+		publisher := &publisherMock{}
+		publisher.On("Publish", uint64(1)).Return(errors.New("broker down"))
+		relay := newRelay(repo, publisher)
+		// act
+		published, err := relay.Flush(context.Background())
+		// assert
+		assert.Error(t, err)
+		assert.Equal(t, 0, published)
+		publisher.AssertNotCalled(t, "Publish", uint64(2))
+		pending, _ := repo.PendingEvents(context.Background(), 10)
+		assert.Len(t, pending, 2)
+	})
+}
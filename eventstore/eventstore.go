@@ -0,0 +1,523 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gotest/event"
+	"gotest/repository"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrUnrecordedChange rejects writes whose wallet state does not follow from
+// the events written with it; the events are the only thing stored.
+var ErrUnrecordedChange = errors.New("wallet change not described by its events")
+
+const DefaultSnapshotEvery = 50
+
+// Snapshot is a wallet's state right after the event EventID, which is
+// event number Wallet.Version of its stream.
+type Snapshot struct {
+	Wallet     repository.Wallet `json:"wallet"`
+	EventID    uint64            `json:"event_id"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// eventStoreRepository implements repository.WalletRepository on top of an
+// append-only event log. Each wallet is a stream of the events carrying its
+// ID; writes are accepted only at the stream's current version. Wallet rows
+// served by Get and List are a projection of the streams, kept up to date on
+// every write and rebuildable from the log.
+type eventStoreRepository struct {
+	mu            sync.RWMutex
+	log           []repository.Event
+	streams       map[uint64][]int
+	snapshots     map[uint64][]Snapshot
+	projection    map[uint64]repository.Wallet
+	lastID        uint64
+	snapshotEvery int
+	path          string
+}
+
+type Option func(r *eventStoreRepository)
+
+func WithSnapshotEvery(n int) Option {
+	return func(r *eventStoreRepository) {
+		if n > 0 {
+			r.snapshotEvery = n
+		}
+	}
+}
+
+func NewRepository(opts ...Option) *eventStoreRepository {
+	r := &eventStoreRepository{
+		streams:       map[uint64][]int{},
+		snapshots:     map[uint64][]Snapshot{},
+		projection:    map[uint64]repository.Wallet{},
+		snapshotEvery: DefaultSnapshotEvery,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+type storeFile struct {
+	Events    []repository.Event  `json:"events"`
+	Snapshots []Snapshot          `json:"snapshots"`
+	Wallets   []repository.Wallet `json:"wallets"`
+	LastID    uint64              `json:"last_id"`
+}
+
+// NewFileRepository loads the log, snapshots and projection from path and
+// rewrites the file after every write, like the file-backed memory
+// repository.
+func NewFileRepository(path string, opts ...Option) (*eventStoreRepository, error) {
+	r := NewRepository(opts...)
+	r.path = path
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stored := storeFile{}
+	if err := json.Unmarshal(content, &stored); err != nil {
+		return nil, err
+	}
+	for _, record := range stored.Events {
+		r.streams[record.WalletID] = append(r.streams[record.WalletID], len(r.log))
+		r.log = append(r.log, record)
+	}
+	for _, snapshot := range stored.Snapshots {
+		r.snapshots[snapshot.Wallet.ID] = append(r.snapshots[snapshot.Wallet.ID], snapshot)
+	}
+	for _, wallet := range stored.Wallets {
+		r.projection[wallet.ID] = wallet
+	}
+	r.lastID = stored.LastID
+	return r, nil
+}
+
+func (r *eventStoreRepository) Get(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	wallet := r.projection[id]
+	return &wallet, nil
+}
+
+// Create starts a new stream. Events without a wallet ID belong to it, and
+// the stream must open with a WalletOpened event.
+func (r *eventStoreRepository) Create(ctx context.Context, wallet *repository.Wallet, events ...repository.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	id := r.lastID + 1
+	events = stamp(events, id)
+	if len(events) == 0 || events[0].Type != event.TypeWalletOpened {
+		return ErrUnrecordedChange
+	}
+
+	created := *wallet
+	created.ID = id
+	created.Version = 0
+	states, err := r.fold(map[uint64]repository.Wallet{id: {}}, []*repository.Wallet{&created}, events)
+	if err != nil {
+		return err
+	}
+
+	undo := r.checkpoint(id)
+	r.lastID = id
+	r.commit(states, events)
+	if err := r.save(); err != nil {
+		undo()
+		return err
+	}
+	*wallet = states[id]
+	return nil
+}
+
+func (r *eventStoreRepository) Update(ctx context.Context, id uint64, wallet *repository.Wallet, events ...repository.Event) error {
+	updated := *wallet
+	updated.ID = id
+	if err := r.UpdateMany(ctx, []*repository.Wallet{&updated}, stamp(events, id)...); err != nil {
+		return err
+	}
+	*wallet = updated
+	return nil
+}
+
+// UpdateMany appends to every wallet's stream or to none of them. Each
+// wallet's Version must match its stream, and every event must belong to one
+// of the wallets.
+func (r *eventStoreRepository) UpdateMany(ctx context.Context, wallets []*repository.Wallet, events ...repository.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	events = stamp(events, 0)
+	current := map[uint64]repository.Wallet{}
+	for _, wallet := range wallets {
+		if len(r.streams[wallet.ID]) == 0 {
+			return repository.ErrWalletNotFound
+		}
+		state, err := r.rehydrate(wallet.ID)
+		if err != nil {
+			return err
+		}
+		if state.Version != wallet.Version {
+			return repository.ErrVersionConflict
+		}
+		current[wallet.ID] = state
+	}
+
+	states, err := r.fold(current, wallets, events)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uint64, 0, len(current))
+	for id := range current {
+		ids = append(ids, id)
+	}
+	undo := r.checkpoint(ids...)
+	r.commit(states, events)
+	if err := r.save(); err != nil {
+		undo()
+		return err
+	}
+	for _, wallet := range wallets {
+		*wallet = states[wallet.ID]
+	}
+	return nil
+}
+
+func (r *eventStoreRepository) List(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) ([]repository.Wallet, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	r.mu.RLock()
+	wallets := make([]repository.Wallet, 0, len(r.projection))
+	for _, wallet := range r.projection {
+		wallets = append(wallets, wallet)
+	}
+	r.mu.RUnlock()
+
+	return repository.ListWallets(wallets, filter, sort, cursor, limit)
+}
+
+func (r *eventStoreRepository) Events(ctx context.Context, walletID uint64) ([]repository.Event, error) {
+	return r.EventsSince(ctx, walletID, 0)
+}
+
+func (r *eventStoreRepository) EventsSince(ctx context.Context, walletID uint64, afterID uint64) ([]repository.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	events := []repository.Event{}
+	for _, i := range r.streams[walletID] {
+		if r.log[i].ID > afterID {
+			events = append(events, r.log[i])
+		}
+	}
+	return events, nil
+}
+
+// SaveBalanceSnapshot does nothing: the store snapshots every stream on its
+// own, and BalanceSnapshotAt answers from those snapshots.
+func (r *eventStoreRepository) SaveBalanceSnapshot(ctx context.Context, snapshot repository.BalanceSnapshot) error {
+	return nil
+}
+
+func (r *eventStoreRepository) BalanceSnapshotAt(ctx context.Context, walletID uint64, at time.Time) (repository.BalanceSnapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return repository.BalanceSnapshot{}, err
+	}
+
+	snapshots := r.snapshots[walletID]
+	i := sort.Search(len(snapshots), func(i int) bool { return snapshots[i].OccurredAt.After(at) })
+	if i == 0 {
+		return repository.BalanceSnapshot{WalletID: walletID}, nil
+	}
+	return repository.BalanceSnapshot{
+		WalletID:   walletID,
+		EventID:    snapshots[i-1].EventID,
+		Balance:    snapshots[i-1].Wallet.Balance,
+		OccurredAt: snapshots[i-1].OccurredAt,
+	}, nil
+}
+
+func (r *eventStoreRepository) PendingEvents(ctx context.Context, limit int) ([]repository.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pending := []repository.Event{}
+	for _, record := range r.log {
+		if len(pending) == limit {
+			break
+		}
+		if record.PublishedAt == nil {
+			pending = append(pending, record)
+		}
+	}
+	return pending, nil
+}
+
+func (r *eventStoreRepository) MarkEventPublished(ctx context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	i := sort.Search(len(r.log), func(i int) bool { return r.log[i].ID >= id })
+	if i == len(r.log) || r.log[i].ID != id {
+		return repository.ErrEventNotFound
+	}
+	previous, now := r.log[i].PublishedAt, time.Now().UTC()
+	r.log[i].PublishedAt = &now
+	if err := r.save(); err != nil {
+		r.log[i].PublishedAt = previous
+		return err
+	}
+	return nil
+}
+
+func (r *eventStoreRepository) Ping(ctx context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.path == "" {
+		return nil
+	}
+	return repository.PingFile(r.path)
+}
+
+// RebuildProjections replays every stream from its first event, replacing
+// both the projection and the snapshots, and returns the number of wallets.
+func (r *eventStoreRepository) RebuildProjections() (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	projection := map[uint64]repository.Wallet{}
+	snapshots := map[uint64][]Snapshot{}
+	for id, stream := range r.streams {
+		wallet := repository.Wallet{}
+		for _, i := range stream {
+			if err := apply(&wallet, r.log[i]); err != nil {
+				return 0, err
+			}
+			if wallet.Version%uint64(r.snapshotEvery) == 0 {
+				snapshots[id] = append(snapshots[id], Snapshot{Wallet: wallet, EventID: r.log[i].ID, OccurredAt: r.log[i].OccurredAt})
+			}
+		}
+		projection[id] = wallet
+	}
+
+	previousProjection, previousSnapshots := r.projection, r.snapshots
+	r.projection = projection
+	r.snapshots = snapshots
+	if err := r.save(); err != nil {
+		r.projection, r.snapshots = previousProjection, previousSnapshots
+		return 0, err
+	}
+	return len(projection), nil
+}
+
+// rehydrate rebuilds a wallet from its latest snapshot and the events
+// appended after it.
+func (r *eventStoreRepository) rehydrate(id uint64) (repository.Wallet, error) {
+	wallet := repository.Wallet{}
+	if snapshots := r.snapshots[id]; len(snapshots) > 0 {
+		wallet = snapshots[len(snapshots)-1].Wallet
+	}
+	for _, i := range r.streams[id][wallet.Version:] {
+		if err := apply(&wallet, r.log[i]); err != nil {
+			return repository.Wallet{}, err
+		}
+	}
+	return wallet, nil
+}
+
+// fold applies events to the current states and checks that the result is
+// the state the caller asked to store.
+func (r *eventStoreRepository) fold(current map[uint64]repository.Wallet, wallets []*repository.Wallet, events []repository.Event) (map[uint64]repository.Wallet, error) {
+	states := map[uint64]repository.Wallet{}
+	for id, state := range current {
+		states[id] = state
+	}
+
+	for _, record := range events {
+		state, ok := states[record.WalletID]
+		if !ok {
+			return nil, ErrUnrecordedChange
+		}
+		if err := apply(&state, record); err != nil {
+			return nil, err
+		}
+		states[record.WalletID] = state
+	}
+
+	for _, wallet := range wallets {
+		state := states[wallet.ID]
+		if state.Balance != wallet.Balance || state.Status != wallet.Status {
+			return nil, ErrUnrecordedChange
+		}
+	}
+	return states, nil
+}
+
+func (r *eventStoreRepository) commit(states map[uint64]repository.Wallet, events []repository.Event) {
+	for _, record := range events {
+		record.ID = r.nextEventID()
+		r.streams[record.WalletID] = append(r.streams[record.WalletID], len(r.log))
+		r.log = append(r.log, record)
+
+		stream := r.streams[record.WalletID]
+		if len(stream)%r.snapshotEvery == 0 {
+			snapshot, _ := r.rehydrate(record.WalletID)
+			r.snapshots[record.WalletID] = append(r.snapshots[record.WalletID], Snapshot{Wallet: snapshot, EventID: record.ID, OccurredAt: record.OccurredAt})
+		}
+	}
+
+	for id, state := range states {
+		r.projection[id] = state
+	}
+}
+
+// checkpoint returns a function that undoes a commit to the streams in ids,
+// for a save that failed. The log, streams, snapshots and projection are all
+// cut back together; replaying the file on start would rebuild them without
+// the commit anyway, so they must not hold it in the meantime.
+func (r *eventStoreRepository) checkpoint(ids ...uint64) func() {
+	log, lastID := len(r.log), r.lastID
+	streams, snapshots := map[uint64]int{}, map[uint64]int{}
+	projection := map[uint64]repository.Wallet{}
+	for _, id := range ids {
+		streams[id] = len(r.streams[id])
+		snapshots[id] = len(r.snapshots[id])
+		if wallet, ok := r.projection[id]; ok {
+			projection[id] = wallet
+		}
+	}
+
+	return func() {
+		r.log, r.lastID = r.log[:log], lastID
+		for _, id := range ids {
+			r.streams[id] = r.streams[id][:streams[id]]
+			r.snapshots[id] = r.snapshots[id][:snapshots[id]]
+			if wallet, ok := projection[id]; ok {
+				r.projection[id] = wallet
+			} else {
+				delete(r.projection, id)
+			}
+		}
+	}
+}
+
+// stamp copies events, attributing those without a wallet ID to walletID and
+// timestamping them, so that folding and storing see the same records.
+func stamp(events []repository.Event, walletID uint64) []repository.Event {
+	stamped := make([]repository.Event, len(events))
+	for i, record := range events {
+		if record.WalletID == 0 {
+			record.WalletID = walletID
+		}
+		if record.OccurredAt.IsZero() {
+			record.OccurredAt = time.Now().UTC()
+		}
+		stamped[i] = record
+	}
+	return stamped
+}
+
+func (r *eventStoreRepository) nextEventID() uint64 {
+	if len(r.log) == 0 {
+		return 1
+	}
+	return r.log[len(r.log)-1].ID + 1
+}
+
+func (r *eventStoreRepository) save() error {
+	if r.path == "" {
+		return nil
+	}
+
+	stored := storeFile{Events: r.log, Snapshots: []Snapshot{}, LastID: r.lastID}
+	for id := uint64(1); id <= r.lastID; id++ {
+		stored.Snapshots = append(stored.Snapshots, r.snapshots[id]...)
+		if wallet, ok := r.projection[id]; ok {
+			stored.Wallets = append(stored.Wallets, wallet)
+		}
+	}
+
+	content, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	return repository.WriteFile(r.path, content)
+}
+
+// apply advances a wallet by one event of its stream. Events that do not
+// change the wallet, such as TransferCompleted, still count as a version.
+func apply(wallet *repository.Wallet, record repository.Event) error {
+	decoded, err := event.Decode(record)
+	if err != nil {
+		return err
+	}
+
+	switch e := decoded.(type) {
+	case event.WalletOpened:
+		wallet.ID = record.WalletID
+		wallet.Name = e.Name
+		wallet.Balance = e.Balance
+		wallet.Status = repository.StatusActive
+		wallet.CreatedAt = record.OccurredAt
+	case event.FundsDeposited:
+		wallet.Balance = e.Balance
+	case event.FundsWithdrawn:
+		wallet.Balance = e.Balance
+	case event.BalanceAdjusted:
+		wallet.Balance = e.Balance
+	case event.StatusChanged:
+		wallet.Status = e.Status
+	}
+	wallet.Version++
+	return nil
+}
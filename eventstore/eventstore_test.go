@@ -0,0 +1,279 @@
+// go:build unit
+package eventstore_test
+
+import (
+	"context"
+	"gotest/event"
+	"gotest/eventstore"
+	"gotest/repository"
+	"gotest/service"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func opened(name string, balance float32) repository.Event {
+	record, _ := event.Encode(event.WalletOpened{Name: name, Balance: balance})
+	return record
+}
+
+func deposited(id uint64, amount float32, balance float32) repository.Event {
+	record, _ := event.Encode(event.FundsDeposited{WalletID: id, Amount: amount, Balance: balance})
+	return record
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		repo := eventstore.NewRepository()
+		wallet := repository.Wallet{Name: "John Doe", Balance: 100, Status: repository.StatusActive}
+		// act
+		err := repo.Create(context.Background(), &wallet, opened("John Doe", 100))
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), wallet.ID)
+		assert.Equal(t, uint64(1), wallet.Version)
+		result, _ := repo.Get(context.Background(), 1)
+		assert.Equal(t, wallet, *result)
+		events, _ := repo.Events(context.Background(), 1)
+		assert.Equal(t, uint64(1), events[0].WalletID)
+	})
+
+	t.Run("Unrecorded Change", func(t *testing.T) {
+		// arrange
+		repo := eventstore.NewRepository()
+		wallet := repository.Wallet{Name: "John Doe", Balance: 500, Status: repository.StatusActive}
+		// act
+		err := repo.Create(context.Background(), &wallet, opened("John Doe", 100))
+		// assert
+		assert.ErrorIs(t, err, eventstore.ErrUnrecordedChange)
+		assert.ErrorIs(t, repo.Create(context.Background(), &wallet), eventstore.ErrUnrecordedChange)
+		result, _ := repo.Get(context.Background(), 1)
+		assert.Equal(t, uint64(0), result.ID)
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		repo := eventstore.NewRepository()
+		wallet := repository.Wallet{Name: "John Doe", Balance: 100, Status: repository.StatusActive}
+		repo.Create(context.Background(), &wallet, opened("John Doe", 100))
+		wallet.Balance = 150
+		// act
+		err := repo.Update(context.Background(), wallet.ID, &wallet, deposited(wallet.ID, 50, 150))
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), wallet.Version)
+		result, _ := repo.Get(context.Background(), wallet.ID)
+		assert.Equal(t, float32(150), result.Balance)
+	})
+
+	t.Run("Version Conflict", func(t *testing.T) {
+		// arrange
+		repo := eventstore.NewRepository()
+		wallet := repository.Wallet{Name: "John Doe", Balance: 100, Status: repository.StatusActive}
+		repo.Create(context.Background(), &wallet, opened("John Doe", 100))
+		stale := wallet
+		wallet.Balance = 150
+		repo.Update(context.Background(), wallet.ID, &wallet, deposited(wallet.ID, 50, 150))
+		stale.Balance = 120
+		// act
+		err := repo.Update(context.Background(), stale.ID, &stale, deposited(stale.ID, 20, 120))
+		// assert
+		assert.ErrorIs(t, err, repository.ErrVersionConflict)
+		result, _ := repo.Get(context.Background(), wallet.ID)
+		assert.Equal(t, float32(150), result.Balance)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// arrange
+		repo := eventstore.NewRepository()
+		// act
+		err := repo.Update(context.Background(), 1, &repository.Wallet{}, deposited(1, 50, 50))
+		// assert
+		assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+	})
+}
+
+func TestUpdateMany(t *testing.T) {
+	t.Run("All Or Nothing", func(t *testing.T) {
+		// arrange
+		repo := eventstore.NewRepository()
+		john := repository.Wallet{Name: "John Doe", Balance: 100, Status: repository.StatusActive}
+		jane := repository.Wallet{Name: "Jane Doe", Balance: 0, Status: repository.StatusActive}
+		repo.Create(context.Background(), &john, opened("John Doe", 100))
+		repo.Create(context.Background(), &jane, opened("Jane Doe", 0))
+		john.Balance = 110
+		jane.Balance = 10
+		// act
+		err := repo.UpdateMany(context.Background(), []*repository.Wallet{&john, &jane}, deposited(john.ID, 10, 110), deposited(jane.ID, 10, 999))
+		// assert
+		assert.ErrorIs(t, err, eventstore.ErrUnrecordedChange)
+		events, _ := repo.Events(context.Background(), john.ID)
+		assert.Len(t, events, 1)
+	})
+
+	t.Run("Foreign Stream", func(t *testing.T) {
+		// arrange
+		repo := eventstore.NewRepository()
+		john := repository.Wallet{Name: "John Doe", Balance: 100, Status: repository.StatusActive}
+		jane := repository.Wallet{Name: "Jane Doe", Balance: 0, Status: repository.StatusActive}
+		repo.Create(context.Background(), &john, opened("John Doe", 100))
+		repo.Create(context.Background(), &jane, opened("Jane Doe", 0))
+		john.Balance = 110
+		// act
+		err := repo.UpdateMany(context.Background(), []*repository.Wallet{&john}, deposited(john.ID, 10, 110), deposited(jane.ID, 10, 10))
+		// assert
+		assert.ErrorIs(t, err, eventstore.ErrUnrecordedChange)
+	})
+}
+
+func TestSnapshots(t *testing.T) {
+	// arrange
+	repo := eventstore.NewRepository(eventstore.WithSnapshotEvery(3))
+	serv := service.NewWalletService(repo)
+	wallet := repository.Wallet{Name: "John Doe", Balance: 100}
+	serv.OpenAccount(context.Background(), &wallet)
+	for i := 0; i < 7; i++ {
+		serv.Deposit(context.Background(), wallet.ID, 10)
+	}
+	// act
+	snapshot, err := repo.BalanceSnapshotAt(context.Background(), wallet.ID, time.Now())
+	// assert
+	assert.NoError(t, err)
+	events, _ := repo.Events(context.Background(), wallet.ID)
+	assert.Equal(t, events[5].ID, snapshot.EventID)
+	assert.Equal(t, float32(150), snapshot.Balance)
+	balance, _ := serv.Withdraw(context.Background(), wallet.ID, 70)
+	assert.Equal(t, float32(100), balance)
+	result, _ := repo.Get(context.Background(), wallet.ID)
+	assert.Equal(t, uint64(9), result.Version)
+	balance, _ = serv.GetBalanceAt(context.Background(), wallet.ID, time.Now())
+	assert.Equal(t, float32(100), balance)
+}
+
+func TestServiceTransfer(t *testing.T) {
+	// arrange
+	repo := eventstore.NewRepository()
+	serv := service.NewWalletService(repo)
+	john := repository.Wallet{Name: "John Doe", Balance: 100}
+	jane := repository.Wallet{Name: "Jane Doe", Balance: 0}
+	serv.OpenAccount(context.Background(), &john)
+	serv.OpenAccount(context.Background(), &jane)
+	// act
+	err := serv.Transfer(context.Background(), john.ID, jane.ID, 40)
+	// assert
+	assert.Nil(t, err)
+	from, _ := repo.Get(context.Background(), john.ID)
+	to, _ := repo.Get(context.Background(), jane.ID)
+	assert.Equal(t, float32(60), from.Balance)
+	assert.Equal(t, float32(40), to.Balance)
+	pending, _ := repo.PendingEvents(context.Background(), 10)
+	assert.Len(t, pending, 5)
+}
+
+func TestRebuildProjections(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "events.json")
+	repo, _ := eventstore.NewFileRepository(path, eventstore.WithSnapshotEvery(2))
+	serv := service.NewWalletService(repo)
+	wallet := repository.Wallet{Name: "John Doe", Balance: 100}
+	serv.OpenAccount(context.Background(), &wallet)
+	serv.Deposit(context.Background(), wallet.ID, 25)
+	serv.Withdraw(context.Background(), wallet.ID, 5)
+	// act
+	reopened, err := eventstore.NewFileRepository(path, eventstore.WithSnapshotEvery(2))
+	wallets, rebuildErr := reopened.RebuildProjections()
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, rebuildErr)
+	assert.Equal(t, 1, wallets)
+	result, _ := reopened.Get(context.Background(), wallet.ID)
+	assert.Equal(t, float32(120), result.Balance)
+	assert.Equal(t, uint64(3), result.Version)
+	assert.Equal(t, repository.StatusActive, result.Status)
+	snapshot, _ := reopened.BalanceSnapshotAt(context.Background(), wallet.ID, time.Now())
+	assert.Equal(t, float32(125), snapshot.Balance)
+	_, err = service.NewWalletService(reopened).Deposit(context.Background(), wallet.ID, 1)
+	assert.Nil(t, err)
+}
+
+func TestFailedSave(t *testing.T) {
+	// arrange
+	dir := filepath.Join(t.TempDir(), "data")
+	os.Mkdir(dir, 0o755)
+	path := filepath.Join(dir, "events.json")
+	repo, _ := eventstore.NewFileRepository(path, eventstore.WithSnapshotEvery(2))
+	wallet := repository.Wallet{Name: "John Doe", Balance: 100, Status: repository.StatusActive}
+	repo.Create(context.Background(), &wallet, opened("John Doe", 100))
+	os.RemoveAll(dir)
+	// act
+	updated := wallet
+	updated.Balance = 150
+	updateErr := repo.Update(context.Background(), wallet.ID, &updated, deposited(wallet.ID, 50, 150))
+	next := repository.Wallet{Name: "Jane Doe", Balance: 10, Status: repository.StatusActive}
+	createErr := repo.Create(context.Background(), &next, opened("Jane Doe", 10))
+	publishErr := repo.MarkEventPublished(context.Background(), 1)
+	// assert
+	assert.ErrorIs(t, updateErr, repository.ErrUnavailable)
+	assert.ErrorIs(t, createErr, repository.ErrUnavailable)
+	assert.ErrorIs(t, publishErr, repository.ErrUnavailable)
+	assert.Equal(t, uint64(1), updated.Version)
+	assert.Equal(t, uint64(0), next.ID)
+	result, _ := repo.Get(context.Background(), wallet.ID)
+	assert.Equal(t, wallet, *result)
+	events, _ := repo.Events(context.Background(), wallet.ID)
+	assert.Len(t, events, 1)
+	pending, _ := repo.PendingEvents(context.Background(), 10)
+	assert.Len(t, pending, 1)
+	snapshot, _ := repo.BalanceSnapshotAt(context.Background(), wallet.ID, time.Now())
+	assert.Equal(t, uint64(0), snapshot.EventID)
+	os.Mkdir(dir, 0o755)
+	assert.NoError(t, repo.Update(context.Background(), wallet.ID, &updated, deposited(wallet.ID, 50, 150)))
+	assert.Equal(t, uint64(2), updated.Version)
+	reopened, _ := eventstore.NewFileRepository(path, eventstore.WithSnapshotEvery(2))
+	events, _ = reopened.Events(context.Background(), wallet.ID)
+	assert.Equal(t, []uint64{1, 2}, []uint64{events[0].ID, events[1].ID})
+}
+
+func TestRebuildStatus(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "events.json")
+	repo, _ := eventstore.NewFileRepository(path)
+	serv := service.NewWalletService(repo)
+	wallet := repository.Wallet{Name: "John Doe", Balance: 100}
+	serv.OpenAccount(context.Background(), &wallet)
+	serv.SetStatus(context.Background(), wallet.ID, repository.StatusFrozen, "fraud check")
+	// act
+	reopened, err := eventstore.NewFileRepository(path)
+	_, rebuildErr := reopened.RebuildProjections()
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, rebuildErr)
+	result, _ := reopened.Get(context.Background(), wallet.ID)
+	assert.Equal(t, repository.StatusFrozen, result.Status)
+	assert.Equal(t, uint64(2), result.Version)
+	_, err = service.NewWalletService(reopened).Deposit(context.Background(), wallet.ID, 1)
+	assert.ErrorIs(t, err, service.ErrWalletFrozen)
+}
+
+func TestCanceledContext(t *testing.T) {
+	// arrange
+	repo := eventstore.NewRepository()
+	wallet := repository.Wallet{Name: "John Doe", Balance: 100, Status: repository.StatusActive}
+	repo.Create(context.Background(), &wallet, opened("John Doe", 100))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	wallet.Balance = 150
+	// act
+	err := repo.Update(ctx, wallet.ID, &wallet, deposited(wallet.ID, 50, 150))
+	// assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	events, _ := repo.Events(context.Background(), wallet.ID)
+	assert.Len(t, events, 1)
+}
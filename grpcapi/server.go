@@ -0,0 +1,142 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"gotest/audit"
+	"gotest/grpcapi/walletpb"
+	"gotest/repository"
+	"gotest/service"
+	"net"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type walletServer struct {
+	walletpb.UnimplementedWalletServiceServer
+	walletServ service.WalletService
+}
+
+func NewWalletServer(walletServ service.WalletService) walletServer {
+	return walletServer{walletServ: walletServ}
+}
+
+var statusCodes = map[service.ErrorCode]codes.Code{
+	service.CodeNotFound:          codes.NotFound,
+	service.CodeInvalidRequest:    codes.InvalidArgument,
+	service.CodeInvalidParameter:  codes.InvalidArgument,
+	service.CodeInsufficientFunds: codes.FailedPrecondition,
+	service.CodeConflict:          codes.Aborted,
+	service.CodeTimeout:           codes.DeadlineExceeded,
+	service.CodeCanceled:          codes.Canceled,
+	service.CodeLimitExceeded:     codes.FailedPrecondition,
+	service.CodeWalletFrozen:      codes.FailedPrecondition,
+	service.CodeUnavailable:       codes.Unavailable,
+	service.CodeBusy:              codes.ResourceExhausted,
+}
+
+func ResponseError(err error) error {
+	var e service.WalletError
+	if !errors.As(err, &e) {
+		return status.Error(codes.Internal, service.ErrUnexpected.Message)
+	}
+
+	code, ok := statusCodes[e.Code]
+	if !ok {
+		code = codes.Internal
+	}
+	return status.Error(code, e.Message)
+}
+
+// origin tells the audit log where a call came from: the peer address and
+// the unverified x-actor metadata.
+func origin(ctx context.Context) context.Context {
+	o := audit.Origin{}
+	if p, ok := peer.FromContext(ctx); ok {
+		o.ClientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(o.ClientIP); err == nil {
+			o.ClientIP = host
+		}
+	}
+	if actors := metadata.ValueFromIncomingContext(ctx, "x-actor"); len(actors) > 0 {
+		o.ClaimedActor = actors[0]
+	}
+	return audit.WithOrigin(ctx, o)
+}
+
+func (s walletServer) OpenAccount(ctx context.Context, req *walletpb.OpenAccountRequest) (*walletpb.Wallet, error) {
+	if req.GetName() == "" {
+		return nil, ResponseError(service.NewErrorUnprocessableEntity())
+	}
+
+	wallet := repository.Wallet{
+		Name:    req.GetName(),
+		Balance: req.GetBalance(),
+	}
+	if err := s.walletServ.OpenAccount(origin(ctx), &wallet); err != nil {
+		return nil, ResponseError(err)
+	}
+
+	return toWallet(&wallet), nil
+}
+
+func (s walletServer) GetAccount(ctx context.Context, req *walletpb.GetAccountRequest) (*walletpb.Wallet, error) {
+	wallet, err := s.walletServ.GetAccount(ctx, req.GetId())
+	if err != nil {
+		return nil, ResponseError(err)
+	}
+
+	return toWallet(wallet), nil
+}
+
+func (s walletServer) Withdraw(ctx context.Context, req *walletpb.TransactionRequest) (*walletpb.BalanceResponse, error) {
+	balance, err := s.walletServ.Withdraw(origin(ctx), req.GetId(), req.GetAmount())
+	if err != nil {
+		return nil, ResponseError(err)
+	}
+
+	return &walletpb.BalanceResponse{Id: req.GetId(), Balance: balance}, nil
+}
+
+func (s walletServer) Deposit(ctx context.Context, req *walletpb.TransactionRequest) (*walletpb.BalanceResponse, error) {
+	balance, err := s.walletServ.Deposit(origin(ctx), req.GetId(), req.GetAmount())
+	if err != nil {
+		return nil, ResponseError(err)
+	}
+
+	return &walletpb.BalanceResponse{Id: req.GetId(), Balance: balance}, nil
+}
+
+func (s walletServer) ListTransactions(req *walletpb.ListTransactionsRequest, stream walletpb.WalletService_ListTransactionsServer) error {
+	transactions, err := s.walletServ.History(stream.Context(), req.GetId())
+	if err != nil {
+		return ResponseError(err)
+	}
+
+	for _, transaction := range transactions {
+		err := stream.Send(&walletpb.Transaction{
+			Id:         transaction.ID,
+			WalletId:   transaction.WalletID,
+			Type:       transaction.Type,
+			Amount:     transaction.Amount,
+			Balance:    transaction.Balance,
+			OccurredAt: timestamppb.New(transaction.OccurredAt),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toWallet(wallet *repository.Wallet) *walletpb.Wallet {
+	return &walletpb.Wallet{
+		Id:      wallet.ID,
+		Name:    wallet.Name,
+		Balance: wallet.Balance,
+	}
+}
@@ -0,0 +1,263 @@
+// go:build unit
+package grpcapi_test
+
+import (
+	"context"
+	"gotest/audit"
+	"gotest/grpcapi"
+	"gotest/grpcapi/walletpb"
+	"gotest/repository"
+	"gotest/service"
+	"io"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newClient(t *testing.T, serv service.WalletService) walletpb.WalletServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	walletpb.RegisterWalletServiceServer(server, grpcapi.NewWalletServer(serv))
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+	return walletpb.NewWalletServiceClient(conn)
+}
+
+func TestOpenAccount(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		wallet := repository.Wallet{Name: "John Doe", Balance: 1000}
+		serv := service.NewWalletServiceMock()
+		serv.On("OpenAccount", &wallet).Return(nil)
+		client := newClient(t, serv)
+		// act
+		result, err := client.OpenAccount(context.Background(), &walletpb.OpenAccountRequest{Name: "John Doe", Balance: 1000})
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "John Doe", result.GetName())
+	})
+
+	t.Run("Invalid Argument", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		client := newClient(t, serv)
+		// act
+		_, err := client.OpenAccount(context.Background(), &walletpb.OpenAccountRequest{Balance: 1000})
+		// assert
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		serv.AssertNotCalled(t, "OpenAccount")
+	})
+}
+
+func TestGetAccount(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		wallet := repository.Wallet{ID: 1, Name: "John Doe", Balance: 1000}
+		serv := service.NewWalletServiceMock()
+		serv.On("GetAccount", id).Return(&wallet, nil)
+		client := newClient(t, serv)
+		// act
+		result, err := client.GetAccount(context.Background(), &walletpb.GetAccountRequest{Id: id})
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, float32(1000), result.GetBalance())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		serv := service.NewWalletServiceMock()
+		serv.On("GetAccount", id).Return(&repository.Wallet{}, service.NewErrorWalletNotFound())
+		client := newClient(t, serv)
+		// act
+		_, err := client.GetAccount(context.Background(), &walletpb.GetAccountRequest{Id: id})
+		// assert
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestWithdraw(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		serv := service.NewWalletServiceMock()
+		serv.On("Withdraw", id, float32(200)).Return(float32(800), nil)
+		client := newClient(t, serv)
+		// act
+		result, err := client.Withdraw(context.Background(), &walletpb.TransactionRequest{Id: id, Amount: 200})
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, float32(800), result.GetBalance())
+	})
+
+	t.Run("Not Enough Money", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		serv := service.NewWalletServiceMock()
+		serv.On("Withdraw", id, float32(2000)).Return(float32(0), service.NewErrorNotEnoughMoney())
+		client := newClient(t, serv)
+		// act
+		_, err := client.Withdraw(context.Background(), &walletpb.TransactionRequest{Id: id, Amount: 2000})
+		// assert
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Equal(t, "NOT ENOUGH MONEY", status.Convert(err).Message())
+	})
+
+	t.Run("Wallet Frozen", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		serv := service.NewWalletServiceMock()
+		serv.On("Withdraw", id, float32(200)).Return(float32(0), service.NewErrorWalletFrozen())
+		client := newClient(t, serv)
+		// act
+		_, err := client.Withdraw(context.Background(), &walletpb.TransactionRequest{Id: id, Amount: 200})
+		// assert
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Equal(t, "WALLET FROZEN", status.Convert(err).Message())
+	})
+}
+
+func TestAudit(t *testing.T) {
+	// arrange
+	auditLog := audit.NewLoggerMock()
+	auditLog.On("Record", mock.MatchedBy(func(e audit.Event) bool {
+		return e.Action == audit.ActionDeposit &&
+			e.Actor == "anonymous" &&
+			e.ClaimedActor == "alice" &&
+			e.ClientIP != "" &&
+			e.WalletID == 1 &&
+			e.BalanceBefore == 100 &&
+			e.BalanceAfter == 150
+	})).Return(nil)
+	next := service.NewWalletServiceMock()
+	next.On("Deposit", uint64(1), float32(50)).Return(float32(150), nil)
+	client := newClient(t, audit.NewWalletService(next, auditLog))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-actor", "alice")
+	// act
+	_, err := client.Deposit(ctx, &walletpb.TransactionRequest{Id: 1, Amount: 50})
+	// assert
+	assert.NoError(t, err)
+	auditLog.AssertExpectations(t)
+}
+
+func TestInvalidAmount(t *testing.T) {
+	amounts := map[string]float32{
+		"Negative":          -5,
+		"Zero":              0,
+		"NaN":               float32(math.NaN()),
+		"Positive Infinity": float32(math.Inf(1)),
+		"Negative Infinity": float32(math.Inf(-1)),
+	}
+	for name, amount := range amounts {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			serv := service.NewWalletService(repository.NewMemoryWalletRepository())
+			serv.OpenAccount(context.Background(), &repository.Wallet{Name: "John Doe", Balance: 100})
+			client := newClient(t, serv)
+			request := &walletpb.TransactionRequest{Id: 1, Amount: amount}
+			// act
+			_, withdrawErr := client.Withdraw(context.Background(), request)
+			_, depositErr := client.Deposit(context.Background(), request)
+			// assert
+			assert.Equal(t, codes.InvalidArgument, status.Code(withdrawErr))
+			assert.Equal(t, codes.InvalidArgument, status.Code(depositErr))
+			wallet, _ := serv.GetAccount(context.Background(), 1)
+			assert.Equal(t, float32(100), wallet.Balance)
+		})
+	}
+}
+
+func TestDeposit(t *testing.T) {
+	t.Run("Unexpected", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		serv := service.NewWalletServiceMock()
+		serv.On("Deposit", id, float32(200)).Return(float32(0), service.NewErrorWalletUnexpected())
+		client := newClient(t, serv)
+		// act
+		_, err := client.Deposit(context.Background(), &walletpb.TransactionRequest{Id: id, Amount: 200})
+		// assert
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		serv := service.NewWalletServiceMock()
+		serv.On("Deposit", id, float32(200)).Return(float32(0), service.ErrTimeout.Wrap(context.DeadlineExceeded))
+		client := newClient(t, serv)
+		// act
+		_, err := client.Deposit(context.Background(), &walletpb.TransactionRequest{Id: id, Amount: 200})
+		// assert
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		assert.Equal(t, "TIMEOUT", status.Convert(err).Message())
+	})
+}
+
+func TestListTransactions(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		serv := service.NewWalletServiceMock()
+		serv.On("History", id).Return([]service.Transaction{
+			{ID: 1, WalletID: id, Type: "WalletOpened", Amount: 1000, Balance: 1000, OccurredAt: at},
+			{ID: 2, WalletID: id, Type: "FundsWithdrawn", Amount: -200, Balance: 800, OccurredAt: at},
+		}, nil)
+		client := newClient(t, serv)
+		// act
+		stream, err := client.ListTransactions(context.Background(), &walletpb.ListTransactionsRequest{Id: id})
+		// assert
+		assert.NoError(t, err)
+		received := []*walletpb.Transaction{}
+		for {
+			transaction, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			received = append(received, transaction)
+		}
+		assert.Len(t, received, 2)
+		assert.Equal(t, float32(-200), received[1].GetAmount())
+		assert.Equal(t, at, received[1].GetOccurredAt().AsTime())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		serv := service.NewWalletServiceMock()
+		serv.On("History", id).Return([]service.Transaction{}, service.NewErrorWalletNotFound())
+		client := newClient(t, serv)
+		// act
+		stream, _ := client.ListTransactions(context.Background(), &walletpb.ListTransactionsRequest{Id: id})
+		_, err := stream.Recv()
+		// assert
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
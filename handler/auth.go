@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"gotest/auth"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireScope lets through requests whose bearer token authenticates to an
+// identity with scope, and puts that identity in the user context. Without
+// an authenticator every request is refused.
+func RequireScope(authenticator auth.Authenticator, scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || token == "" || authenticator == nil {
+			return c.Status(401).SendString("UNAUTHORIZED")
+		}
+		identity, ok := authenticator.Authenticate(token)
+		if !ok {
+			return c.Status(401).SendString("UNAUTHORIZED")
+		}
+		if !identity.Has(scope) {
+			return c.Status(403).SendString("FORBIDDEN")
+		}
+
+		c.SetUserContext(auth.WithIdentity(c.UserContext(), identity))
+		return c.Next()
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"gotest/service"
+	"gotest/walletcsv"
+	"io"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h walletHandler) ImportCSV(c *fiber.Ctx) error {
+	report, err := walletcsv.Import(c.UserContext(), bytes.NewReader(c.Body()), h.walletServ)
+	if errors.Is(err, walletcsv.ErrInvalidHeader) {
+		e := service.NewErrorBadRequest("INVALID CSV HEADER")
+		return ResponseError(c, e)
+	}
+
+	// Wallets opened before the import stopped stay open, so they are
+	// reported even when it did not finish.
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return c.Status(StatusCode(service.ErrTimeout)).JSON(report)
+	case errors.Is(err, context.Canceled):
+		return c.Status(StatusCode(service.ErrCanceled)).JSON(report)
+	case err != nil:
+		return ResponseError(c, err)
+	}
+	return c.Status(200).JSON(report)
+}
+
+// ExportCSV streams the export. The first page is fetched before the response
+// starts, so failing to read it is still an ordinary error response; a later
+// failure aborts the stream, leaving the client a truncated chunked body
+// rather than a file that looks complete. The stream outlives the handler,
+// and with it the request's deadline, so each page is bounded by the
+// service's own timeouts instead.
+func (h walletHandler) ExportCSV(c *fiber.Ctx) error {
+	ctx := context.WithoutCancel(c.UserContext())
+	first, err := walletcsv.FirstPage(ctx, h.walletServ)
+	if err != nil {
+		return ResponseError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="wallets.csv"`)
+
+	reader, writer := io.Pipe()
+	go func() {
+		buffered := bufio.NewWriter(writer)
+		err := walletcsv.ExportFrom(ctx, buffered, h.walletServ, first)
+		if err == nil {
+			err = buffered.Flush()
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "csv export aborted", slog.Any("error", err))
+		}
+		// A nil error ends the body normally; any other drops the connection
+		// before the final chunk.
+		writer.CloseWithError(err)
+	}()
+	c.Context().SetBodyStream(reader, -1)
+	return nil
+}
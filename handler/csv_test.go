@@ -0,0 +1,122 @@
+// go:build unit
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gotest/audit"
+	"gotest/handler"
+	"gotest/repository"
+	"gotest/service"
+	"gotest/walletcsv"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestImportCSV(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletService(repository.NewMemoryWalletRepository())
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets/import", strings.NewReader("name,balance\nJohn Doe,1000\n,5\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		report := walletcsv.Report{}
+		json.NewDecoder(resp.Body).Decode(&report)
+		assert.Equal(t, 1, report.Imported)
+		assert.Equal(t, 1, report.Failed)
+	})
+
+	t.Run("Timeout Keeps Imported Wallets", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("OpenAccount", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(0).(*repository.Wallet).ID = 1
+			time.Sleep(30 * time.Millisecond)
+		})
+		auditLog := audit.NewLoggerMock()
+		auditLog.On("Record", mock.MatchedBy(func(e audit.Event) bool {
+			return e.Action == audit.ActionOpenAccount && e.WalletID == 1 && e.BalanceAfter == 1000
+		})).Return(nil)
+		app := fiber.New()
+		app.Use(handler.Timeout(20 * time.Millisecond))
+		handler.RegisterRoutes(app, handler.Handlers{Wallet: handler.NewWalletHandler(audit.NewWalletService(serv, auditLog))})
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets/import", strings.NewReader("name,balance\nJohn Doe,1000\nJane Doe,5\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 504, resp.StatusCode)
+		report := walletcsv.Report{}
+		json.NewDecoder(resp.Body).Decode(&report)
+		assert.Equal(t, 1, report.Imported)
+		serv.AssertNumberOfCalls(t, "OpenAccount", 1)
+		auditLog.AssertNumberOfCalls(t, "Record", 1)
+	})
+
+	t.Run("Invalid Header", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets/import", strings.NewReader("who,what\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+}
+
+func TestExportCSV(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletService(repository.NewMemoryWalletRepository())
+		serv.OpenAccount(context.Background(), &repository.Wallet{Name: "John Doe", Balance: 1000})
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/export", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "text/csv", resp.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(resp.Body)
+		assert.True(t, strings.HasPrefix(string(body), "id,name,balance,status,created_at\n1,John Doe,1000,ACTIVE,"))
+	})
+
+	t.Run("First Page Fails", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("ListAccounts", repository.WalletFilter{}, repository.WalletSort{}, "", service.MaxPageSize).Return([]repository.Wallet(nil), "", errors.New("storage down"))
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/export", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 500, resp.StatusCode)
+	})
+
+	t.Run("Later Page Fails", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("ListAccounts", repository.WalletFilter{}, repository.WalletSort{}, "", service.MaxPageSize).Return([]repository.Wallet{{ID: 1, Name: "John Doe", Status: repository.StatusActive}}, "next", nil)
+		serv.On("ListAccounts", repository.WalletFilter{}, repository.WalletSort{}, "next", service.MaxPageSize).Return([]repository.Wallet(nil), "", errors.New("storage down"))
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/export", nil)
+		// act
+		_, err := app.Test(req)
+		// assert
+		assert.Error(t, err)
+	})
+}
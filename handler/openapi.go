@@ -0,0 +1,385 @@
+package handler
+
+import (
+	"encoding/json"
+	"gotest/health"
+	"gotest/repository"
+	"gotest/service"
+	"gotest/statement"
+	"gotest/stream"
+	"gotest/walletcsv"
+	"gotest/webhook"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Schema map[string]interface{}
+
+type Parameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+	Schema   Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       map[string]string               `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components map[string]map[string]Schema    `json:"components"`
+}
+
+func OpenAPI(c *fiber.Ctx) error {
+	return c.Status(200).JSON(Spec())
+}
+
+// Spec describes every route mounted by RegisterProbes and RegisterRoutes. Request and response
+// schemas are derived from the Go types, so field changes show up here
+// without editing the document by hand.
+func Spec() Document {
+	schemas := map[string]Schema{
+		"Wallet":              schemaOf(repository.Wallet{}),
+		"TransactionRequest":  schemaOf(TransactionRequest{}),
+		"BalanceResponse":     schemaOf(BalanceResponse{}),
+		"HistoricalBalance":   schemaOf(HistoricalBalance{}),
+		"WalletPage":          schemaOf(WalletPage{}),
+		"StatusRequest":       schemaOf(StatusRequest{}),
+		"Transaction":         schemaOf(service.Transaction{}),
+		"BatchRequest":        schemaOf(BatchRequest{}),
+		"BatchResponse":       schemaOf(BatchResponse{}),
+		"ImportReport":        schemaOf(walletcsv.Report{}),
+		"SubscriptionRequest": schemaOf(SubscriptionRequest{}),
+		"Subscription":        schemaOf(webhook.Subscription{}),
+		"Delivery":            schemaOf(webhook.Delivery{}),
+		"DeadLetter":          schemaOf(webhook.DeadLetter{}),
+		"BalanceUpdate":       schemaOf(stream.BalanceUpdate{}),
+		"HealthReport":        schemaOf(health.Report{}),
+		"Error":               {"type": "string", "example": "WALLET NOT FOUND"},
+	}
+
+	listParams := []Parameter{
+		{Name: "name", In: "query", Schema: Schema{"type": "string", "description": "Case-insensitive name prefix"}},
+		{Name: "status", In: "query", Schema: Schema{"type": "string", "enum": []string{repository.StatusActive, repository.StatusFrozen, repository.StatusClosed}}},
+		{Name: "min_balance", In: "query", Schema: Schema{"type": "number"}},
+		{Name: "max_balance", In: "query", Schema: Schema{"type": "number"}},
+		{Name: "created_after", In: "query", Schema: Schema{"type": "string", "format": "date-time"}},
+		{Name: "created_before", In: "query", Schema: Schema{"type": "string", "format": "date-time"}},
+		{Name: "sort", In: "query", Schema: Schema{"type": "string", "enum": []string{"id", "-id", "name", "-name", "balance", "-balance", "created_at", "-created_at"}}},
+		{Name: "cursor", In: "query", Schema: Schema{"type": "string", "description": "next_cursor of the previous page"}},
+		{Name: "limit", In: "query", Schema: Schema{"type": "integer", "default": 20, "maximum": 100}},
+	}
+	id := []Parameter{{Name: "id", In: "path", Required: true, Schema: Schema{"type": "integer", "format": "int64"}}}
+	wallets := []string{"wallets"}
+	webhooks := []string{"webhooks"}
+	operations := []string{"operations"}
+
+	return Document{
+		OpenAPI: "3.0.3",
+		Info:    map[string]string{"title": "Wallet API", "version": "1.0.0"},
+		Paths: map[string]map[string]Operation{
+			"/openapi.json": {
+				"get": {OperationID: "getOpenAPI", Summary: "This document", Responses: map[string]Response{
+					"200": jsonResponse("OpenAPI document", Schema{"type": "object"}),
+				}},
+			},
+			"/healthz": {
+				"get": {OperationID: "liveness", Summary: "Liveness probe", Tags: operations, Responses: map[string]Response{
+					"200": jsonResponse("The process is serving requests", ref("HealthReport")),
+				}},
+			},
+			"/readyz": {
+				"get": {OperationID: "readiness", Summary: "Readiness probe with the state of each dependency", Tags: operations, Responses: map[string]Response{
+					"200": jsonResponse("Every dependency is up", ref("HealthReport")),
+					"503": jsonResponse("A dependency is down, or the service is draining", ref("HealthReport")),
+				}},
+			},
+			"/metrics": {
+				"get": {OperationID: "metrics", Summary: "Prometheus metrics", Tags: operations, Responses: map[string]Response{
+					"200": {Description: "Prometheus text exposition format", Content: map[string]MediaType{
+						"text/plain": {Schema: Schema{"type": "string"}},
+					}},
+				}},
+			},
+			"/v1/wallets": {
+				"post": {OperationID: "openAccount", Summary: "Open a wallet", Tags: wallets,
+					RequestBody: jsonBody(ref("Wallet")),
+					Responses: withErrors(map[string]Response{
+						"201": jsonResponse("Wallet opened; Location points at it", ref("Wallet")),
+					}, "422", "500", "503", "504"),
+				},
+				"get": {OperationID: "listAccounts", Summary: "Search wallets", Tags: wallets, Parameters: listParams,
+					Responses: withErrors(map[string]Response{
+						"200": jsonResponse("One page of wallets", ref("WalletPage")),
+					}, "400", "422", "500", "503", "504"),
+				},
+			},
+			"/v1/wallets/batch": {
+				"post": {OperationID: "batch", Summary: "Apply many deposits and withdrawals; mode is \"atomic\" or \"best_effort\"", Tags: wallets,
+					RequestBody: jsonBody(ref("BatchRequest")),
+					Responses: withErrors(map[string]Response{
+						"200": jsonResponse("Per-operation results", ref("BatchResponse")),
+					}, "400", "422", "500", "503", "504"),
+				},
+			},
+			"/v1/wallets/import": {
+				"post": {OperationID: "importCSV", Summary: "Open wallets from a CSV with name and balance columns", Tags: wallets,
+					RequestBody: &RequestBody{Required: true, Content: map[string]MediaType{"text/csv": {Schema: Schema{"type": "string"}}}},
+					Responses: withErrors(map[string]Response{
+						"200": jsonResponse("Import report with row-level errors", ref("ImportReport")),
+						"504": jsonResponse("Timed out; the report covers the rows imported so far", ref("ImportReport")),
+					}, "400", "500", "503"),
+				},
+			},
+			"/v1/wallets/export": {
+				"get": {OperationID: "exportCSV", Summary: "Export all wallets and balances", Tags: wallets,
+					Responses: map[string]Response{
+						"200": {Description: "Columns: " + strings.Join(walletcsv.Header, ", "), Content: map[string]MediaType{
+							"text/csv": {Schema: Schema{"type": "string"}},
+						}},
+					},
+				},
+			},
+			"/v1/wallets/{id}": {
+				"get": {OperationID: "getAccount", Summary: "Get a wallet, or its balance at a point in time", Tags: wallets,
+					Parameters: append(id, Parameter{Name: "at", In: "query", Schema: Schema{"type": "string", "format": "date-time", "description": "Return a HistoricalBalance instead of the wallet"}}),
+					Responses: withErrors(map[string]Response{
+						"200": jsonResponse("Wallet, or HistoricalBalance when at is given", Schema{"oneOf": []Schema{ref("Wallet"), ref("HistoricalBalance")}}),
+					}, "404", "422", "500", "503", "504"),
+				},
+			},
+			"/v1/wallets/{id}/withdrawals": {
+				"post": {OperationID: "withdraw", Summary: "Withdraw from a wallet", Tags: wallets, Parameters: id,
+					RequestBody: jsonBody(ref("TransactionRequest")),
+					Responses: withErrors(map[string]Response{
+						"200": jsonResponse("New balance", ref("BalanceResponse")),
+					}, "400", "404", "409", "422", "423", "500", "503", "504"),
+				},
+			},
+			"/v1/wallets/{id}/deposits": {
+				"post": {OperationID: "deposit", Summary: "Deposit into a wallet", Tags: wallets, Parameters: id,
+					RequestBody: jsonBody(ref("TransactionRequest")),
+					Responses: withErrors(map[string]Response{
+						"200": jsonResponse("New balance", ref("BalanceResponse")),
+					}, "400", "404", "409", "422", "423", "500", "503", "504"),
+				},
+			},
+			"/v1/wallets/{id}/status": {
+				"put": {OperationID: "setStatus", Summary: "Freeze (FROZEN) or reactivate (ACTIVE) a wallet", Tags: wallets, Parameters: id,
+					RequestBody: jsonBody(ref("StatusRequest")),
+					Responses: adminOnly(withErrors(map[string]Response{
+						"200": jsonResponse("Wallet with its new status", ref("Wallet")),
+					}, "400", "404", "409", "422", "500", "503", "504")),
+				},
+			},
+			"/v1/wallets/{id}/transactions": {
+				"get": {OperationID: "history", Summary: "Every balance change of a wallet, oldest first", Tags: wallets, Parameters: id,
+					Responses: withErrors(map[string]Response{
+						"200": jsonResponse("Transactions", arrayOf(ref("Transaction"))),
+					}, "404", "422", "500", "503", "504"),
+				},
+			},
+			"/v1/wallets/{id}/statements": {
+				"get": {OperationID: "statement", Summary: "Opening balance, transactions and closing balance for a period", Tags: wallets,
+					Parameters: append(id,
+						Parameter{Name: "from", In: "query", Schema: Schema{"type": "string", "description": "Date or RFC 3339 timestamp, inclusive; defaults to the start of to's month"}},
+						Parameter{Name: "to", In: "query", Schema: Schema{"type": "string", "description": "Date (whole day included) or RFC 3339 timestamp, exclusive; defaults to now"}},
+						Parameter{Name: "format", In: "query", Schema: Schema{"type": "string", "enum": []string{statement.FormatCSV, statement.FormatPDF}, "default": statement.FormatCSV}},
+					),
+					Responses: withErrors(map[string]Response{
+						"200": {Description: "Statement; CSV columns: " + strings.Join(statement.Header, ", "), Content: map[string]MediaType{
+							"text/csv":        {Schema: Schema{"type": "string"}},
+							"application/pdf": {Schema: Schema{"type": "string", "format": "binary"}},
+						}},
+					}, "400", "404", "422", "500", "503", "504"),
+				},
+			},
+			"/v1/wallets/{id}/stream": {
+				"get": {OperationID: "streamBalance", Summary: "Stream balance changes as Server-Sent Events", Tags: wallets,
+					Parameters: append(id, Parameter{Name: "access_token", In: "query", Schema: Schema{"type": "string"}}),
+					Responses: withErrors(map[string]Response{
+						"200": {Description: "`balance` events carrying a BalanceUpdate", Content: map[string]MediaType{
+							"text/event-stream": {Schema: ref("BalanceUpdate")},
+						}},
+						"401": textResponse("Missing token"),
+						"403": textResponse("Token may not read this wallet"),
+					}, "404", "422", "500", "503", "504"),
+				},
+			},
+			"/v1/webhooks": {
+				"post": {OperationID: "createSubscription", Summary: "Subscribe to wallet events", Tags: webhooks,
+					RequestBody: jsonBody(ref("SubscriptionRequest")),
+					Responses: adminOnly(withErrors(map[string]Response{
+						"201": jsonResponse("Subscription, including its signing secret", ref("Subscription")),
+					}, "400", "422", "500")),
+				},
+				"get": {OperationID: "listSubscriptions", Summary: "List subscriptions", Tags: webhooks,
+					Responses: adminOnly(withErrors(map[string]Response{
+						"200": jsonResponse("Subscriptions", arrayOf(ref("Subscription"))),
+					}, "500")),
+				},
+			},
+			"/v1/webhooks/{id}": {
+				"get": {OperationID: "getSubscription", Summary: "Get a subscription", Tags: webhooks, Parameters: id,
+					Responses: adminOnly(withErrors(map[string]Response{
+						"200": jsonResponse("Subscription", ref("Subscription")),
+					}, "404", "422", "500")),
+				},
+				"delete": {OperationID: "deleteSubscription", Summary: "Delete a subscription", Tags: webhooks, Parameters: id,
+					Responses: adminOnly(withErrors(map[string]Response{
+						"204": {Description: "Deleted"},
+					}, "404", "422", "500")),
+				},
+			},
+			"/v1/webhooks/{id}/deliveries": {
+				"get": {OperationID: "listDeliveries", Summary: "Delivery log of a subscription", Tags: webhooks, Parameters: id,
+					Responses: adminOnly(withErrors(map[string]Response{
+						"200": jsonResponse("Delivery attempts", arrayOf(ref("Delivery"))),
+					}, "404", "422", "500")),
+				},
+			},
+			"/v1/webhooks/{id}/dead-letters": {
+				"get": {OperationID: "listDeadLetters", Summary: "Events that could not be delivered", Tags: webhooks, Parameters: id,
+					Responses: adminOnly(withErrors(map[string]Response{
+						"200": jsonResponse("Dead letters", arrayOf(ref("DeadLetter"))),
+					}, "404", "422", "500")),
+				},
+			},
+		},
+		Components: map[string]map[string]Schema{"schemas": schemas},
+	}
+}
+
+func ref(name string) Schema {
+	return Schema{"$ref": "#/components/schemas/" + name}
+}
+
+func arrayOf(items Schema) Schema {
+	return Schema{"type": "array", "items": items}
+}
+
+func jsonBody(schema Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]MediaType{"application/json": {Schema: schema}}}
+}
+
+func jsonResponse(description string, schema Schema) Response {
+	return Response{Description: description, Content: map[string]MediaType{"application/json": {Schema: schema}}}
+}
+
+func textResponse(description string) Response {
+	return Response{Description: description, Content: map[string]MediaType{"text/plain": {Schema: Schema{"type": "string"}}}}
+}
+
+var errorDescriptions = map[string]string{
+	"400": "Request rejected by a business rule",
+	"404": "Not found",
+	"409": "Wallet changed concurrently; retry the request",
+	"422": "Invalid parameter",
+	"423": "Wallet frozen",
+	"500": "Unexpected error",
+	"503": "Storage unavailable or too busy; retry later",
+	"504": "Operation timed out",
+}
+
+// adminOnly documents the refusals of routes behind RequireScope.
+func adminOnly(responses map[string]Response) map[string]Response {
+	responses["401"] = textResponse("Missing or unknown bearer token")
+	responses["403"] = textResponse("Token lacks the admin scope")
+	return responses
+}
+
+func withErrors(responses map[string]Response, codes ...string) map[string]Response {
+	for _, code := range codes {
+		responses[code] = Response{Description: errorDescriptions[code], Content: map[string]MediaType{
+			"text/plain": {Schema: ref("Error")},
+		}}
+	}
+	return responses
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+func schemaOf(v interface{}) Schema {
+	return schemaOfType(reflect.TypeOf(v))
+}
+
+func schemaOfType(t reflect.Type) Schema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case t == rawType:
+		return Schema{"type": "object"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := Schema{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = schemaOfType(field.Type)
+			if strings.Contains(field.Tag.Get("validate"), "required") {
+				required = append(required, name)
+			}
+		}
+
+		schema := Schema{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	case reflect.Slice, reflect.Array:
+		return arrayOf(schemaOfType(t.Elem()))
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Float32:
+		return Schema{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return Schema{"type": "number", "format": "double"}
+	case reflect.Int64, reflect.Uint64:
+		return Schema{"type": "integer", "format": "int64"}
+	default:
+		return Schema{"type": "integer"}
+	}
+}
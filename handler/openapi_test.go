@@ -0,0 +1,79 @@
+// go:build unit
+package handler_test
+
+import (
+	"encoding/json"
+	"gotest/auth"
+	"gotest/handler"
+	"gotest/health"
+	"gotest/service"
+	"gotest/stream"
+	"gotest/webhook"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+// newRoutedApp mounts every route the server does.
+func newRoutedApp() *fiber.App {
+	serv := service.NewWalletServiceMock()
+	h := handler.Handlers{
+		Wallet:  handler.NewWalletHandler(serv),
+		Webhook: handler.NewWebhookHandler(webhook.NewMemoryStore()),
+		Stream:  handler.NewStreamHandler(serv, stream.NewHub(), auth.Grants{}),
+		Health:  health.NewChecker(),
+		Metrics: func(c *fiber.Ctx) error { return c.SendStatus(200) },
+	}
+	app := fiber.New()
+	handler.RegisterProbes(app, h)
+	handler.RegisterRoutes(app, h)
+	return app
+}
+
+func TestSpecCoversRoutes(t *testing.T) {
+	// arrange
+	app := newRoutedApp()
+	spec := handler.Spec()
+	// act
+	registered := map[string]bool{}
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == http.MethodHead {
+			continue
+		}
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+		method := strings.ToLower(route.Method)
+		registered[method+" "+path] = true
+		// assert
+		_, ok := spec.Paths[path][method]
+		assert.True(t, ok, "route %s %s is missing from the OpenAPI spec", route.Method, path)
+	}
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			assert.True(t, registered[method+" "+path], "spec documents %s %s, which is not registered", method, path)
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	// arrange
+	app := newRoutedApp()
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	// act
+	resp, _ := app.Test(req)
+	// assert
+	assert.Equal(t, 200, resp.StatusCode)
+	document := map[string]interface{}{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&document))
+	assert.Equal(t, "3.0.3", document["openapi"])
+
+	wallet := document["components"].(map[string]interface{})["schemas"].(map[string]interface{})["Wallet"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"name", "balance"}, wallet["required"])
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"gotest/audit"
+	"gotest/auth"
+	"gotest/health"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Handlers struct {
+	Wallet  walletHandler
+	Webhook webhookHandler
+	Stream  streamHandler
+	// Auth authenticates the tokens of admin routes; nil refuses them all.
+	Auth auth.Authenticator
+	// Health answers the probes mounted by RegisterProbes.
+	Health *health.Checker
+	// Metrics serves /metrics; nil leaves it unmounted.
+	Metrics fiber.Handler
+}
+
+// Version is one API generation mounted under "/<Name>". Deprecated versions
+// keep working but advertise their sunset date and successor on every
+// response.
+type Version struct {
+	Name       string
+	Deprecated bool
+	Sunset     time.Time
+	Successor  string
+	Register   func(router fiber.Router, h Handlers)
+}
+
+var Versions = []Version{
+	{Name: "v1", Register: registerV1},
+}
+
+// RegisterProbes mounts /healthz and /readyz. Mount them ahead of any
+// middleware so probes stay out of logs, metrics and traces.
+func RegisterProbes(router fiber.Router, h Handlers) {
+	health.Routes(router, h.Health)
+}
+
+func RegisterRoutes(router fiber.Router, h Handlers) {
+	router.Get("/openapi.json", OpenAPI)
+	if h.Metrics != nil {
+		router.Get("/metrics", h.Metrics)
+	}
+	MountVersions(router, h, Versions...)
+}
+
+func MountVersions(router fiber.Router, h Handlers, versions ...Version) {
+	for _, version := range versions {
+		group := router.Group("/"+version.Name, deprecation(version), origin)
+		version.Register(group, h)
+	}
+}
+
+func registerV1(router fiber.Router, h Handlers) {
+	admin := RequireScope(h.Auth, auth.ScopeAdmin)
+
+	router.Post("/wallets", h.Wallet.OpenAcount)
+	router.Get("/wallets", h.Wallet.ListAccounts)
+	router.Post("/wallets/batch", h.Wallet.Batch)
+	router.Post("/wallets/import", h.Wallet.ImportCSV)
+	router.Get("/wallets/export", h.Wallet.ExportCSV)
+	router.Get("/wallets/:id", h.Wallet.GetAccount)
+	router.Post("/wallets/:id/withdrawals", h.Wallet.Withdraw)
+	router.Post("/wallets/:id/deposits", h.Wallet.Deposit)
+	router.Put("/wallets/:id/status", admin, h.Wallet.SetStatus)
+	router.Get("/wallets/:id/transactions", h.Wallet.History)
+	router.Get("/wallets/:id/statements", h.Wallet.Statement)
+	router.Get("/wallets/:id/stream", h.Stream.StreamBalance)
+
+	router.Post("/webhooks", admin, h.Webhook.CreateSubscription)
+	router.Get("/webhooks", admin, h.Webhook.ListSubscriptions)
+	router.Get("/webhooks/:id", admin, h.Webhook.GetSubscription)
+	router.Delete("/webhooks/:id", admin, h.Webhook.DeleteSubscription)
+	router.Get("/webhooks/:id/deliveries", admin, h.Webhook.ListDeliveries)
+	router.Get("/webhooks/:id/dead-letters", admin, h.Webhook.ListDeadLetters)
+}
+
+// origin tells the audit log where a call came from. X-Actor is only the
+// name the client claims; the actor itself comes from the auth identity.
+func origin(c *fiber.Ctx) error {
+	c.SetUserContext(audit.WithOrigin(c.UserContext(), audit.Origin{
+		ClaimedActor: c.Get("X-Actor"),
+		ClientIP:     c.IP(),
+	}))
+	return c.Next()
+}
+
+func deprecation(version Version) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if version.Deprecated {
+			c.Set("Deprecation", "true")
+			if !version.Sunset.IsZero() {
+				c.Set("Sunset", version.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if version.Successor != "" {
+				c.Set(fiber.HeaderLink, `</`+version.Successor+`>; rel="successor-version"`)
+			}
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,108 @@
+// go:build unit
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"gotest/handler"
+	"gotest/repository"
+	"gotest/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMountVersions(t *testing.T) {
+	// arrange
+	var id uint64 = 1
+	wallet := repository.Wallet{ID: 1, Name: "John Doe", Balance: 1000}
+	serv := service.NewWalletServiceMock()
+	serv.On("GetAccount", id).Return(&wallet, nil)
+
+	v1 := handler.Versions[0]
+	v1.Deprecated = true
+	v1.Sunset = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	v1.Successor = "v2"
+	v2 := handler.Versions[0]
+	v2.Name = "v2"
+
+	app := fiber.New()
+	handler.MountVersions(app, handler.Handlers{Wallet: handler.NewWalletHandler(serv)}, v1, v2)
+
+	t.Run("Deprecated Version", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "true", resp.Header.Get("Deprecation"))
+		assert.Equal(t, "Tue, 01 Jan 2030 00:00:00 GMT", resp.Header.Get("Sunset"))
+		assert.Equal(t, `</v2>; rel="successor-version"`, resp.Header.Get("Link"))
+	})
+
+	t.Run("Current Version", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v2/wallets/1", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Deprecation"))
+	})
+}
+
+func TestJSONResponses(t *testing.T) {
+	t.Run("Open Account", func(t *testing.T) {
+		// arrange
+		wallet := repository.Wallet{Name: "John Doe", Balance: 1000}
+
+		var buff bytes.Buffer
+		if err := json.NewEncoder(&buff).Encode(wallet); err != nil {
+			t.Log(err)
+		}
+
+		serv := service.NewWalletServiceMock()
+		serv.On("OpenAccount", &wallet).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(0).(*repository.Wallet).ID = 7
+		})
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets", &buff)
+		req.Header.Set("Content-Type", "application/json")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 201, resp.StatusCode)
+		assert.Equal(t, "/v1/wallets/7", resp.Header.Get("Location"))
+		result := repository.Wallet{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		assert.Equal(t, uint64(7), result.ID)
+	})
+
+	t.Run("Withdraw", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		transaction := handler.TransactionRequest{Amount: 200}
+
+		var buff bytes.Buffer
+		if err := json.NewEncoder(&buff).Encode(transaction); err != nil {
+			t.Log(err)
+		}
+
+		serv := service.NewWalletServiceMock()
+		serv.On("Withdraw", id, transaction.Amount).Return(float32(800), nil)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets/1/withdrawals", &buff)
+		req.Header.Set("Content-Type", "application/json")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		result := handler.BalanceResponse{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		assert.Equal(t, handler.BalanceResponse{ID: 1, Balance: 800}, result)
+	})
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"fmt"
+	"gotest/service"
+	"gotest/statement"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const dateLayout = "2006-01-02"
+
+// Statement accepts from and to as RFC 3339 timestamps or dates; a date
+// passed as to includes that whole day. Without from the period starts at
+// the beginning of to's month, and without to it ends now.
+func (h walletHandler) Statement(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		e := service.NewErrorUnprocessableEntity()
+		return ResponseError(c, e)
+	}
+
+	to := time.Now().UTC()
+	from, _ := statement.MonthOf(to)
+	if raw := c.Query("to"); raw != "" {
+		var date bool
+		if to, date, err = queryPeriodBound(raw); err != nil {
+			return ResponseError(c, service.NewErrorUnprocessableEntity())
+		}
+		from, _ = statement.MonthOf(to)
+		if date {
+			to = to.AddDate(0, 0, 1)
+		}
+	}
+	if raw := c.Query("from"); raw != "" {
+		if from, _, err = queryPeriodBound(raw); err != nil {
+			return ResponseError(c, service.NewErrorUnprocessableEntity())
+		}
+	}
+	if !from.Before(to) {
+		e := service.NewErrorBadRequest("INVALID PERIOD")
+		return ResponseError(c, e)
+	}
+
+	format := c.Query("format", statement.FormatCSV)
+	if format != statement.FormatCSV && format != statement.FormatPDF {
+		e := service.NewErrorBadRequest("INVALID FORMAT")
+		return ResponseError(c, e)
+	}
+
+	result, err := statement.Generate(c.UserContext(), h.walletServ, uint64(id), from, to)
+	if err != nil {
+		return ResponseError(c, err)
+	}
+
+	filename := fmt.Sprintf("statement-%d-%s.%s", id, from.Format(dateLayout), format)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	if format == statement.FormatPDF {
+		c.Set(fiber.HeaderContentType, "application/pdf")
+		return statement.WritePDF(c, result)
+	}
+	c.Set(fiber.HeaderContentType, "text/csv")
+	return statement.WriteCSV(c, result)
+}
+
+// queryPeriodBound also reports whether raw was a date rather than a
+// timestamp.
+func queryPeriodBound(raw string) (time.Time, bool, error) {
+	if date, err := time.Parse(dateLayout, raw); err == nil {
+		return date, true, nil
+	}
+	bound, err := time.Parse(time.RFC3339, raw)
+	return bound, false, err
+}
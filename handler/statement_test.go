@@ -0,0 +1,111 @@
+// go:build unit
+package handler_test
+
+import (
+	"gotest/handler"
+	"gotest/repository"
+	"gotest/service"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatement(t *testing.T) {
+	september := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []service.Transaction{
+		{ID: 1, WalletID: 1, Type: "WalletOpened", Amount: 100, Balance: 100, OccurredAt: september.AddDate(0, -1, 0)},
+		{ID: 2, WalletID: 1, Type: "FundsDeposited", Amount: 50, Balance: 150, OccurredAt: september.AddDate(0, 0, 29).Add(23 * time.Hour)},
+	}
+
+	t.Run("CSV For Inclusive Dates", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("GetAccount", uint64(1)).Return(&repository.Wallet{ID: 1, Name: "John Doe"}, nil)
+		serv.On("History", uint64(1)).Return(transactions, nil)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1/statements?from=2026-09-01&to=2026-09-30", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "text/csv", resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), "statement-1-2026-09-01.csv")
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), "OPENING_BALANCE,,100.00")
+		assert.Contains(t, string(body), "CLOSING_BALANCE,,150.00")
+	})
+
+	t.Run("Default From At Month End", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("GetAccount", uint64(1)).Return(&repository.Wallet{ID: 1, Name: "John Doe"}, nil)
+		serv.On("History", uint64(1)).Return(transactions, nil)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1/statements?to=2026-09-30", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), "statement-1-2026-09-01.csv")
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), "CLOSING_BALANCE,,150.00")
+	})
+
+	t.Run("PDF", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("GetAccount", uint64(1)).Return(&repository.Wallet{ID: 1, Name: "John Doe"}, nil)
+		serv.On("History", uint64(1)).Return(transactions, nil)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1/statements?from=2026-09-01T00:00:00Z&to=2026-10-01T00:00:00Z&format=pdf", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(resp.Body)
+		assert.True(t, strings.HasPrefix(string(body), "%PDF-"))
+	})
+
+	t.Run("Bad Request Error", func(t *testing.T) {
+		for _, query := range []string{"from=2026-10-01&to=2026-09-01", "format=xml"} {
+			// arrange
+			serv := service.NewWalletServiceMock()
+			app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+			req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1/statements?"+query, nil)
+			// act
+			resp, _ := app.Test(req)
+			// assert
+			assert.Equal(t, 400, resp.StatusCode, query)
+			serv.AssertNotCalled(t, "History")
+		}
+	})
+
+	t.Run("Unprocessable Entity Error", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1/statements?from=yesterday", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 422, resp.StatusCode)
+	})
+
+	t.Run("Wallet Not Found", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("GetAccount", uint64(1)).Return(&repository.Wallet{}, service.NewErrorWalletNotFound())
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1/statements", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 404, resp.StatusCode)
+	})
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"gotest/auth"
+	"gotest/service"
+	"gotest/stream"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type streamHandler struct {
+	walletServ service.WalletService
+	hub        *stream.Hub
+	authorizer auth.Authorizer
+	heartbeat  time.Duration
+}
+
+func NewStreamHandler(walletServ service.WalletService, hub *stream.Hub, authorizer auth.Authorizer) streamHandler {
+	return streamHandler{
+		walletServ: walletServ,
+		hub:        hub,
+		authorizer: authorizer,
+		heartbeat:  15 * time.Second,
+	}
+}
+
+func (h streamHandler) WithHeartbeat(heartbeat time.Duration) streamHandler {
+	h.heartbeat = heartbeat
+	return h
+}
+
+// StreamBalance sends the current balance followed by every change as
+// Server-Sent Events. Browsers' EventSource cannot set headers, so the token
+// may also come from the access_token query parameter.
+func (h streamHandler) StreamBalance(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		e := service.NewErrorUnprocessableEntity()
+		return ResponseError(c, e)
+	}
+
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if token == "" {
+		token = c.Query("access_token")
+	}
+	if token == "" {
+		return c.Status(401).SendString("UNAUTHORIZED")
+	}
+	if !h.authorizer.CanReadWallet(token, uint64(id)) {
+		return c.Status(403).SendString("FORBIDDEN")
+	}
+
+	// Subscribing before reading the balance means no change can fall between
+	// the two; one that lands in both is sent twice, which is harmless.
+	sub := h.hub.Subscribe(uint64(id))
+	wallet, err := h.walletServ.GetAccount(c.UserContext(), uint64(id))
+	if err != nil {
+		sub.Close()
+		return ResponseError(c, err)
+	}
+
+	initial := stream.BalanceUpdate{WalletID: wallet.ID, Balance: wallet.Balance, OccurredAt: time.Now().UTC()}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+
+		if err := writeBalance(w, initial); err != nil {
+			return
+		}
+		for {
+			select {
+			case update, ok := <-sub.Updates():
+				if !ok {
+					return
+				}
+				if err := writeBalance(w, update); err != nil {
+					return
+				}
+			case <-ticker.C:
+				// A failed flush means the client went away.
+				fmt.Fprint(w, ": ping\n\n")
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+func writeBalance(w *bufio.Writer, update stream.BalanceUpdate) error {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	if update.EventID != 0 {
+		fmt.Fprintf(w, "id: %d\n", update.EventID)
+	}
+	fmt.Fprintf(w, "event: balance\ndata: %s\n\n", data)
+	return w.Flush()
+}
@@ -0,0 +1,127 @@
+// go:build unit
+package handler_test
+
+import (
+	"gotest/auth"
+	"gotest/event"
+	"gotest/handler"
+	"gotest/repository"
+	"gotest/service"
+	"gotest/stream"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newStreamApp(serv service.WalletService, hub *stream.Hub) *fiber.App {
+	grants := auth.Grants{"alice": {1}}
+	return newApp(handler.Handlers{
+		Stream: handler.NewStreamHandler(serv, hub, grants).WithHeartbeat(10 * time.Millisecond),
+	})
+}
+
+func TestStreamBalance(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		wallet := repository.Wallet{ID: 1, Name: "John Doe", Balance: 1000}
+
+		serv := service.NewWalletServiceMock()
+		serv.On("GetAccount", id).Return(&wallet, nil)
+		hub := stream.NewHub()
+		app := newStreamApp(serv, hub)
+
+		go func() {
+			for hub.Subscribers(id) == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			e, _ := event.Encode(event.FundsDeposited{WalletID: id, Amount: 200, Balance: 1200})
+			e.ID = 5
+			hub.Publish(e)
+			time.Sleep(30 * time.Millisecond)
+			hub.Close()
+		}()
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1/stream", nil)
+		req.Header.Set("Authorization", "Bearer alice")
+		// act
+		resp, _ := app.Test(req, -1)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), `"balance":1000`)
+		assert.Contains(t, string(body), "id: 5\nevent: balance\n")
+		assert.Contains(t, string(body), `"balance":1200`)
+		assert.Contains(t, string(body), ": ping\n\n")
+	})
+
+	t.Run("Change While Reading Balance", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		wallet := repository.Wallet{ID: 1, Name: "John Doe", Balance: 1000}
+		hub := stream.NewHub()
+		serv := service.NewWalletServiceMock()
+		serv.On("GetAccount", id).Return(&wallet, nil).Run(func(mock.Arguments) {
+			e, _ := event.Encode(event.FundsDeposited{WalletID: id, Amount: 200, Balance: 1200})
+			e.ID = 5
+			hub.Publish(e)
+			time.AfterFunc(30*time.Millisecond, hub.Close)
+		})
+		app := newStreamApp(serv, hub)
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1/stream", nil)
+		req.Header.Set("Authorization", "Bearer alice")
+		// act
+		resp, _ := app.Test(req, -1)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), `"balance":1200`)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		app := newStreamApp(serv, stream.NewHub())
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1/stream", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 401, resp.StatusCode)
+		serv.AssertNotCalled(t, "GetAccount")
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		app := newStreamApp(serv, stream.NewHub())
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/2/stream?access_token=alice", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 403, resp.StatusCode)
+		serv.AssertNotCalled(t, "GetAccount")
+	})
+
+	t.Run("Wallet Not Found", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		serv := service.NewWalletServiceMock()
+		serv.On("GetAccount", id).Return(&repository.Wallet{}, service.NewErrorWalletNotFound())
+		hub := stream.NewHub()
+		app := newStreamApp(serv, hub)
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1/stream", nil)
+		req.Header.Set("Authorization", "Bearer alice")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 404, resp.StatusCode)
+		assert.Equal(t, 0, hub.Subscribers(id))
+	})
+}
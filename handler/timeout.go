@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Timeout gives every request's user context a deadline d from now, which
+// the service and repository calls made with it inherit. fasthttp does not
+// report client disconnects, so this is what bounds abandoned requests.
+func Timeout(d time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), d)
+		defer cancel()
+
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+// go:build unit
+package handler_test
+
+import (
+	"context"
+	"gotest/handler"
+	"gotest/repository"
+	"gotest/service"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingRepository struct {
+	repository.WalletRepository
+}
+
+func (r blockingRepository) Get(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestTimeout(t *testing.T) {
+	// arrange
+	serv := service.NewWalletService(blockingRepository{repository.NewMemoryWalletRepository()})
+	app := fiber.New()
+	app.Use(handler.Timeout(20 * time.Millisecond))
+	handler.RegisterRoutes(app, handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+	start := time.Now()
+	// act
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/v1/wallets/1", nil))
+	// assert
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 504, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "TIMEOUT", string(body))
+}
@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"gotest/repository"
 	"gotest/service"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -16,13 +20,45 @@ func NewWalletHandler(walletServ service.WalletService) walletHandler {
 	return walletHandler{walletServ: walletServ}
 }
 
+// StatusClientClosedRequest reports a request abandoned by its client. The
+// client never sees it, but logs and metrics do.
+const StatusClientClosedRequest = 499
+
+var statusCodes = map[service.ErrorCode]int{
+	service.CodeNotFound:          fiber.StatusNotFound,
+	service.CodeInvalidRequest:    fiber.StatusBadRequest,
+	service.CodeInvalidParameter:  fiber.StatusUnprocessableEntity,
+	service.CodeInsufficientFunds: fiber.StatusBadRequest,
+	service.CodeConflict:          fiber.StatusConflict,
+	service.CodeTimeout:           fiber.StatusGatewayTimeout,
+	service.CodeCanceled:          StatusClientClosedRequest,
+	service.CodeLimitExceeded:     fiber.StatusBadRequest,
+	service.CodeWalletFrozen:      fiber.StatusLocked,
+	service.CodeUnavailable:       fiber.StatusServiceUnavailable,
+	service.CodeBusy:              fiber.StatusServiceUnavailable,
+}
+
+// StatusCode maps the WalletError in err's chain to an HTTP status; anything
+// else is a 500.
+func StatusCode(err error) int {
+	var e service.WalletError
+	if !errors.As(err, &e) {
+		return fiber.StatusInternalServerError
+	}
+
+	status, ok := statusCodes[e.Code]
+	if !ok {
+		return fiber.StatusInternalServerError
+	}
+	return status
+}
+
 func ResponseError(c *fiber.Ctx, err error) error {
-	switch v := err.(type) {
-	case service.WalletError:
-		return c.Status(v.Code).SendString(v.Message)
-	default:
-		return c.SendStatus(500)
+	var e service.WalletError
+	if !errors.As(err, &e) {
+		return c.SendStatus(fiber.StatusInternalServerError)
 	}
+	return c.Status(StatusCode(e)).SendString(e.Message)
 }
 
 func (h walletHandler) OpenAcount(c *fiber.Ctx) error {
@@ -32,14 +68,17 @@ func (h walletHandler) OpenAcount(c *fiber.Ctx) error {
 		return ResponseError(c, e)
 	}
 
-	err := h.walletServ.OpenAccount(&wallet)
+	err := h.walletServ.OpenAccount(c.UserContext(), &wallet)
 	if err != nil {
 		return ResponseError(c, err)
 	}
 
-	return c.SendStatus(201)
+	c.Location(fmt.Sprintf("%s/%d", c.Path(), wallet.ID))
+	return c.Status(201).JSON(wallet)
 }
 
+// GetAccount returns the wallet, or only its balance as of the RFC 3339
+// timestamp in at.
 func (h walletHandler) GetAccount(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil {
@@ -47,7 +86,11 @@ func (h walletHandler) GetAccount(c *fiber.Ctx) error {
 		return ResponseError(c, e)
 	}
 
-	wallet, err := h.walletServ.GetAccount(uint64(id))
+	if c.Query("at") != "" {
+		return h.getBalanceAt(c, uint64(id))
+	}
+
+	wallet, err := h.walletServ.GetAccount(c.UserContext(), uint64(id))
 	if err != nil {
 		return ResponseError(c, err)
 	}
@@ -55,10 +98,105 @@ func (h walletHandler) GetAccount(c *fiber.Ctx) error {
 	return c.Status(200).JSON(wallet)
 }
 
+type HistoricalBalance struct {
+	ID      uint64    `json:"id"`
+	Balance float32   `json:"balance"`
+	At      time.Time `json:"at"`
+}
+
+func (h walletHandler) getBalanceAt(c *fiber.Ctx, id uint64) error {
+	at, err := queryTime(c, "at")
+	if err != nil {
+		return ResponseError(c, service.NewErrorUnprocessableEntity())
+	}
+
+	balance, err := h.walletServ.GetBalanceAt(c.UserContext(), id, at)
+	if err != nil {
+		return ResponseError(c, err)
+	}
+
+	return c.Status(200).JSON(HistoricalBalance{ID: id, Balance: balance, At: at})
+}
+
+type WalletPage struct {
+	Items      []repository.Wallet `json:"items"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// ListAccounts accepts name, status, min_balance, max_balance,
+// created_after, created_before (RFC 3339), sort (a field, "-" prefix for
+// descending), cursor and limit.
+func (h walletHandler) ListAccounts(c *fiber.Ctx) error {
+	filter := repository.WalletFilter{
+		NamePrefix: c.Query("name"),
+		Status:     strings.ToUpper(c.Query("status")),
+	}
+
+	var err error
+	if filter.MinBalance, err = queryFloat(c, "min_balance"); err != nil {
+		return ResponseError(c, service.NewErrorUnprocessableEntity())
+	}
+	if filter.MaxBalance, err = queryFloat(c, "max_balance"); err != nil {
+		return ResponseError(c, service.NewErrorUnprocessableEntity())
+	}
+	if filter.CreatedAfter, err = queryTime(c, "created_after"); err != nil {
+		return ResponseError(c, service.NewErrorUnprocessableEntity())
+	}
+	if filter.CreatedBefore, err = queryTime(c, "created_before"); err != nil {
+		return ResponseError(c, service.NewErrorUnprocessableEntity())
+	}
+
+	sort := repository.WalletSort{Field: c.Query("sort")}
+	if strings.HasPrefix(sort.Field, "-") {
+		sort = repository.WalletSort{Field: sort.Field[1:], Desc: true}
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil {
+			return ResponseError(c, service.NewErrorUnprocessableEntity())
+		}
+	}
+
+	wallets, next, err := h.walletServ.ListAccounts(c.UserContext(), filter, sort, c.Query("cursor"), limit)
+	if err != nil {
+		return ResponseError(c, err)
+	}
+
+	return c.Status(200).JSON(WalletPage{Items: wallets, NextCursor: next})
+}
+
+func queryFloat(c *fiber.Ctx, key string) (*float32, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseFloat(raw, 32)
+	if err != nil {
+		return nil, err
+	}
+	result := float32(value)
+	return &result, nil
+}
+
+func queryTime(c *fiber.Ctx, key string) (time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
+
 type TransactionRequest struct {
 	Amount float32 `json:"amount" validate:"required,number"`
 }
 
+type BalanceResponse struct {
+	ID      uint64  `json:"id"`
+	Balance float32 `json:"balance"`
+}
+
 func (h walletHandler) Withdraw(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	if err != nil {
@@ -72,12 +210,12 @@ func (h walletHandler) Withdraw(c *fiber.Ctx) error {
 		return ResponseError(c, e)
 	}
 
-	change, err := h.walletServ.Withdraw(uint64(id), transaction.Amount)
+	change, err := h.walletServ.Withdraw(c.UserContext(), uint64(id), transaction.Amount)
 	if err != nil {
 		return ResponseError(c, err)
 	}
 
-	return c.Status(200).SendString(fmt.Sprintf("Balance: %v", change))
+	return c.Status(200).JSON(BalanceResponse{ID: uint64(id), Balance: change})
 }
 
 func (h walletHandler) Deposit(c *fiber.Ctx) error {
@@ -93,10 +231,89 @@ func (h walletHandler) Deposit(c *fiber.Ctx) error {
 		return ResponseError(c, e)
 	}
 
-	change, err := h.walletServ.Deposit(uint64(id), transaction.Amount)
+	change, err := h.walletServ.Deposit(c.UserContext(), uint64(id), transaction.Amount)
 	if err != nil {
 		return ResponseError(c, err)
 	}
 
-	return c.Status(200).SendString(fmt.Sprintf("Balance: %v", change))
+	return c.Status(200).JSON(BalanceResponse{ID: uint64(id), Balance: change})
+}
+
+type StatusRequest struct {
+	Status string `json:"status" validate:"required"`
+	Reason string `json:"reason"`
+}
+
+// SetStatus freezes or reactivates a wallet.
+func (h walletHandler) SetStatus(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		e := service.NewErrorUnprocessableEntity()
+		return ResponseError(c, e)
+	}
+
+	request := StatusRequest{}
+	if err := c.BodyParser(&request); err != nil {
+		e := service.NewErrorUnprocessableEntity()
+		return ResponseError(c, e)
+	}
+
+	wallet, _, err := h.walletServ.SetStatus(c.UserContext(), uint64(id), strings.ToUpper(request.Status), request.Reason)
+	if err != nil {
+		return ResponseError(c, err)
+	}
+
+	return c.Status(200).JSON(wallet)
+}
+
+func (h walletHandler) History(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		e := service.NewErrorUnprocessableEntity()
+		return ResponseError(c, e)
+	}
+
+	transactions, err := h.walletServ.History(c.UserContext(), uint64(id))
+	if err != nil {
+		return ResponseError(c, err)
+	}
+
+	return c.Status(200).JSON(transactions)
+}
+
+type BatchRequest struct {
+	Mode       string              `json:"mode" validate:"required"`
+	Operations []service.Operation `json:"operations" validate:"required"`
+}
+
+type BatchResponse struct {
+	Mode      string                    `json:"mode"`
+	Succeeded int                       `json:"succeeded"`
+	Failed    int                       `json:"failed"`
+	Results   []service.OperationResult `json:"results"`
+}
+
+func (h walletHandler) Batch(c *fiber.Ctx) error {
+	request := BatchRequest{}
+	if err := c.BodyParser(&request); err != nil {
+		e := service.NewErrorUnprocessableEntity()
+		return ResponseError(c, e)
+	}
+
+	results, err := h.walletServ.Batch(c.UserContext(), request.Operations, request.Mode)
+	if err != nil {
+		return ResponseError(c, err)
+	}
+
+	response := BatchResponse{Mode: request.Mode, Results: results}
+	for _, result := range results {
+		if result.Status != service.ResultOK {
+			response.Failed++
+			continue
+		}
+
+		response.Succeeded++
+	}
+
+	return c.Status(200).JSON(response)
 }
@@ -4,18 +4,37 @@ package handler_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"gotest/audit"
+	"gotest/auth"
 	"gotest/handler"
+	"gotest/logging"
 	"gotest/repository"
 	"gotest/service"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+var tokens = auth.Tokens{
+	"s3cret": {Name: "alice", Scopes: []string{auth.ScopeAdmin}},
+	"r34d":   {Name: "bob"},
+}
+
+func newApp(h handler.Handlers) *fiber.App {
+	app := fiber.New()
+	app.Use(logging.RequestIDMiddleware())
+	handler.RegisterRoutes(app, h)
+	return app
+}
+
 func TestOpenAccount(t *testing.T) {
 	t.Run("Successful", func(t *testing.T) {
 		// arrange
@@ -31,11 +50,8 @@ func TestOpenAccount(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("OpenAccount", &wallet).Return(nil)
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Post("/bank", handler.OpenAcount)
-		req := httptest.NewRequest(http.MethodPost, "/bank", &buff)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets", &buff)
 		req.Header.Set("Content-Type", "application/json")
 		// act
 		resp, _ := app.Test(req)
@@ -57,11 +73,8 @@ func TestOpenAccount(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("OpenAccount", &wallet).Return(nil)
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Post("/bank", handler.OpenAcount)
-		req := httptest.NewRequest(http.MethodPost, "/bank", &buff)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets", &buff)
 		req.Header.Set("Content-Type", "application/json")
 		// act
 		resp, _ := app.Test(req)
@@ -84,11 +97,8 @@ func TestOpenAccount(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("OpenAccount", &wallet).Return(service.NewErrorWalletUnexpected())
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Post("/bank", handler.OpenAcount)
-		req := httptest.NewRequest(http.MethodPost, "/bank", &buff)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets", &buff)
 		req.Header.Set("Content-Type", "application/json")
 		// act
 		resp, _ := app.Test(req)
@@ -108,11 +118,8 @@ func TestGetAccount(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("GetAccount", id).Return(&wallet, nil)
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Get("/bank/:id", handler.GetAccount)
-		url := fmt.Sprintf("/bank/%v", id)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		url := fmt.Sprintf("/v1/wallets/%v", id)
 		req := httptest.NewRequest(http.MethodGet, url, nil)
 		// act
 		resp, _ := app.Test(req)
@@ -130,11 +137,8 @@ func TestGetAccount(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("GetAccount", id).Return(&wallet, nil)
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Get("/bank/:id", handler.GetAccount)
-		url := fmt.Sprintf("/bank/%v", "error")
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		url := fmt.Sprintf("/v1/wallets/%v", "error")
 		req := httptest.NewRequest(http.MethodGet, url, nil)
 		// act
 		resp, _ := app.Test(req)
@@ -152,11 +156,8 @@ func TestGetAccount(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("GetAccount", id).Return(&wallet, service.NewErrorWalletNotFound())
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Get("/bank/:id", handler.GetAccount)
-		url := fmt.Sprintf("/bank/%v", id)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		url := fmt.Sprintf("/v1/wallets/%v", id)
 		req := httptest.NewRequest(http.MethodGet, url, nil)
 		// act
 		resp, _ := app.Test(req)
@@ -179,11 +180,8 @@ func TestWithdraw(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("Withdraw", id, transaction.Amount).Return(float32(800), nil)
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Post("/bank/withdraw/:id", handler.Withdraw)
-		url := fmt.Sprintf("/bank/withdraw/%v", id)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		url := fmt.Sprintf("/v1/wallets/%v/withdrawals", id)
 		req := httptest.NewRequest(http.MethodPost, url, &buff)
 		req.Header.Set("Content-Type", "application/json")
 		// act
@@ -205,11 +203,8 @@ func TestWithdraw(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("Withdraw", id, transaction.Amount).Return(float32(800), nil)
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Post("/bank/withdraw/:id", handler.Withdraw)
-		url := fmt.Sprintf("/bank/withdraw/%v", "error")
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		url := fmt.Sprintf("/v1/wallets/%v/withdrawals", "error")
 		req := httptest.NewRequest(http.MethodPost, url, &buff)
 		req.Header.Set("Content-Type", "application/json")
 		// act
@@ -232,11 +227,8 @@ func TestWithdraw(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("Withdraw", id, float32(200)).Return(float32(800), nil)
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Post("/bank/withdraw/:id", handler.Withdraw)
-		url := fmt.Sprintf("/bank/withdraw/%v", id)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		url := fmt.Sprintf("/v1/wallets/%v/withdrawals", id)
 		req := httptest.NewRequest(http.MethodPost, url, &buff)
 		req.Header.Set("Content-Type", "application/json")
 		// act
@@ -259,11 +251,8 @@ func TestWithdraw(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("Withdraw", id, transaction.Amount).Return(float32(0), service.NewErrorWalletUnexpected())
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Post("/bank/withdraw/:id", handler.Withdraw)
-		url := fmt.Sprintf("/bank/withdraw/%v", id)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		url := fmt.Sprintf("/v1/wallets/%v/withdrawals", id)
 		req := httptest.NewRequest(http.MethodPost, url, &buff)
 		req.Header.Set("Content-Type", "application/json")
 		// act
@@ -287,12 +276,9 @@ func TestDeposit(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("Deposit", id, transaction.Amount).Return(float32(1200), nil)
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Get("/bank/deposit/:id", handler.Deposit)
-		url := fmt.Sprintf("/bank/deposit/%v", id)
-		req := httptest.NewRequest(http.MethodGet, url, &buff)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		url := fmt.Sprintf("/v1/wallets/%v/deposits", id)
+		req := httptest.NewRequest(http.MethodPost, url, &buff)
 		req.Header.Set("Content-Type", "application/json")
 		// act
 		resp, _ := app.Test(req)
@@ -313,11 +299,8 @@ func TestDeposit(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("Deposit", id, transaction.Amount).Return(float32(1200), nil)
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Post("/bank/deposit/:id", handler.Deposit)
-		url := fmt.Sprintf("/bank/deposit/%v", "error")
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		url := fmt.Sprintf("/v1/wallets/%v/deposits", "error")
 		req := httptest.NewRequest(http.MethodPost, url, &buff)
 		req.Header.Set("Content-Type", "application/json")
 		// act
@@ -340,11 +323,8 @@ func TestDeposit(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("Deposit", id, float32(200)).Return(float32(1200), nil)
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Post("/bank/deposit/:id", handler.Deposit)
-		url := fmt.Sprintf("/bank/deposit/%v", id)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		url := fmt.Sprintf("/v1/wallets/%v/deposits", id)
 		req := httptest.NewRequest(http.MethodPost, url, &buff)
 		req.Header.Set("Content-Type", "application/json")
 		// act
@@ -367,11 +347,8 @@ func TestDeposit(t *testing.T) {
 
 		serv := service.NewWalletServiceMock()
 		serv.On("Deposit", id, transaction.Amount).Return(float32(0), service.NewErrorWalletUnexpected())
-		handler := handler.NewWalletHandler(serv)
-
-		app := fiber.New()
-		app.Post("/bank/deposit/:id", handler.Deposit)
-		url := fmt.Sprintf("/bank/deposit/%v", id)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		url := fmt.Sprintf("/v1/wallets/%v/deposits", id)
 		req := httptest.NewRequest(http.MethodPost, url, &buff)
 		req.Header.Set("Content-Type", "application/json")
 		// act
@@ -380,3 +357,401 @@ func TestDeposit(t *testing.T) {
 		assert.Equal(t, 500, resp.StatusCode)
 	})
 }
+
+func TestAuditLog(t *testing.T) {
+	t.Run("Record Withdraw", func(t *testing.T) {
+		var id uint64 = 1
+		transaction := handler.TransactionRequest{
+			Amount: 200,
+		}
+
+		var buff bytes.Buffer
+		if err := json.NewEncoder(&buff).Encode(transaction); err != nil {
+			t.Log(err)
+		}
+
+		serv := service.NewWalletServiceMock()
+		serv.On("Withdraw", id, transaction.Amount).Return(float32(800), nil)
+		auditLog := audit.NewLoggerMock()
+		auditLog.On("Record", mock.MatchedBy(func(e audit.Event) bool {
+			return e.Action == audit.ActionWithdraw &&
+				e.Actor == "anonymous" &&
+				e.ClaimedActor == "alice" &&
+				e.WalletID == id &&
+				e.BalanceBefore == 1000 &&
+				e.BalanceAfter == 800 &&
+				e.RequestID == "req-1"
+		})).Return(nil)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(audit.NewWalletService(serv, auditLog))})
+		url := fmt.Sprintf("/v1/wallets/%v/withdrawals", id)
+		req := httptest.NewRequest(http.MethodPost, url, &buff)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Actor", "alice")
+		req.Header.Set("X-Request-ID", "req-1")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("Skip Failed Operation", func(t *testing.T) {
+		var id uint64 = 1
+		transaction := handler.TransactionRequest{
+			Amount: 200,
+		}
+
+		var buff bytes.Buffer
+		if err := json.NewEncoder(&buff).Encode(transaction); err != nil {
+			t.Log(err)
+		}
+
+		serv := service.NewWalletServiceMock()
+		serv.On("Deposit", id, transaction.Amount).Return(float32(0), service.NewErrorWalletNotFound())
+		auditLog := audit.NewLoggerMock()
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(audit.NewWalletService(serv, auditLog))})
+		url := fmt.Sprintf("/v1/wallets/%v/deposits", id)
+		req := httptest.NewRequest(http.MethodPost, url, &buff)
+		req.Header.Set("Content-Type", "application/json")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 404, resp.StatusCode)
+		auditLog.AssertNotCalled(t, "Record", mock.Anything)
+	})
+}
+
+func TestSetStatus(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		var id uint64 = 1
+		wallet := repository.Wallet{ID: id, Name: "John Doe", Balance: 1000, Status: repository.StatusFrozen}
+		serv := service.NewWalletServiceMock()
+		serv.On("SetStatus", id, repository.StatusFrozen, "fraud check").Return(&wallet, true, nil)
+		auditLog := audit.NewLoggerMock()
+		auditLog.On("Record", mock.MatchedBy(func(e audit.Event) bool {
+			return e.Action == audit.ActionStatusChange &&
+				e.Actor == "alice" &&
+				e.ClaimedActor == "mallory" &&
+				e.WalletID == id &&
+				e.BalanceBefore == 1000 &&
+				e.BalanceAfter == 1000 &&
+				e.Detail == "FROZEN: fraud check"
+		})).Return(nil)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(audit.NewWalletService(serv, auditLog)), Auth: tokens})
+		body := bytes.NewBufferString(`{"status":"frozen","reason":"fraud check"}`)
+		req := httptest.NewRequest(http.MethodPut, "/v1/wallets/1/status", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer s3cret")
+		req.Header.Set("X-Actor", "mallory")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		result := repository.Wallet{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		assert.Equal(t, repository.StatusFrozen, result.Status)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("Same Status", func(t *testing.T) {
+		// arrange
+		wallet := repository.Wallet{ID: 1, Name: "John Doe", Balance: 1000, Status: repository.StatusActive}
+		serv := service.NewWalletServiceMock()
+		serv.On("SetStatus", uint64(1), repository.StatusActive, "").Return(&wallet, false, nil)
+		auditLog := audit.NewLoggerMock()
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(audit.NewWalletService(serv, auditLog)), Auth: tokens})
+		req := httptest.NewRequest(http.MethodPut, "/v1/wallets/1/status", bytes.NewBufferString(`{"status":"active"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer s3cret")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		auditLog.AssertNotCalled(t, "Record", mock.Anything)
+	})
+
+	t.Run("Invalid Status", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("SetStatus", uint64(1), "GONE", "").Return((*repository.Wallet)(nil), false, service.NewErrorBadRequest("INVALID STATUS"))
+		auditLog := audit.NewLoggerMock()
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(audit.NewWalletService(serv, auditLog)), Auth: tokens})
+		req := httptest.NewRequest(http.MethodPut, "/v1/wallets/1/status", bytes.NewBufferString(`{"status":"gone"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer s3cret")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 400, resp.StatusCode)
+		auditLog.AssertNotCalled(t, "Record", mock.Anything)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		for _, authorization := range []string{"", "Bearer wrong"} {
+			// arrange
+			serv := service.NewWalletServiceMock()
+			app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv), Auth: tokens})
+			req := httptest.NewRequest(http.MethodPut, "/v1/wallets/1/status", bytes.NewBufferString(`{"status":"frozen"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", authorization)
+			// act
+			resp, _ := app.Test(req)
+			// assert
+			assert.Equal(t, 401, resp.StatusCode)
+			serv.AssertNotCalled(t, "SetStatus", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("Without Admin Scope", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv), Auth: tokens})
+		req := httptest.NewRequest(http.MethodPut, "/v1/wallets/1/status", bytes.NewBufferString(`{"status":"frozen"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer r34d")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 403, resp.StatusCode)
+		serv.AssertNotCalled(t, "SetStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Frozen Wallet", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("Deposit", uint64(1), float32(10)).Return(float32(0), service.NewErrorWalletFrozen())
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets/1/deposits", bytes.NewBufferString(`{"amount":10}`))
+		req.Header.Set("Content-Type", "application/json")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 423, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "WALLET FROZEN", string(body))
+	})
+}
+
+func TestHistory(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		transactions := []service.Transaction{
+			{ID: 1, WalletID: 1, Type: "WalletOpened", Amount: 1000, Balance: 1000},
+			{ID: 2, WalletID: 1, Type: "FundsWithdrawn", Amount: -200, Balance: 800},
+		}
+		serv := service.NewWalletServiceMock()
+		serv.On("History", uint64(1)).Return(transactions, nil)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1/transactions", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		result := []service.Transaction{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		assert.Equal(t, transactions, result)
+	})
+
+	t.Run("Error Not Found", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("History", uint64(9)).Return([]service.Transaction{}, service.NewErrorWalletNotFound())
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/9/transactions", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 404, resp.StatusCode)
+	})
+}
+
+func TestGetBalanceAt(t *testing.T) {
+	at := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("GetBalanceAt", uint64(1), at).Return(float32(250), nil)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1?at=2026-09-01T12:00:00Z", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		result := handler.HistoricalBalance{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		assert.Equal(t, handler.HistoricalBalance{ID: 1, Balance: 250, At: at}, result)
+		serv.AssertNotCalled(t, "GetAccount", mock.Anything)
+	})
+
+	t.Run("Unprocessable Entity", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1?at=yesterday", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 422, resp.StatusCode)
+	})
+
+	t.Run("Wallet Not Found", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("GetBalanceAt", uint64(1), at).Return(float32(0), service.NewErrorWalletNotFound())
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1?at=2026-09-01T12:00:00Z", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 404, resp.StatusCode)
+	})
+}
+
+func TestListAccounts(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		min := float32(100)
+		filter := repository.WalletFilter{
+			NamePrefix:   "john",
+			Status:       repository.StatusActive,
+			MinBalance:   &min,
+			CreatedAfter: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		sort := repository.WalletSort{Field: "balance", Desc: true}
+		wallets := []repository.Wallet{{ID: 1, Name: "John Doe", Balance: 1000}}
+
+		serv := service.NewWalletServiceMock()
+		serv.On("ListAccounts", filter, sort, "abc", 5).Return(wallets, "def", nil)
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+
+		url := "/v1/wallets?name=john&status=active&min_balance=100&created_after=2024-01-01T00:00:00Z&sort=-balance&cursor=abc&limit=5"
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		page := handler.WalletPage{}
+		json.NewDecoder(resp.Body).Decode(&page)
+		assert.Equal(t, "def", page.NextCursor)
+		assert.Len(t, page.Items, 1)
+	})
+
+	t.Run("Unprocessable Entity", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets?created_after=yesterday", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 422, resp.StatusCode)
+		serv.AssertNotCalled(t, "ListAccounts")
+	})
+
+	t.Run("Bad Request", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("ListAccounts", repository.WalletFilter{}, repository.WalletSort{}, "bad", 0).
+			Return([]repository.Wallet{}, "", service.NewErrorBadRequest("INVALID CURSOR"))
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets?cursor=bad", nil)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+}
+
+func TestBatch(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		request := handler.BatchRequest{
+			Mode: service.BatchBestEffort,
+			Operations: []service.Operation{
+				{WalletID: 1, Type: service.OperationDeposit, Amount: 100},
+				{WalletID: 2, Type: service.OperationWithdraw, Amount: 100},
+			},
+		}
+
+		var buff bytes.Buffer
+		if err := json.NewEncoder(&buff).Encode(request); err != nil {
+			t.Log(err)
+		}
+
+		serv := service.NewWalletServiceMock()
+		serv.On("Batch", request.Operations, request.Mode).Return([]service.OperationResult{
+			{Index: 0, WalletID: 1, Status: service.ResultOK, Before: 0, Balance: 100},
+			{Index: 1, WalletID: 2, Status: service.ResultFailed, Code: service.CodeInsufficientFunds, Message: "NOT ENOUGH MONEY"},
+		}, nil)
+		auditLog := audit.NewLoggerMock()
+		auditLog.On("Record", mock.MatchedBy(func(e audit.Event) bool {
+			return e.Action == audit.ActionDeposit && e.WalletID == 1 && e.BalanceAfter == 100
+		})).Return(nil).Once()
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(audit.NewWalletService(serv, auditLog))})
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets/batch", &buff)
+		req.Header.Set("Content-Type", "application/json")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		result := handler.BatchResponse{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		assert.Equal(t, 1, result.Succeeded)
+		assert.Equal(t, 1, result.Failed)
+		auditLog.AssertExpectations(t)
+	})
+
+	t.Run("Bad Request", func(t *testing.T) {
+		// arrange
+		request := handler.BatchRequest{Mode: "sometimes"}
+
+		var buff bytes.Buffer
+		if err := json.NewEncoder(&buff).Encode(request); err != nil {
+			t.Log(err)
+		}
+
+		serv := service.NewWalletServiceMock()
+		serv.On("Batch", mock.Anything, "sometimes").Return([]service.OperationResult{}, service.NewErrorBadRequest("INVALID BATCH MODE"))
+		app := newApp(handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets/batch", &buff)
+		req.Header.Set("Content-Type", "application/json")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+}
+
+func TestResponseError(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		status int
+		body   string
+	}{
+		{service.ErrWalletNotFound, 404, "WALLET NOT FOUND"},
+		{service.ErrNotEnoughMoney, 400, "NOT ENOUGH MONEY"},
+		{service.NewErrorUnprocessableEntity(), 422, "INVALID PARAMETER"},
+		{service.ErrConflict.Wrap(repository.ErrVersionConflict), 409, "CONCURRENT UPDATE"},
+		{service.ErrWalletFrozen, 423, "WALLET FROZEN"},
+		{service.ErrUnavailable.Wrap(repository.ErrUnavailable), 503, "SERVICE UNAVAILABLE"},
+		{service.ErrBusy, 503, "TOO MANY PENDING OPERATIONS"},
+		{fmt.Errorf("withdraw: %w", service.ErrUnexpected.Wrap(errors.New("database down"))), 500, "UNEXPECTED ERROR"},
+		{errors.New("database down"), 500, "Internal Server Error"},
+	} {
+		t.Run(tc.body, func(t *testing.T) {
+			// arrange
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error { return handler.ResponseError(c, tc.err) })
+			// act
+			resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+			// assert
+			assert.Equal(t, tc.status, resp.StatusCode)
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, tc.body, string(body))
+		})
+	}
+}
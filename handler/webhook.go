@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"gotest/event"
+	"gotest/service"
+	"gotest/webhook"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type webhookHandler struct {
+	store webhook.Store
+}
+
+func NewWebhookHandler(store webhook.Store) webhookHandler {
+	return webhookHandler{store: store}
+}
+
+type SubscriptionRequest struct {
+	URL        string   `json:"url" validate:"required,url"`
+	EventTypes []string `json:"event_types"`
+}
+
+func (h webhookHandler) CreateSubscription(c *fiber.Ctx) error {
+	request := SubscriptionRequest{}
+	if err := c.BodyParser(&request); err != nil {
+		e := service.NewErrorUnprocessableEntity()
+		return ResponseError(c, e)
+	}
+
+	target, err := url.Parse(request.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		e := service.NewErrorBadRequest("INVALID URL")
+		return ResponseError(c, e)
+	}
+	if err := webhook.CheckHost(c.UserContext(), target.Hostname(), webhook.Public); err != nil {
+		e := service.NewErrorBadRequest("FORBIDDEN DESTINATION")
+		return ResponseError(c, e)
+	}
+	for _, eventType := range request.EventTypes {
+		if !slices.Contains(event.Types, eventType) {
+			e := service.NewErrorBadRequest("UNKNOWN EVENT TYPE")
+			return ResponseError(c, e)
+		}
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return ResponseError(c, err)
+	}
+
+	sub := webhook.Subscription{
+		URL:        request.URL,
+		Secret:     secret,
+		EventTypes: request.EventTypes,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := h.store.CreateSubscription(&sub); err != nil {
+		return ResponseError(c, err)
+	}
+
+	return c.Status(201).JSON(sub)
+}
+
+func (h webhookHandler) ListSubscriptions(c *fiber.Ctx) error {
+	subs, err := h.store.ListSubscriptions()
+	if err != nil {
+		return ResponseError(c, err)
+	}
+
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return c.Status(200).JSON(subs)
+}
+
+func (h webhookHandler) GetSubscription(c *fiber.Ctx) error {
+	sub, err := h.subscription(c)
+	if err != nil {
+		return ResponseError(c, err)
+	}
+
+	sub.Secret = ""
+	return c.Status(200).JSON(sub)
+}
+
+func (h webhookHandler) DeleteSubscription(c *fiber.Ctx) error {
+	sub, err := h.subscription(c)
+	if err != nil {
+		return ResponseError(c, err)
+	}
+
+	if err := h.store.DeleteSubscription(sub.ID); err != nil {
+		return ResponseError(c, err)
+	}
+	return c.SendStatus(204)
+}
+
+func (h webhookHandler) ListDeliveries(c *fiber.Ctx) error {
+	sub, err := h.subscription(c)
+	if err != nil {
+		return ResponseError(c, err)
+	}
+
+	deliveries, err := h.store.ListDeliveries(sub.ID)
+	if err != nil {
+		return ResponseError(c, err)
+	}
+	return c.Status(200).JSON(deliveries)
+}
+
+func (h webhookHandler) ListDeadLetters(c *fiber.Ctx) error {
+	sub, err := h.subscription(c)
+	if err != nil {
+		return ResponseError(c, err)
+	}
+
+	letters, err := h.store.ListDeadLetters(sub.ID)
+	if err != nil {
+		return ResponseError(c, err)
+	}
+	return c.Status(200).JSON(letters)
+}
+
+func (h webhookHandler) subscription(c *fiber.Ctx) (*webhook.Subscription, error) {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return nil, service.NewErrorUnprocessableEntity()
+	}
+
+	sub, err := h.store.GetSubscription(uint64(id))
+	if errors.Is(err, webhook.ErrSubscriptionNotFound) {
+		return nil, service.NewErrorNotFound("SUBSCRIPTION NOT FOUND")
+	}
+	return sub, err
+}
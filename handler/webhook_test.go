@@ -0,0 +1,182 @@
+// go:build unit
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gotest/handler"
+	"gotest/webhook"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWebhookApp(store webhook.Store) *fiber.App {
+	return newApp(handler.Handlers{Webhook: handler.NewWebhookHandler(store), Auth: tokens})
+}
+
+func TestCreateSubscription(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		request := handler.SubscriptionRequest{URL: "https://partner.example/hooks"}
+
+		var buff bytes.Buffer
+		if err := json.NewEncoder(&buff).Encode(request); err != nil {
+			t.Log(err)
+		}
+
+		store := webhook.NewMemoryStore()
+		app := newWebhookApp(store)
+		req := httptest.NewRequest(http.MethodPost, "/v1/webhooks", &buff)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		req.Header.Set("Content-Type", "application/json")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 201, resp.StatusCode)
+		sub := webhook.Subscription{}
+		json.NewDecoder(resp.Body).Decode(&sub)
+		assert.NotEmpty(t, sub.Secret)
+		subs, _ := store.ListSubscriptions()
+		assert.Len(t, subs, 1)
+	})
+
+	t.Run("Invalid URL", func(t *testing.T) {
+		// arrange
+		request := handler.SubscriptionRequest{URL: "ftp://partner.example"}
+
+		var buff bytes.Buffer
+		if err := json.NewEncoder(&buff).Encode(request); err != nil {
+			t.Log(err)
+		}
+
+		app := newWebhookApp(webhook.NewMemoryStore())
+		req := httptest.NewRequest(http.MethodPost, "/v1/webhooks", &buff)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		req.Header.Set("Content-Type", "application/json")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+
+	t.Run("Private Destination", func(t *testing.T) {
+		for _, target := range []string{"http://127.0.0.1:8000/hooks", "http://169.254.169.254/latest/meta-data", "http://10.0.0.5", "http://[::1]/hooks", "http://localhost/hooks"} {
+			// arrange
+			body := bytes.NewBufferString(fmt.Sprintf(`{"url":%q}`, target))
+			store := webhook.NewMemoryStore()
+			app := newWebhookApp(store)
+			req := httptest.NewRequest(http.MethodPost, "/v1/webhooks", body)
+			req.Header.Set("Authorization", "Bearer s3cret")
+			req.Header.Set("Content-Type", "application/json")
+			// act
+			resp, _ := app.Test(req)
+			// assert
+			assert.Equal(t, 400, resp.StatusCode, target)
+			subs, _ := store.ListSubscriptions()
+			assert.Empty(t, subs)
+		}
+	})
+}
+
+func TestCreateSubscriptionEventTypes(t *testing.T) {
+	for eventTypes, status := range map[string]int{`["FundsWithdrawn","StatusChanged"]`: 201, `["FundsWithdrawn","Withdrawn"]`: 400} {
+		// arrange
+		body := bytes.NewBufferString(fmt.Sprintf(`{"url":"https://partner.example/hooks","event_types":%s}`, eventTypes))
+		store := webhook.NewMemoryStore()
+		app := newWebhookApp(store)
+		req := httptest.NewRequest(http.MethodPost, "/v1/webhooks", body)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		req.Header.Set("Content-Type", "application/json")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, status, resp.StatusCode, eventTypes)
+	}
+}
+
+func TestWebhookAuth(t *testing.T) {
+	for authorization, status := range map[string]int{"": 401, "Bearer wrong": 401, "Bearer r34d": 403} {
+		// arrange
+		store := webhook.NewMemoryStore()
+		store.CreateSubscription(&webhook.Subscription{URL: "https://partner.example/hooks"})
+		app := newWebhookApp(store)
+		req := httptest.NewRequest(http.MethodGet, "/v1/webhooks", nil)
+		req.Header.Set("Authorization", authorization)
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, status, resp.StatusCode, authorization)
+	}
+}
+
+func TestGetSubscription(t *testing.T) {
+	t.Run("Secret Hidden", func(t *testing.T) {
+		// arrange
+		store := webhook.NewMemoryStore()
+		sub := webhook.Subscription{URL: "https://partner.example/hooks", Secret: "secret"}
+		store.CreateSubscription(&sub)
+
+		app := newWebhookApp(store)
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/webhooks/%v", sub.ID), nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		result := webhook.Subscription{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		assert.Empty(t, result.Secret)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// arrange
+		app := newWebhookApp(webhook.NewMemoryStore())
+		req := httptest.NewRequest(http.MethodGet, "/v1/webhooks/1", nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 404, resp.StatusCode)
+	})
+}
+
+func TestDeleteSubscription(t *testing.T) {
+	// arrange
+	store := webhook.NewMemoryStore()
+	sub := webhook.Subscription{URL: "https://partner.example/hooks"}
+	store.CreateSubscription(&sub)
+
+	app := newWebhookApp(store)
+	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/webhooks/%v", sub.ID), nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	// act
+	resp, _ := app.Test(req)
+	// assert
+	assert.Equal(t, 204, resp.StatusCode)
+	_, err := store.GetSubscription(sub.ID)
+	assert.ErrorIs(t, err, webhook.ErrSubscriptionNotFound)
+}
+
+func TestListDeliveries(t *testing.T) {
+	// arrange
+	store := webhook.NewMemoryStore()
+	sub := webhook.Subscription{URL: "https://partner.example/hooks"}
+	store.CreateSubscription(&sub)
+	store.AddDelivery(&webhook.Delivery{SubscriptionID: sub.ID, EventID: 1, Attempt: 1, StatusCode: 200, Succeeded: true})
+
+	app := newWebhookApp(store)
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/webhooks/%v/deliveries", sub.ID), nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	// act
+	resp, _ := app.Test(req)
+	// assert
+	assert.Equal(t, 200, resp.StatusCode)
+	deliveries := []webhook.Delivery{}
+	json.NewDecoder(resp.Body).Decode(&deliveries)
+	assert.Len(t, deliveries, 1)
+}
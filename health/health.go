@@ -0,0 +1,145 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+	StatusDraining = "draining"
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusTimeout  = "timeout"
+
+	DefaultTimeout = 2 * time.Second
+)
+
+// Check probes one dependency; a nil error means it is usable.
+type Check func(ctx context.Context) error
+
+// DependencyStatus only carries a fixed status: readiness is served without
+// authentication, so why a check failed is logged rather than reported.
+type DependencyStatus struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type Report struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyStatus `json:"dependencies,omitempty"`
+}
+
+// Checker answers liveness and readiness probes. Liveness only shows the
+// process is serving requests; readiness runs every check concurrently,
+// each bounded by Timeout, and fails once Drain is called.
+type Checker struct {
+	checks   map[string]Check
+	draining atomic.Bool
+	Timeout  time.Duration
+}
+
+func NewChecker() *Checker {
+	return &Checker{checks: map[string]Check{}, Timeout: DefaultTimeout}
+}
+
+// Register adds a named readiness check. It is not safe to call once the
+// checker is serving probes.
+func (h *Checker) Register(name string, check Check) *Checker {
+	h.checks[name] = check
+	return h
+}
+
+// Drain makes readiness fail from now on, so the load balancer stops sending
+// traffic while in-flight requests finish.
+func (h *Checker) Drain() {
+	h.draining.Store(true)
+}
+
+func (h *Checker) Draining() bool {
+	return h.draining.Load()
+}
+
+// Check runs every dependency check and reports StatusReady only if all of
+// them pass and the checker is not draining.
+func (h *Checker) Check(ctx context.Context) Report {
+	report := Report{Status: StatusReady, Dependencies: map[string]DependencyStatus{}}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range h.checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			dependency := h.run(ctx, name, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Dependencies[name] = dependency
+			if dependency.Status != StatusUp {
+				report.Status = StatusNotReady
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	if h.Draining() {
+		report.Status = StatusDraining
+	}
+	return report
+}
+
+// run waits for check at most Timeout; a check that ignores its context is
+// left running and reported as timed out.
+func (h *Checker) run(ctx context.Context, name string, check Check) DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	dependency := DependencyStatus{Status: StatusUp, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		dependency.Status = StatusDown
+		if errors.Is(err, context.DeadlineExceeded) {
+			dependency.Status = StatusTimeout
+		}
+		slog.WarnContext(ctx, "readiness check failed", slog.String("dependency", name), slog.Any("error", err))
+	}
+	return dependency
+}
+
+func (h *Checker) Liveness(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(Report{Status: StatusOK})
+}
+
+func (h *Checker) Readiness(c *fiber.Ctx) error {
+	report := h.Check(c.UserContext())
+	status := fiber.StatusOK
+	if report.Status != StatusReady {
+		status = fiber.StatusServiceUnavailable
+	}
+	return c.Status(status).JSON(report)
+}
+
+// Routes mounts /healthz and /readyz on router.
+func Routes(router fiber.Router, h *Checker) {
+	router.Get("/healthz", h.Liveness)
+	router.Get("/readyz", h.Readiness)
+}
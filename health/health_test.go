@@ -0,0 +1,99 @@
+// go:build unit
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gotest/health"
+	"gotest/repository"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newApp(checker *health.Checker) *fiber.App {
+	app := fiber.New()
+	health.Routes(app, checker)
+	return app
+}
+
+func readiness(t *testing.T, checker *health.Checker) (int, health.Report) {
+	resp, err := newApp(checker).Test(httptest.NewRequest(http.MethodGet, "/readyz", nil), -1)
+	assert.NoError(t, err)
+	report := health.Report{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	return resp.StatusCode, report
+}
+
+func TestLiveness(t *testing.T) {
+	// arrange
+	checker := health.NewChecker().Register("repository", func(ctx context.Context) error { return errors.New("down") })
+	// act
+	resp, _ := newApp(checker).Test(httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	// assert
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestReadiness(t *testing.T) {
+	t.Run("Ready", func(t *testing.T) {
+		// arrange
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Ping").Return(nil)
+		checker := health.NewChecker().Register("repository", repo.Ping)
+		// act
+		status, report := readiness(t, checker)
+		// assert
+		assert.Equal(t, 200, status)
+		assert.Equal(t, health.StatusReady, report.Status)
+		assert.Equal(t, health.StatusUp, report.Dependencies["repository"].Status)
+	})
+
+	t.Run("Dependency Down", func(t *testing.T) {
+		// arrange
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Ping").Return(errors.New("disk full"))
+		checker := health.NewChecker().
+			Register("repository", repo.Ping).
+			Register("webhooks", func(ctx context.Context) error { return nil })
+		// act
+		status, report := readiness(t, checker)
+		// assert
+		assert.Equal(t, 503, status)
+		assert.Equal(t, health.StatusNotReady, report.Status)
+		assert.Equal(t, health.StatusDown, report.Dependencies["repository"].Status)
+		assert.Equal(t, health.StatusUp, report.Dependencies["webhooks"].Status)
+	})
+
+	t.Run("Dependency Hangs", func(t *testing.T) {
+		// arrange
+		checker := health.NewChecker().Register("repository", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+		checker.Timeout = 20 * time.Millisecond
+		start := time.Now()
+		// act
+		status, report := readiness(t, checker)
+		// assert
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Equal(t, 503, status)
+		assert.Equal(t, health.StatusTimeout, report.Dependencies["repository"].Status)
+	})
+
+	t.Run("Draining", func(t *testing.T) {
+		// arrange
+		checker := health.NewChecker().Register("repository", func(ctx context.Context) error { return nil })
+		checker.Drain()
+		// act
+		status, report := readiness(t, checker)
+		// assert
+		assert.Equal(t, 503, status)
+		assert.Equal(t, health.StatusDraining, report.Status)
+		assert.Equal(t, health.StatusUp, report.Dependencies["repository"].Status)
+	})
+}
@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+const Redacted = "[REDACTED]"
+
+// SensitiveKeys are attribute keys whose values never reach the output,
+// compared case-insensitively and at any group depth.
+var SensitiveKeys = []string{
+	"access_token",
+	"api_key",
+	"authorization",
+	"cookie",
+	"password",
+	"secret",
+	"token",
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID set by WithRequestID, or "" outside a request.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// New returns a JSON logger that redacts SensitiveKeys and tags every record
+// logged with a context with that context's request ID and trace IDs.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(NewHandler(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: Redact,
+	})))
+}
+
+// Redact is a slog.HandlerOptions.ReplaceAttr that hides SensitiveKeys.
+func Redact(groups []string, a slog.Attr) slog.Attr {
+	for _, key := range SensitiveKeys {
+		if strings.EqualFold(a.Key, key) {
+			return slog.String(a.Key, Redacted)
+		}
+	}
+	return a
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+// NewHandler adds request_id, trace_id and span_id from the record's context
+// to next.
+func NewHandler(next slog.Handler) slog.Handler {
+	return contextHandler{Handler: next}
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,146 @@
+// go:build unit
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"gotest/logging"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func decode(logs *bytes.Buffer) []map[string]any {
+	records := []map[string]any{}
+	decoder := json.NewDecoder(logs)
+	for decoder.More() {
+		record := map[string]any{}
+		decoder.Decode(&record)
+		records = append(records, record)
+	}
+	return records
+}
+
+func newApp(logger *slog.Logger) *fiber.App {
+	app := fiber.New()
+	app.Use(logging.RequestIDMiddleware())
+	app.Use(logging.Middleware(logger))
+	app.Get("/v1/wallets/:id", func(c *fiber.Ctx) error {
+		logger.InfoContext(c.UserContext(), "inside handler")
+		return c.SendStatus(200)
+	})
+	app.Get("/v1/broken", func(c *fiber.Ctx) error { return c.SendStatus(500) })
+	return app
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	t.Run("Generated", func(t *testing.T) {
+		// arrange
+		var logs bytes.Buffer
+		app := newApp(logging.New(&logs, slog.LevelInfo))
+		// act
+		resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/v1/wallets/1?access_token=abc", nil))
+		// assert
+		id := resp.Header.Get(logging.RequestIDHeader)
+		assert.Len(t, id, 32)
+		records := decode(&logs)
+		assert.Len(t, records, 2)
+		assert.Equal(t, "inside handler", records[0]["msg"])
+		assert.Equal(t, id, records[0]["request_id"])
+		assert.Equal(t, "request", records[1]["msg"])
+		assert.Equal(t, id, records[1]["request_id"])
+		assert.Equal(t, "/v1/wallets/:id", records[1]["route"])
+		assert.Equal(t, "/v1/wallets/1", records[1]["path"])
+		assert.Equal(t, float64(200), records[1]["status"])
+		assert.NotContains(t, logs.String(), "abc")
+	})
+
+	t.Run("From Client", func(t *testing.T) {
+		// arrange
+		var logs bytes.Buffer
+		app := newApp(logging.New(&logs, slog.LevelInfo))
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1", nil)
+		req.Header.Set(logging.RequestIDHeader, "abc-123")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, "abc-123", resp.Header.Get(logging.RequestIDHeader))
+		assert.Equal(t, "abc-123", decode(&logs)[1]["request_id"])
+	})
+
+	t.Run("Malformed From Client", func(t *testing.T) {
+		// arrange
+		var logs bytes.Buffer
+		app := newApp(logging.New(&logs, slog.LevelInfo))
+		req := httptest.NewRequest(http.MethodGet, "/v1/wallets/1", nil)
+		req.Header.Set(logging.RequestIDHeader, "bad id\"}")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Len(t, resp.Header.Get(logging.RequestIDHeader), 32)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	// arrange
+	var logs bytes.Buffer
+	app := newApp(logging.New(&logs, slog.LevelInfo))
+	// act
+	app.Test(httptest.NewRequest(http.MethodGet, "/v1/broken", nil))
+	// assert
+	records := decode(&logs)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "ERROR", records[0]["level"])
+	assert.Equal(t, float64(500), records[0]["status"])
+}
+
+func TestRedact(t *testing.T) {
+	// arrange
+	var logs bytes.Buffer
+	logger := logging.New(&logs, slog.LevelInfo)
+	// act
+	logger.Info("subscription",
+		slog.String("Authorization", "Bearer abc"),
+		slog.Group("webhook", slog.String("url", "https://example.com"), slog.String("secret", "s3cr3t")),
+	)
+	// assert
+	records := decode(&logs)
+	assert.Equal(t, logging.Redacted, records[0]["Authorization"])
+	assert.Equal(t, map[string]any{"url": "https://example.com", "secret": logging.Redacted}, records[0]["webhook"])
+	assert.NotContains(t, logs.String(), "s3cr3t")
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("Trace IDs", func(t *testing.T) {
+		// arrange
+		var logs bytes.Buffer
+		logger := logging.New(&logs, slog.LevelInfo)
+		ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "span")
+		defer span.End()
+		// act
+		logger.InfoContext(logging.WithRequestID(ctx, "abc-123"), "traced")
+		// assert
+		record := decode(&logs)[0]
+		assert.Equal(t, "abc-123", record["request_id"])
+		assert.Equal(t, span.SpanContext().TraceID().String(), record["trace_id"])
+		assert.Equal(t, span.SpanContext().SpanID().String(), record["span_id"])
+	})
+
+	t.Run("With Attrs", func(t *testing.T) {
+		// arrange
+		var logs bytes.Buffer
+		logger := logging.New(&logs, slog.LevelInfo).With(slog.String("component", "relay"))
+		// act
+		logger.InfoContext(logging.WithRequestID(context.Background(), "abc-123"), "flushed")
+		// assert
+		record := decode(&logs)[0]
+		assert.Equal(t, "relay", record["component"])
+		assert.Equal(t, "abc-123", record["request_id"])
+	})
+}
@@ -1 +1,443 @@
 package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"gotest/actor"
+	"gotest/audit"
+	"gotest/auth"
+	"gotest/cache"
+	"gotest/config"
+	"gotest/event"
+	"gotest/eventstore"
+	"gotest/grpcapi"
+	"gotest/grpcapi/walletpb"
+	"gotest/handler"
+	"gotest/health"
+	"gotest/logging"
+	"gotest/metrics"
+	"gotest/reconcile"
+	"gotest/resilience"
+	"gotest/service"
+	"gotest/stream"
+	"gotest/tracing"
+	"gotest/walletcsv"
+	"gotest/webhook"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "serve":
+		err = serve(os.Args[2:])
+	case "audit-verify":
+		err = auditVerify(os.Args[2:])
+	case "import":
+		err = importCSV(os.Args[2:])
+	case "export":
+		err = exportCSV(os.Args[2:])
+	case "rebuild-projections":
+		err = rebuildProjections(os.Args[2:])
+	case "reconcile":
+		err = reconcileWallets(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gotest serve [-config file.yaml] [-store memory|events] [-db file] [-addr :8000] [-grpc-addr :9000] [-audit-log file] [-stream-tokens grants] [-tokens tokens] [-trace-stdout] [-log-level info] [-request-timeout 30s] [-drain-delay 5s]")
+	fmt.Fprintln(os.Stderr, "       gotest audit-verify <file>")
+	fmt.Fprintln(os.Stderr, "       gotest import [-store memory|events] -db file [-audit-log file] [-actor name] <wallets.csv>")
+	fmt.Fprintln(os.Stderr, "       gotest export [-store memory|events] -db file [out.csv]")
+	fmt.Fprintln(os.Stderr, "       gotest rebuild-projections -db file")
+	fmt.Fprintln(os.Stderr, "       gotest reconcile [-store memory|events] -db file [-fix] [-audit-log file] [-actor name]")
+	os.Exit(2)
+}
+
+func serve(args []string) error {
+	cfg, err := config.Load("serve", args, os.Environ())
+	if err != nil {
+		return err
+	}
+	live := config.NewLive(cfg)
+
+	level := &slog.LevelVar{}
+	level.Set(cfg.LogLevel())
+	logger := logging.New(os.Stderr, level)
+	slog.SetDefault(logger)
+
+	grants, err := auth.ParseGrants(cfg.Auth.StreamTokens)
+	if err != nil {
+		return err
+	}
+	tokens, err := auth.ParseTokens(cfg.Auth.Tokens)
+	if err != nil {
+		return err
+	}
+
+	auditLog, err := audit.NewFileSink(cfg.Audit.Path)
+	if err != nil {
+		return err
+	}
+	defer auditLog.Close()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	walletMetrics := metrics.New(registry)
+
+	tracerProvider, err := newTracerProvider(cfg.Features.TraceStdout)
+	if err != nil {
+		return err
+	}
+	defer tracerProvider.Shutdown(context.Background())
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	storage, err := cfg.Storage.Open()
+	if err != nil {
+		return err
+	}
+	resilient := resilience.NewWalletRepository(storage,
+		resilience.WithAttempts(cfg.Resilience.Attempts),
+		resilience.WithBackoff(cfg.Resilience.BaseDelay, cfg.Resilience.MaxDelay),
+		resilience.WithBreaker(cfg.Resilience.FailureThreshold, cfg.Resilience.Cooldown),
+		resilience.WithRetryHook(func(operation string, err error) {
+			walletMetrics.RepositoryRetries.WithLabelValues(operation).Inc()
+		}),
+		resilience.WithStateHook(func(from, to resilience.State) {
+			walletMetrics.CircuitState.Set(float64(to))
+			logger.Warn("repository circuit breaker changed", slog.String("from", from.String()), slog.String("to", to.String()))
+		}),
+	)
+	walletRepo := metrics.NewWalletRepository(resilient, walletMetrics)
+	servRepo := walletRepo
+	if cfg.Cache.TTL > 0 {
+		servRepo = cache.NewWalletRepository(walletRepo, cache.WithTTL(cfg.Cache.TTL), cache.WithSize(cfg.Cache.Size))
+	}
+	walletServ := service.NewWalletService(tracing.NewWalletRepository(servRepo, tracerProvider),
+		service.WithLogger(logger),
+		service.WithTimeouts(cfg.Timeouts()),
+		service.WithBatchConcurrency(cfg.Service.BatchConcurrency),
+		service.WithPolicy(func() service.Policy { return live.Get().Policy() }),
+	)
+	// Auditing inside the actors keeps each wallet's entries in the order
+	// its changes were made.
+	walletServ = audit.NewWalletService(walletServ, auditLog, audit.WithFailureHook(func(ctx context.Context, event audit.Event, err error) {
+		walletMetrics.AuditFailures.Inc()
+		logger.ErrorContext(ctx, "audit record lost", slog.String("action", event.Action), slog.Uint64("wallet_id", event.WalletID), slog.Any("error", err))
+	}))
+	drainActors := func(context.Context) error { return nil }
+	if cfg.Actors.Enabled {
+		actors := actor.NewWalletService(walletServ, actor.WithShards(cfg.Actors.Shards), actor.WithQueueSize(cfg.Actors.QueueSize))
+		walletServ, drainActors = actors, actors.Close
+	}
+	walletServ = metrics.NewWalletService(walletServ, walletMetrics)
+	walletServ = tracing.NewWalletService(walletServ, tracerProvider)
+	walletHandler := handler.NewWalletHandler(walletServ)
+	webhookStore := webhook.NewMemoryStore()
+	webhookHandler := handler.NewWebhookHandler(webhookStore)
+	hub := stream.NewHub()
+	streamHandler := handler.NewStreamHandler(walletServ, hub, grants)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go reloadOnHangup(ctx, live, args, level, logger)
+
+	publishers := []event.Publisher{event.NewLogPublisher(os.Stdout), hub}
+	drainWebhooks := func(context.Context) error { return nil }
+	if cfg.Features.Webhooks {
+		dispatcher := webhook.NewDispatcher(webhookStore)
+		publishers = append(publishers, dispatcher)
+		drainWebhooks = dispatcher.Close
+	}
+	relay := event.NewRelay(walletRepo, event.NewMultiPublisher(publishers...))
+	relay.Interval = 200 * time.Millisecond
+	relay.Logger = logger
+	go relay.Run(ctx)
+
+	// The repository probe skips retries and the breaker, so it reports
+	// storage as it is; the breaker has a check of its own.
+	checker := health.NewChecker().
+		Register("repository", storage.Ping).
+		Register("circuit_breaker", resilient.Check)
+
+	handlers := handler.Handlers{
+		Wallet:  walletHandler,
+		Webhook: webhookHandler,
+		Stream:  streamHandler,
+		Auth:    tokens,
+		Health:  checker,
+		Metrics: metrics.Handler(registry),
+	}
+
+	app := fiber.New()
+	app.Use(recover.New())
+	handler.RegisterProbes(app, handlers)
+	app.Use(logging.RequestIDMiddleware())
+	app.Use(metrics.Middleware(walletMetrics))
+	app.Use(tracing.Middleware(tracerProvider, otel.GetTextMapPropagator()))
+	app.Use(logging.Middleware(logger))
+	app.Use(handler.Timeout(cfg.Server.RequestTimeout))
+	handler.RegisterRoutes(app, handlers)
+
+	listener, err := net.Listen("tcp", cfg.Server.GRPCAddr)
+	if err != nil {
+		return err
+	}
+	grpcServer := grpc.NewServer()
+	walletpb.RegisterWalletServiceServer(grpcServer, grpcapi.NewWalletServer(walletServ))
+	// A gRPC server that stops on its own takes HTTP down with it, so the
+	// process exits instead of serving half its API.
+	grpcErr := make(chan error, 1)
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			logger.Error("grpc server stopped", slog.Any("error", err))
+			grpcErr <- err
+			stop()
+		}
+	}()
+
+	stopped := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		checker.Drain()
+		time.Sleep(cfg.Server.DrainDelay)
+		hub.Close()
+		grpcServer.GracefulStop()
+		app.Shutdown()
+		close(stopped)
+	}()
+
+	err = app.Listen(cfg.Server.Addr)
+
+	// Listen returns as soon as shutdown begins, or at once when it cannot
+	// start. Wait until HTTP and gRPC have finished their requests, then
+	// run what they left queued on the wallet actors before exiting.
+	stop()
+	<-stopped
+	drainCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.RequestTimeout)
+	defer cancel()
+	if drainErr := drainActors(drainCtx); drainErr != nil {
+		logger.Warn("wallet actors not drained", slog.Any("error", drainErr))
+	}
+	if drainErr := drainWebhooks(drainCtx); drainErr != nil {
+		logger.Warn("webhook deliveries not drained", slog.Any("error", drainErr))
+	}
+	select {
+	case serveErr := <-grpcErr:
+		return errors.Join(err, serveErr)
+	default:
+	}
+	return err
+}
+
+// reloadOnHangup reloads the configuration on every SIGHUP. An invalid
+// configuration is logged and ignored; a valid one updates the safe settings
+// and logs the changed settings that need a restart.
+func reloadOnHangup(ctx context.Context, live *config.Live, args []string, level *slog.LevelVar, logger *slog.Logger) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+		}
+
+		next, err := config.Load("serve", args, os.Environ())
+		if err != nil {
+			logger.Error("config reload rejected", slog.Any("error", err))
+			continue
+		}
+
+		ignored := live.Reload(next)
+		level.Set(live.Get().LogLevel())
+		if len(ignored) > 0 {
+			logger.Warn("config changes need a restart", slog.Any("settings", ignored))
+		}
+		logger.Info("config reloaded")
+	}
+}
+
+func auditVerify(args []string) error {
+	if len(args) != 1 {
+		usage()
+	}
+
+	last, err := audit.VerifyFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("OK: %d entries, head %s\n", last.Seq, last.Hash)
+	return nil
+}
+
+func newTracerProvider(stdout bool) (*sdktrace.TracerProvider, error) {
+	if !stdout {
+		return sdktrace.NewTracerProvider(), nil
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter)), nil
+}
+
+func importCSV(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	store := flags.String("store", config.StorageMemory, "wallet storage: memory or events")
+	dbPath := flags.String("db", "wallets.json", "wallet data file")
+	auditPath := flags.String("audit-log", "audit.log", "audit log file")
+	actor := flags.String("actor", "importer", "actor recorded for opened wallets")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+	}
+
+	walletRepo, err := config.Storage{Driver: *store, DSN: *dbPath}.Open()
+	if err != nil {
+		return err
+	}
+
+	auditLog, err := audit.NewFileSink(*auditPath)
+	if err != nil {
+		return err
+	}
+	defer auditLog.Close()
+	walletServ := audit.NewWalletService(service.NewWalletService(walletRepo), auditLog)
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ctx := auth.WithIdentity(context.Background(), auth.Identity{Name: *actor})
+	report, err := walletcsv.Import(ctx, file, walletServ)
+	if err != nil {
+		return err
+	}
+
+	for _, rowErr := range report.Errors {
+		fmt.Fprintf(os.Stderr, "line %d: %s %s\n", rowErr.Line, rowErr.Field, rowErr.Message)
+	}
+	fmt.Printf("imported %d, failed %d\n", report.Imported, report.Failed)
+	return nil
+}
+
+func exportCSV(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	store := flags.String("store", config.StorageMemory, "wallet storage: memory or events")
+	dbPath := flags.String("db", "wallets.json", "wallet data file")
+	flags.Parse(args)
+
+	walletRepo, err := config.Storage{Driver: *store, DSN: *dbPath}.Open()
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if flags.NArg() == 1 {
+		if out, err = os.Create(flags.Arg(0)); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+
+	return walletcsv.Export(context.Background(), out, service.NewWalletService(walletRepo))
+}
+
+func rebuildProjections(args []string) error {
+	flags := flag.NewFlagSet("rebuild-projections", flag.ExitOnError)
+	dbPath := flags.String("db", "wallets.json", "event store file")
+	flags.Parse(args)
+
+	store, err := eventstore.NewFileRepository(*dbPath)
+	if err != nil {
+		return err
+	}
+
+	wallets, err := store.RebuildProjections()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("rebuilt %d wallets\n", wallets)
+	return nil
+}
+
+// reconcileWallets prints the report as JSON and fails when wallets are left
+// out of balance, so it can run unattended.
+func reconcileWallets(args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	store := flags.String("store", config.StorageMemory, "wallet storage: memory or events")
+	dbPath := flags.String("db", "wallets.json", "wallet data file")
+	fix := flags.Bool("fix", false, "adjust drifted balances to the ledger total")
+	auditPath := flags.String("audit-log", "audit.log", "audit log file")
+	actor := flags.String("actor", "reconciler", "actor recorded for adjustments")
+	flags.Parse(args)
+
+	walletRepo, err := config.Storage{Driver: *store, DSN: *dbPath}.Open()
+	if err != nil {
+		return err
+	}
+
+	auditLog, err := audit.NewFileSink(*auditPath)
+	if err != nil {
+		return err
+	}
+	defer auditLog.Close()
+
+	reconciler := reconcile.NewReconciler(service.NewWalletService(walletRepo), auditLog)
+	reconciler.Fix = *fix
+	reconciler.Actor = *actor
+
+	report, err := reconciler.Run(context.Background())
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	if unresolved := report.Unresolved(); unresolved > 0 {
+		return fmt.Errorf("%d wallets out of balance", unresolved)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// Metrics holds every collector the API exports. Decorators and middleware
+// share one instance so that all series land in the same registry.
+type Metrics struct {
+	Requests        *prometheus.CounterVec
+	RequestDuration *prometheus.HistogramVec
+
+	Deposits          prometheus.Counter
+	Withdrawals       prometheus.Counter
+	Transfers         prometheus.Counter
+	InsufficientFunds prometheus.Counter
+	AmountMoved       *prometheus.CounterVec
+	AuditFailures     prometheus.Counter
+
+	RepositoryDuration *prometheus.HistogramVec
+	RepositoryErrors   *prometheus.CounterVec
+	RepositoryRetries  *prometheus.CounterVec
+	CircuitState       prometheus.Gauge
+}
+
+func New(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		Requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		RequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+
+		Deposits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "wallet_deposits_total",
+			Help: "Successful deposits, including batch operations.",
+		}),
+		Withdrawals: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "wallet_withdrawals_total",
+			Help: "Successful withdrawals, including batch operations.",
+		}),
+		Transfers: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "wallet_transfers_total",
+			Help: "Successful transfers between wallets.",
+		}),
+		InsufficientFunds: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "wallet_insufficient_funds_total",
+			Help: "Withdrawals and transfers rejected for lack of money.",
+		}),
+		AmountMoved: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "wallet_amount_moved_total",
+			Help: "Sum of amounts deposited, withdrawn and transferred.",
+		}, []string{"operation"}),
+		AuditFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "wallet_audit_failures_total",
+			Help: "Committed changes whose audit record could not be written.",
+		}),
+
+		RepositoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "wallet_repository_operation_duration_seconds",
+			Help:    "Repository call latency by operation.",
+			Buckets: prometheus.ExponentialBuckets(0.00005, 4, 10),
+		}, []string{"operation"}),
+		RepositoryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "wallet_repository_errors_total",
+			Help: "Repository calls that returned an error, by operation.",
+		}, []string{"operation"}),
+		RepositoryRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "wallet_repository_retries_total",
+			Help: "Repository calls retried after a transient error, by operation.",
+		}, []string{"operation"}),
+		CircuitState: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "wallet_repository_circuit_state",
+			Help: "Repository circuit breaker: 0 closed, 1 half-open, 2 open.",
+		}),
+	}
+
+	registerer.MustRegister(
+		m.Requests, m.RequestDuration,
+		m.Deposits, m.Withdrawals, m.Transfers, m.InsufficientFunds, m.AmountMoved, m.AuditFailures,
+		m.RepositoryDuration, m.RepositoryErrors, m.RepositoryRetries, m.CircuitState,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus text format.
+func Handler(gatherer prometheus.Gatherer) fiber.Handler {
+	handler := fasthttpadaptor.NewFastHTTPHandler(promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	return func(c *fiber.Ctx) error {
+		handler(c.Context())
+		return nil
+	}
+}
@@ -0,0 +1,166 @@
+// go:build unit
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"gotest/handler"
+	"gotest/metrics"
+	"gotest/repository"
+	"gotest/service"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	// arrange
+	m := metrics.New(prometheus.NewRegistry())
+	app := fiber.New()
+	app.Use(metrics.Middleware(m))
+	app.Get("/v1/wallets/:id", func(c *fiber.Ctx) error { return c.SendStatus(200) })
+	app.Post("/v1/wallets/:id/deposits", func(c *fiber.Ctx) error { return fiber.ErrUnprocessableEntity })
+	// act
+	app.Test(httptest.NewRequest(http.MethodGet, "/v1/wallets/1", nil))
+	app.Test(httptest.NewRequest(http.MethodGet, "/v1/wallets/2", nil))
+	app.Test(httptest.NewRequest(http.MethodPost, "/v1/wallets/1/deposits", nil))
+	app.Test(httptest.NewRequest(http.MethodGet, "/nope", nil))
+	// assert
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.Requests.WithLabelValues("GET", "/v1/wallets/:id", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.Requests.WithLabelValues("POST", "/v1/wallets/:id/deposits", "422")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.Requests.WithLabelValues("GET", "unmatched", "404")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.RequestDuration))
+}
+
+func TestWalletService(t *testing.T) {
+	t.Run("Money Movements", func(t *testing.T) {
+		// arrange
+		m := metrics.New(prometheus.NewRegistry())
+		next := service.NewWalletServiceMock()
+		next.On("Deposit", uint64(1), float32(100)).Return(float32(100), nil)
+		next.On("Withdraw", uint64(1), float32(30)).Return(float32(70), nil)
+		next.On("Withdraw", uint64(1), float32(500)).Return(float32(0), service.NewErrorNotEnoughMoney())
+		next.On("Transfer", uint64(1), uint64(2), float32(20)).Return(nil)
+		next.On("Deposit", uint64(9), float32(5)).Return(float32(0), service.NewErrorWalletNotFound())
+		serv := metrics.NewWalletService(next, m)
+		// act
+		serv.Deposit(context.Background(), 1, 100)
+		serv.Withdraw(context.Background(), 1, 30)
+		serv.Withdraw(context.Background(), 1, 500)
+		serv.Transfer(context.Background(), 1, 2, 20)
+		serv.Deposit(context.Background(), 9, 5)
+		// assert
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.Deposits))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.Withdrawals))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.Transfers))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.InsufficientFunds))
+		assert.Equal(t, float64(100), testutil.ToFloat64(m.AmountMoved.WithLabelValues("deposit")))
+		assert.Equal(t, float64(30), testutil.ToFloat64(m.AmountMoved.WithLabelValues("withdraw")))
+		assert.Equal(t, float64(20), testutil.ToFloat64(m.AmountMoved.WithLabelValues("transfer")))
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		// arrange
+		m := metrics.New(prometheus.NewRegistry())
+		ops := []service.Operation{
+			{WalletID: 1, Type: service.OperationDeposit, Amount: 10},
+			{WalletID: 1, Type: service.OperationWithdraw, Amount: 500},
+			{WalletID: 2, Type: service.OperationWithdraw, Amount: 5},
+		}
+		notEnough := service.NewErrorNotEnoughMoney()
+		next := service.NewWalletServiceMock()
+		next.On("Batch", ops, service.BatchBestEffort).Return([]service.OperationResult{
+			{Index: 0, WalletID: 1, Status: service.ResultOK},
+			{Index: 1, WalletID: 1, Status: service.ResultFailed, Code: notEnough.Code, Message: notEnough.Message},
+			{Index: 2, WalletID: 2, Status: service.ResultOK},
+		}, nil)
+		serv := metrics.NewWalletService(next, m)
+		// act
+		serv.Batch(context.Background(), ops, service.BatchBestEffort)
+		// assert
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.Deposits))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.Withdrawals))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.InsufficientFunds))
+		assert.Equal(t, float64(5), testutil.ToFloat64(m.AmountMoved.WithLabelValues("withdraw")))
+	})
+
+	t.Run("Negative Amount Accepted By Next", func(t *testing.T) {
+		// arrange
+		m := metrics.New(prometheus.NewRegistry())
+		next := service.NewWalletServiceMock()
+		next.On("Deposit", uint64(1), float32(-5)).Return(float32(95), nil)
+		serv := metrics.NewWalletService(next, m)
+		// act
+		_, err := serv.Deposit(context.Background(), 1, -5)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.Deposits))
+		assert.Equal(t, float64(0), testutil.ToFloat64(m.AmountMoved.WithLabelValues("deposit")))
+	})
+
+	t.Run("Invalid Amount Through The Stack", func(t *testing.T) {
+		// arrange
+		m := metrics.New(prometheus.NewRegistry())
+		next := service.NewWalletService(repository.NewMemoryWalletRepository())
+		next.OpenAccount(context.Background(), &repository.Wallet{Name: "John Doe", Balance: 100})
+		serv := metrics.NewWalletService(next, m)
+		app := fiber.New()
+		app.Use(recover.New())
+		handler.RegisterRoutes(app, handler.Handlers{Wallet: handler.NewWalletHandler(serv)})
+		// act
+		statuses := []int{}
+		for _, path := range []string{"/v1/wallets/1/deposits", "/v1/wallets/1/withdrawals"} {
+			for _, amount := range []string{"-5", "0"} {
+				req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"amount":`+amount+`}`))
+				req.Header.Set("Content-Type", "application/json")
+				resp, _ := app.Test(req)
+				statuses = append(statuses, resp.StatusCode)
+			}
+		}
+		// assert
+		assert.Equal(t, []int{400, 400, 400, 400}, statuses)
+		wallet, _ := serv.GetAccount(context.Background(), 1)
+		assert.Equal(t, float32(100), wallet.Balance)
+		assert.Equal(t, float64(0), testutil.ToFloat64(m.AmountMoved.WithLabelValues("deposit")))
+	})
+}
+
+func TestWalletRepository(t *testing.T) {
+	// arrange
+	m := metrics.New(prometheus.NewRegistry())
+	next := repository.NewWalletRepositoryMock()
+	next.On("Get", uint64(1)).Return(&repository.Wallet{ID: 1}, nil)
+	next.On("Update", uint64(1)).Return(errors.New("database down"))
+	repo := metrics.NewWalletRepository(next, m)
+	// act
+	repo.Get(context.Background(), 1)
+	err := repo.Update(context.Background(), 1, &repository.Wallet{ID: 1})
+	// assert
+	assert.Error(t, err)
+	assert.Equal(t, 2, testutil.CollectAndCount(m.RepositoryDuration))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.RepositoryErrors.WithLabelValues("Get")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.RepositoryErrors.WithLabelValues("Update")))
+}
+
+func TestHandler(t *testing.T) {
+	// arrange
+	registry := prometheus.NewRegistry()
+	m := metrics.New(registry)
+	m.Deposits.Inc()
+	app := fiber.New()
+	app.Get("/metrics", metrics.Handler(registry))
+	// act
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	// assert
+	assert.Equal(t, 200, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "wallet_deposits_total 1")
+}
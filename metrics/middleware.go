@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Middleware labels requests with the route pattern rather than the path,
+// so "/v1/wallets/:id" is one series however many wallets there are.
+// Requests that match no route share the "unmatched" label.
+func Middleware(m *Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		route := c.Route().Path
+		if status == fiber.StatusNotFound && route == "/" {
+			route = "unmatched"
+		}
+
+		// fiber reuses the method's buffer after the request; labels outlive it.
+		labels := []string{strings.Clone(c.Method()), route, strconv.Itoa(status)}
+		m.Requests.WithLabelValues(labels...).Inc()
+		m.RequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"context"
+	"gotest/repository"
+	"time"
+)
+
+type walletRepository struct {
+	next    repository.WalletRepository
+	metrics *Metrics
+}
+
+// NewWalletRepository times every call to next and counts its errors,
+// labelled by method name.
+func NewWalletRepository(next repository.WalletRepository, m *Metrics) repository.WalletRepository {
+	return walletRepository{next: next, metrics: m}
+}
+
+func (r walletRepository) observe(operation string, start time.Time, err error) {
+	r.metrics.RepositoryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		r.metrics.RepositoryErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+func (r walletRepository) Get(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	start := time.Now()
+	wallet, err := r.next.Get(ctx, id)
+	r.observe("Get", start, err)
+	return wallet, err
+}
+
+func (r walletRepository) Create(ctx context.Context, wallet *repository.Wallet, events ...repository.Event) error {
+	start := time.Now()
+	err := r.next.Create(ctx, wallet, events...)
+	r.observe("Create", start, err)
+	return err
+}
+
+func (r walletRepository) Update(ctx context.Context, id uint64, wallet *repository.Wallet, events ...repository.Event) error {
+	start := time.Now()
+	err := r.next.Update(ctx, id, wallet, events...)
+	r.observe("Update", start, err)
+	return err
+}
+
+func (r walletRepository) UpdateMany(ctx context.Context, wallets []*repository.Wallet, events ...repository.Event) error {
+	start := time.Now()
+	err := r.next.UpdateMany(ctx, wallets, events...)
+	r.observe("UpdateMany", start, err)
+	return err
+}
+
+func (r walletRepository) List(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) ([]repository.Wallet, string, error) {
+	start := time.Now()
+	wallets, next, err := r.next.List(ctx, filter, sort, cursor, limit)
+	r.observe("List", start, err)
+	return wallets, next, err
+}
+
+func (r walletRepository) Events(ctx context.Context, walletID uint64) ([]repository.Event, error) {
+	start := time.Now()
+	events, err := r.next.Events(ctx, walletID)
+	r.observe("Events", start, err)
+	return events, err
+}
+
+func (r walletRepository) EventsSince(ctx context.Context, walletID uint64, afterID uint64) ([]repository.Event, error) {
+	start := time.Now()
+	events, err := r.next.EventsSince(ctx, walletID, afterID)
+	r.observe("EventsSince", start, err)
+	return events, err
+}
+
+func (r walletRepository) SaveBalanceSnapshot(ctx context.Context, snapshot repository.BalanceSnapshot) error {
+	start := time.Now()
+	err := r.next.SaveBalanceSnapshot(ctx, snapshot)
+	r.observe("SaveBalanceSnapshot", start, err)
+	return err
+}
+
+func (r walletRepository) BalanceSnapshotAt(ctx context.Context, walletID uint64, at time.Time) (repository.BalanceSnapshot, error) {
+	start := time.Now()
+	snapshot, err := r.next.BalanceSnapshotAt(ctx, walletID, at)
+	r.observe("BalanceSnapshotAt", start, err)
+	return snapshot, err
+}
+
+func (r walletRepository) PendingEvents(ctx context.Context, limit int) ([]repository.Event, error) {
+	start := time.Now()
+	events, err := r.next.PendingEvents(ctx, limit)
+	r.observe("PendingEvents", start, err)
+	return events, err
+}
+
+func (r walletRepository) MarkEventPublished(ctx context.Context, id uint64) error {
+	start := time.Now()
+	err := r.next.MarkEventPublished(ctx, id)
+	r.observe("MarkEventPublished", start, err)
+	return err
+}
+
+func (r walletRepository) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := r.next.Ping(ctx)
+	r.observe("Ping", start, err)
+	return err
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"gotest/repository"
+	"gotest/service"
+	"time"
+)
+
+type walletService struct {
+	next    service.WalletService
+	metrics *Metrics
+}
+
+// NewWalletService counts money movements on top of next. Only successful
+// operations are counted, apart from insufficient-funds rejections.
+func NewWalletService(next service.WalletService, m *Metrics) service.WalletService {
+	return walletService{next: next, metrics: m}
+}
+
+func (s walletService) OpenAccount(ctx context.Context, wallet *repository.Wallet) error {
+	return s.next.OpenAccount(ctx, wallet)
+}
+
+func (s walletService) GetAccount(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	return s.next.GetAccount(ctx, id)
+}
+
+func (s walletService) ListAccounts(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) ([]repository.Wallet, string, error) {
+	return s.next.ListAccounts(ctx, filter, sort, cursor, limit)
+}
+
+func (s walletService) Withdraw(ctx context.Context, id uint64, amount float32) (float32, error) {
+	balance, err := s.next.Withdraw(ctx, id, amount)
+	s.withdrawn(amount, err)
+	return balance, err
+}
+
+func (s walletService) Deposit(ctx context.Context, id uint64, amount float32) (float32, error) {
+	balance, err := s.next.Deposit(ctx, id, amount)
+	s.deposited(amount, err)
+	return balance, err
+}
+
+func (s walletService) Transfer(ctx context.Context, fromID uint64, toID uint64, amount float32) error {
+	err := s.next.Transfer(ctx, fromID, toID, amount)
+	switch {
+	case err == nil:
+		s.metrics.Transfers.Inc()
+		s.moved("transfer", amount)
+	case errors.Is(err, service.ErrNotEnoughMoney):
+		s.metrics.InsufficientFunds.Inc()
+	}
+	return err
+}
+
+func (s walletService) History(ctx context.Context, id uint64) ([]service.Transaction, error) {
+	return s.next.History(ctx, id)
+}
+
+func (s walletService) GetBalanceAt(ctx context.Context, id uint64, at time.Time) (float32, error) {
+	return s.next.GetBalanceAt(ctx, id, at)
+}
+
+func (s walletService) AdjustBalance(ctx context.Context, id uint64, balance float32, reason string) error {
+	return s.next.AdjustBalance(ctx, id, balance, reason)
+}
+
+func (s walletService) SetStatus(ctx context.Context, id uint64, status string, reason string) (*repository.Wallet, bool, error) {
+	return s.next.SetStatus(ctx, id, status, reason)
+}
+
+func (s walletService) Batch(ctx context.Context, ops []service.Operation, mode string) ([]service.OperationResult, error) {
+	results, err := s.next.Batch(ctx, ops, mode)
+	for _, result := range results {
+		var opErr error
+		if result.Status != service.ResultOK {
+			opErr = service.WalletError{Code: result.Code, Message: result.Message}
+		}
+
+		switch ops[result.Index].Type {
+		case service.OperationDeposit:
+			s.deposited(ops[result.Index].Amount, opErr)
+		case service.OperationWithdraw:
+			s.withdrawn(ops[result.Index].Amount, opErr)
+		}
+	}
+	return results, err
+}
+
+// moved adds amount to AmountMoved. Counters panic when they would go down,
+// so anything but a positive amount is left out whatever next accepted.
+func (s walletService) moved(operation string, amount float32) {
+	if amount > 0 {
+		s.metrics.AmountMoved.WithLabelValues(operation).Add(float64(amount))
+	}
+}
+
+func (s walletService) deposited(amount float32, err error) {
+	if err == nil {
+		s.metrics.Deposits.Inc()
+		s.moved("deposit", amount)
+	}
+}
+
+func (s walletService) withdrawn(amount float32, err error) {
+	switch {
+	case err == nil:
+		s.metrics.Withdrawals.Inc()
+		s.moved("withdraw", amount)
+	case errors.Is(err, service.ErrNotEnoughMoney):
+		s.metrics.InsufficientFunds.Inc()
+	}
+}
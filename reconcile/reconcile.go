@@ -0,0 +1,134 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"gotest/audit"
+	"gotest/repository"
+	"gotest/service"
+	"math"
+	"time"
+)
+
+// Mismatch is a wallet whose stored balance differs from the sum of its
+// ledger amounts by more than the tolerance.
+type Mismatch struct {
+	WalletID     uint64  `json:"wallet_id"`
+	Stored       float32 `json:"stored_balance"`
+	Ledger       float32 `json:"ledger_balance"`
+	Difference   float32 `json:"difference"`
+	Transactions int     `json:"transactions"`
+	Adjusted     bool    `json:"adjusted"`
+	Error        string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt time.Time  `json:"finished_at"`
+	Checked    int        `json:"checked"`
+	Mismatched int        `json:"mismatched"`
+	Adjusted   int        `json:"adjusted"`
+	Mismatches []Mismatch `json:"mismatches"`
+}
+
+// Unresolved counts mismatches that are still out of balance.
+func (r Report) Unresolved() int {
+	return r.Mismatched - r.Adjusted
+}
+
+// Reconciler treats the ledger as the source of truth. With Fix set it
+// adjusts drifted balances to the ledger total and records each adjustment
+// in the audit log under Actor.
+type Reconciler struct {
+	walletServ service.WalletService
+	auditLog   audit.Logger
+	Tolerance  float32
+	Actor      string
+	Fix        bool
+}
+
+func NewReconciler(walletServ service.WalletService, auditLog audit.Logger) *Reconciler {
+	return &Reconciler{
+		walletServ: walletServ,
+		auditLog:   auditLog,
+		Tolerance:  0.005,
+		Actor:      "reconciler",
+	}
+}
+
+// Run checks every wallet. A wallet that cannot be checked or adjusted is
+// reported with its error rather than stopping the run; only failing to list
+// wallets or to write the audit log aborts it.
+func (r *Reconciler) Run(ctx context.Context) (Report, error) {
+	report := Report{StartedAt: time.Now().UTC(), Mismatches: []Mismatch{}}
+
+	cursor := ""
+	for {
+		wallets, next, err := r.walletServ.ListAccounts(ctx, repository.WalletFilter{}, repository.WalletSort{}, cursor, service.MaxPageSize)
+		if err != nil {
+			return report, err
+		}
+
+		for _, wallet := range wallets {
+			report.Checked++
+			mismatch, ok := r.check(ctx, wallet)
+			if !ok {
+				continue
+			}
+
+			report.Mismatched++
+			if r.Fix && mismatch.Error == "" {
+				if err := r.adjust(ctx, &mismatch); err != nil {
+					return report, err
+				}
+			}
+			if mismatch.Adjusted {
+				report.Adjusted++
+			}
+			report.Mismatches = append(report.Mismatches, mismatch)
+		}
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	return report, nil
+}
+
+func (r *Reconciler) check(ctx context.Context, wallet repository.Wallet) (Mismatch, bool) {
+	mismatch := Mismatch{WalletID: wallet.ID, Stored: wallet.Balance}
+
+	transactions, err := r.walletServ.History(ctx, wallet.ID)
+	if err != nil {
+		mismatch.Error = err.Error()
+		return mismatch, true
+	}
+
+	for _, transaction := range transactions {
+		mismatch.Ledger += transaction.Amount
+	}
+	mismatch.Transactions = len(transactions)
+	mismatch.Difference = mismatch.Stored - mismatch.Ledger
+	return mismatch, math.Abs(float64(mismatch.Difference)) > float64(r.Tolerance)
+}
+
+func (r *Reconciler) adjust(ctx context.Context, mismatch *Mismatch) error {
+	reason := fmt.Sprintf("reconciliation: stored %.2f, ledger %.2f", mismatch.Stored, mismatch.Ledger)
+	if err := r.walletServ.AdjustBalance(ctx, mismatch.WalletID, mismatch.Ledger, reason); err != nil {
+		mismatch.Error = err.Error()
+		return nil
+	}
+	mismatch.Adjusted = true
+
+	return r.auditLog.Record(audit.Event{
+		Actor:         r.Actor,
+		Action:        audit.ActionAdjustBalance,
+		WalletID:      mismatch.WalletID,
+		BalanceBefore: mismatch.Stored,
+		BalanceAfter:  mismatch.Ledger,
+		Detail:        reason,
+	})
+}
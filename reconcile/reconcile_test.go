@@ -0,0 +1,125 @@
+// go:build unit
+package reconcile_test
+
+import (
+	"context"
+	"errors"
+	"gotest/audit"
+	"gotest/reconcile"
+	"gotest/repository"
+	"gotest/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// drifted opens two wallets and overwrites the first one's balance without
+// an event, the way a lost update would.
+func drifted() (repository.WalletRepository, service.WalletService) {
+	repo := repository.NewMemoryWalletRepository()
+	serv := service.NewWalletService(repo)
+	john := repository.Wallet{Name: "John Doe", Balance: 100}
+	jane := repository.Wallet{Name: "Jane Doe", Balance: 50}
+	serv.OpenAccount(context.Background(), &john)
+	serv.OpenAccount(context.Background(), &jane)
+	serv.Withdraw(context.Background(), john.ID, 30)
+	serv.Deposit(context.Background(), jane.ID, 25)
+
+	wallet, _ := repo.Get(context.Background(), john.ID)
+	wallet.Balance = 90
+	repo.Update(context.Background(), john.ID, wallet)
+	return repo, serv
+}
+
+func TestRun(t *testing.T) {
+	t.Run("Report Only", func(t *testing.T) {
+		// arrange
+		repo, serv := drifted()
+		auditLog := audit.NewLoggerMock()
+		reconciler := reconcile.NewReconciler(serv, auditLog)
+		// act
+		report, err := reconciler.Run(context.Background())
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 2, report.Checked)
+		assert.Equal(t, 1, report.Unresolved())
+		assert.Equal(t, []reconcile.Mismatch{
+			{WalletID: 1, Stored: 90, Ledger: 70, Difference: 20, Transactions: 2},
+		}, report.Mismatches)
+		wallet, _ := repo.Get(context.Background(), 1)
+		assert.Equal(t, float32(90), wallet.Balance)
+		auditLog.AssertNotCalled(t, "Record", mock.Anything)
+	})
+
+	t.Run("Fix", func(t *testing.T) {
+		// arrange
+		repo, serv := drifted()
+		auditLog := audit.NewLoggerMock()
+		auditLog.On("Record", mock.Anything).Return(nil)
+		reconciler := reconcile.NewReconciler(serv, auditLog)
+		reconciler.Fix = true
+		reconciler.Actor = "ops"
+		// act
+		report, err := reconciler.Run(context.Background())
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Adjusted)
+		assert.Equal(t, 0, report.Unresolved())
+		wallet, _ := repo.Get(context.Background(), 1)
+		assert.Equal(t, float32(70), wallet.Balance)
+		recorded := auditLog.Calls[0].Arguments.Get(0).(audit.Event)
+		assert.Equal(t, "ops", recorded.Actor)
+		assert.Equal(t, audit.ActionAdjustBalance, recorded.Action)
+		assert.Equal(t, float32(90), recorded.BalanceBefore)
+		assert.Equal(t, float32(70), recorded.BalanceAfter)
+
+		again, _ := reconciler.Run(context.Background())
+		assert.Equal(t, 0, again.Mismatched)
+		history, _ := serv.History(context.Background(), 1)
+		assert.Equal(t, float32(70), history[len(history)-1].Balance)
+	})
+
+	t.Run("Within Tolerance", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		serv := service.NewWalletService(repo)
+		wallet := repository.Wallet{Name: "John Doe", Balance: 0.1}
+		serv.OpenAccount(context.Background(), &wallet)
+		for i := 0; i < 10; i++ {
+			serv.Deposit(context.Background(), wallet.ID, 0.1)
+		}
+		// act
+		report, err := reconcile.NewReconciler(serv, audit.Nop()).Run(context.Background())
+		// assert
+		assert.NoError(t, err)
+		assert.Empty(t, report.Mismatches)
+	})
+
+	t.Run("Adjustment Fails", func(t *testing.T) {
+		// arrange
+		wallet := repository.Wallet{ID: 1, Balance: 90}
+		serv := service.NewWalletServiceMock()
+		serv.On("ListAccounts", repository.WalletFilter{}, repository.WalletSort{}, "", service.MaxPageSize).Return([]repository.Wallet{wallet}, "", nil)
+		serv.On("History", uint64(1)).Return([]service.Transaction{{Amount: 70}}, nil)
+		serv.On("AdjustBalance", uint64(1), float32(70), mock.Anything).Return(service.NewErrorConflict())
+		reconciler := reconcile.NewReconciler(serv, audit.Nop())
+		reconciler.Fix = true
+		// act
+		report, err := reconciler.Run(context.Background())
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Unresolved())
+		assert.Equal(t, "CONCURRENT UPDATE", report.Mismatches[0].Error)
+	})
+
+	t.Run("Error Listing Wallets", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		serv.On("ListAccounts", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]repository.Wallet{}, "", errors.New("database down"))
+		// act
+		_, err := reconcile.NewReconciler(serv, audit.Nop()).Run(context.Background())
+		// assert
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SortByID        = "id"
+	SortByName      = "name"
+	SortByBalance   = "balance"
+	SortByCreatedAt = "created_at"
+)
+
+type WalletFilter struct {
+	NamePrefix    string
+	Status        string
+	MinBalance    *float32
+	MaxBalance    *float32
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+func (f WalletFilter) Match(wallet Wallet) bool {
+	switch {
+	case f.NamePrefix != "" && !strings.HasPrefix(strings.ToLower(wallet.Name), strings.ToLower(f.NamePrefix)):
+		return false
+	case f.Status != "" && wallet.Status != f.Status:
+		return false
+	case f.MinBalance != nil && wallet.Balance < *f.MinBalance:
+		return false
+	case f.MaxBalance != nil && wallet.Balance > *f.MaxBalance:
+		return false
+	case !f.CreatedAfter.IsZero() && wallet.CreatedAt.Before(f.CreatedAfter):
+		return false
+	case !f.CreatedBefore.IsZero() && !wallet.CreatedAt.Before(f.CreatedBefore):
+		return false
+	}
+	return true
+}
+
+type WalletSort struct {
+	Field string
+	Desc  bool
+}
+
+func (s WalletSort) Valid() bool {
+	switch s.Field {
+	case "", SortByID, SortByName, SortByBalance, SortByCreatedAt:
+		return true
+	}
+	return false
+}
+
+// less orders by the sort field and breaks ties by ID, which makes the
+// order total and lets a cursor resume exactly after the last row.
+func (s WalletSort) less(a, b Wallet) bool {
+	cmp := 0
+	switch s.Field {
+	case SortByName:
+		cmp = strings.Compare(a.Name, b.Name)
+	case SortByBalance:
+		cmp = compare(a.Balance, b.Balance)
+	case SortByCreatedAt:
+		cmp = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if cmp == 0 {
+		cmp = compare(a.ID, b.ID)
+	}
+	if s.Desc {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+func compare[T float32 | uint64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// cursor holds the sort key of the last row of a page. It is handed out
+// base64-encoded so clients treat it as opaque.
+type cursor struct {
+	Field     string    `json:"f"`
+	Desc      bool      `json:"d"`
+	ID        uint64    `json:"i"`
+	Name      string    `json:"n,omitempty"`
+	Balance   float32   `json:"b,omitempty"`
+	CreatedAt time.Time `json:"c"`
+}
+
+func encodeCursor(s WalletSort, last Wallet) string {
+	body, _ := json.Marshal(cursor{
+		Field:     s.Field,
+		Desc:      s.Desc,
+		ID:        last.ID,
+		Name:      last.Name,
+		Balance:   last.Balance,
+		CreatedAt: last.CreatedAt,
+	})
+	return base64.RawURLEncoding.EncodeToString(body)
+}
+
+func decodeCursor(s WalletSort, token string) (Wallet, error) {
+	body, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Wallet{}, ErrInvalidCursor
+	}
+
+	c := cursor{}
+	if err := json.Unmarshal(body, &c); err != nil || c.Field != s.Field || c.Desc != s.Desc {
+		return Wallet{}, ErrInvalidCursor
+	}
+	return Wallet{ID: c.ID, Name: c.Name, Balance: c.Balance, CreatedAt: c.CreatedAt}, nil
+}
+
+// ListWallets filters, sorts and pages an in-memory set of wallets. The
+// returned cursor is empty on the last page.
+func ListWallets(wallets []Wallet, filter WalletFilter, s WalletSort, token string, limit int) ([]Wallet, string, error) {
+	var after *Wallet
+	if token != "" {
+		last, err := decodeCursor(s, token)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &last
+	}
+
+	matched := []Wallet{}
+	for _, wallet := range wallets {
+		if !filter.Match(wallet) {
+			continue
+		}
+		if after != nil && !s.less(*after, wallet) {
+			continue
+		}
+		matched = append(matched, wallet)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return s.less(matched[i], matched[j])
+	})
+
+	if limit <= 0 || len(matched) <= limit {
+		return matched, "", nil
+	}
+
+	page := matched[:limit]
+	return page, encodeCursor(s, page[limit-1]), nil
+}
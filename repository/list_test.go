@@ -0,0 +1,84 @@
+// go:build unit
+package repository_test
+
+import (
+	"gotest/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newWallets() []repository.Wallet {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []repository.Wallet{
+		{ID: 1, Name: "John Doe", Balance: 1000, Status: repository.StatusActive, CreatedAt: day},
+		{ID: 2, Name: "Jane Doe", Balance: 500, Status: repository.StatusFrozen, CreatedAt: day.AddDate(0, 0, 1)},
+		{ID: 3, Name: "Johnny Walker", Balance: 500, Status: repository.StatusActive, CreatedAt: day.AddDate(0, 0, 2)},
+		{ID: 4, Name: "Alice", Balance: 10, Status: repository.StatusActive, CreatedAt: day.AddDate(0, 0, 3)},
+	}
+}
+
+func ids(wallets []repository.Wallet) []uint64 {
+	result := []uint64{}
+	for _, wallet := range wallets {
+		result = append(result, wallet.ID)
+	}
+	return result
+}
+
+func TestListWallets(t *testing.T) {
+	t.Run("Filter", func(t *testing.T) {
+		// arrange
+		min := float32(100)
+		filter := repository.WalletFilter{
+			NamePrefix:    "john",
+			Status:        repository.StatusActive,
+			MinBalance:    &min,
+			CreatedBefore: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+		}
+		// act
+		result, next, err := repository.ListWallets(newWallets(), filter, repository.WalletSort{}, "", 10)
+		// assert
+		assert.NoError(t, err)
+		assert.Empty(t, next)
+		assert.Equal(t, []uint64{1}, ids(result))
+	})
+
+	t.Run("Sort With Tie Break", func(t *testing.T) {
+		// act
+		result, _, err := repository.ListWallets(newWallets(), repository.WalletFilter{}, repository.WalletSort{Field: repository.SortByBalance, Desc: true}, "", 10)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{1, 3, 2, 4}, ids(result))
+	})
+
+	t.Run("Paginate", func(t *testing.T) {
+		// arrange
+		sort := repository.WalletSort{Field: repository.SortByName}
+		// act
+		first, cursor, err := repository.ListWallets(newWallets(), repository.WalletFilter{}, sort, "", 3)
+		second, last, _ := repository.ListWallets(newWallets(), repository.WalletFilter{}, sort, cursor, 3)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{4, 2, 1}, ids(first))
+		assert.Equal(t, []uint64{3}, ids(second))
+		assert.Empty(t, last)
+	})
+
+	t.Run("Invalid Cursor", func(t *testing.T) {
+		// act
+		_, _, err := repository.ListWallets(newWallets(), repository.WalletFilter{}, repository.WalletSort{}, "garbage!", 3)
+		// assert
+		assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+	})
+
+	t.Run("Cursor From Another Sort", func(t *testing.T) {
+		// arrange
+		_, cursor, _ := repository.ListWallets(newWallets(), repository.WalletFilter{}, repository.WalletSort{Field: repository.SortByName}, "", 1)
+		// act
+		_, _, err := repository.ListWallets(newWallets(), repository.WalletFilter{}, repository.WalletSort{Field: repository.SortByBalance}, cursor, 1)
+		// assert
+		assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+	})
+}
@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryWalletRepository struct {
+	mu          sync.RWMutex
+	wallets     map[uint64]Wallet
+	events      []Event
+	snapshots   map[uint64][]BalanceSnapshot
+	lastID      uint64
+	lastEventID uint64
+	path        string
+}
+
+func NewMemoryWalletRepository() *memoryWalletRepository {
+	return &memoryWalletRepository{wallets: map[uint64]Wallet{}, snapshots: map[uint64][]BalanceSnapshot{}}
+}
+
+type snapshotFile struct {
+	Wallets     []Wallet          `json:"wallets"`
+	Events      []Event           `json:"events"`
+	Snapshots   []BalanceSnapshot `json:"balance_snapshots,omitempty"`
+	LastID      uint64            `json:"last_id"`
+	LastEventID uint64            `json:"last_event_id"`
+}
+
+// NewFileWalletRepository keeps everything in memory like the memory
+// repository, and rewrites the whole JSON file after every write. It is
+// meant for local use and small data sets.
+func NewFileWalletRepository(path string) (*memoryWalletRepository, error) {
+	r := NewMemoryWalletRepository()
+	r.path = path
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := snapshotFile{}
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, err
+	}
+	for _, wallet := range snapshot.Wallets {
+		r.wallets[wallet.ID] = wallet
+	}
+	r.events = snapshot.Events
+	for _, balance := range snapshot.Snapshots {
+		r.snapshots[balance.WalletID] = append(r.snapshots[balance.WalletID], balance)
+	}
+	r.lastID = snapshot.LastID
+	r.lastEventID = snapshot.LastEventID
+	return r, nil
+}
+
+func (r *memoryWalletRepository) save() error {
+	if r.path == "" {
+		return nil
+	}
+
+	snapshot := snapshotFile{Events: r.events, LastID: r.lastID, LastEventID: r.lastEventID}
+	for id := uint64(1); id <= r.lastID; id++ {
+		if wallet, ok := r.wallets[id]; ok {
+			snapshot.Wallets = append(snapshot.Wallets, wallet)
+		}
+		snapshot.Snapshots = append(snapshot.Snapshots, r.snapshots[id]...)
+	}
+
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	return WriteFile(r.path, content)
+}
+
+func (r *memoryWalletRepository) Get(ctx context.Context, id uint64) (*Wallet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	wallet := r.wallets[id]
+	return &wallet, nil
+}
+
+// Create assigns the wallet ID. Events without a wallet ID are attributed to
+// the new wallet, because callers cannot know the ID beforehand.
+func (r *memoryWalletRepository) Create(ctx context.Context, wallet *Wallet, events ...Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	undo := r.checkpoint(r.lastID + 1)
+	r.lastID++
+	created := *wallet
+	created.ID = r.lastID
+	r.wallets[created.ID] = created
+
+	for _, event := range events {
+		if event.WalletID == 0 {
+			event.WalletID = created.ID
+		}
+		r.appendEvent(event)
+	}
+	if err := r.save(); err != nil {
+		undo()
+		return err
+	}
+	wallet.ID = created.ID
+	return nil
+}
+
+func (r *memoryWalletRepository) Update(ctx context.Context, id uint64, wallet *Wallet, events ...Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.wallets[id]; !ok {
+		return ErrWalletNotFound
+	}
+
+	undo := r.checkpoint(id)
+	updated := *wallet
+	updated.ID = id
+	r.wallets[id] = updated
+	r.appendEvents(events)
+	if err := r.save(); err != nil {
+		undo()
+		return err
+	}
+	return nil
+}
+
+func (r *memoryWalletRepository) UpdateMany(ctx context.Context, wallets []*Wallet, events ...Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, wallet := range wallets {
+		if _, ok := r.wallets[wallet.ID]; !ok {
+			return ErrWalletNotFound
+		}
+	}
+
+	ids := make([]uint64, len(wallets))
+	for i, wallet := range wallets {
+		ids[i] = wallet.ID
+	}
+	undo := r.checkpoint(ids...)
+	for _, wallet := range wallets {
+		r.wallets[wallet.ID] = *wallet
+	}
+	r.appendEvents(events)
+	if err := r.save(); err != nil {
+		undo()
+		return err
+	}
+	return nil
+}
+
+func (r *memoryWalletRepository) List(ctx context.Context, filter WalletFilter, sort WalletSort, cursor string, limit int) ([]Wallet, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	r.mu.RLock()
+	wallets := make([]Wallet, 0, len(r.wallets))
+	for _, wallet := range r.wallets {
+		wallets = append(wallets, wallet)
+	}
+	r.mu.RUnlock()
+
+	return ListWallets(wallets, filter, sort, cursor, limit)
+}
+
+func (r *memoryWalletRepository) Events(ctx context.Context, walletID uint64) ([]Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	events := []Event{}
+	for _, event := range r.events {
+		if event.WalletID == walletID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *memoryWalletRepository) EventsSince(ctx context.Context, walletID uint64, afterID uint64) ([]Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	start := sort.Search(len(r.events), func(i int) bool { return r.events[i].ID > afterID })
+	events := []Event{}
+	for _, event := range r.events[start:] {
+		if event.WalletID == walletID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// SaveBalanceSnapshot ignores snapshots that are not newer than the
+// wallet's latest one, which keeps each wallet's snapshots ordered.
+func (r *memoryWalletRepository) SaveBalanceSnapshot(ctx context.Context, snapshot BalanceSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	snapshots := r.snapshots[snapshot.WalletID]
+	if n := len(snapshots); n > 0 && snapshots[n-1].EventID >= snapshot.EventID {
+		return nil
+	}
+	r.snapshots[snapshot.WalletID] = append(snapshots, snapshot)
+	if err := r.save(); err != nil {
+		r.snapshots[snapshot.WalletID] = snapshots
+		return err
+	}
+	return nil
+}
+
+// BalanceSnapshotAt returns the latest snapshot taken at or before at, or
+// the zero snapshot when there is none.
+func (r *memoryWalletRepository) BalanceSnapshotAt(ctx context.Context, walletID uint64, at time.Time) (BalanceSnapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return BalanceSnapshot{}, err
+	}
+
+	snapshots := r.snapshots[walletID]
+	i := sort.Search(len(snapshots), func(i int) bool { return snapshots[i].OccurredAt.After(at) })
+	if i == 0 {
+		return BalanceSnapshot{WalletID: walletID}, nil
+	}
+	return snapshots[i-1], nil
+}
+
+func (r *memoryWalletRepository) PendingEvents(ctx context.Context, limit int) ([]Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pending := []Event{}
+	for _, event := range r.events {
+		if len(pending) == limit {
+			break
+		}
+		if event.PublishedAt == nil {
+			pending = append(pending, event)
+		}
+	}
+	return pending, nil
+}
+
+func (r *memoryWalletRepository) MarkEventPublished(ctx context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for i := range r.events {
+		if r.events[i].ID == id {
+			previous, now := r.events[i].PublishedAt, time.Now().UTC()
+			r.events[i].PublishedAt = &now
+			if err := r.save(); err != nil {
+				r.events[i].PublishedAt = previous
+				return err
+			}
+			return nil
+		}
+	}
+	return ErrEventNotFound
+}
+
+// Ping waits for the lock like any read, so a writer stuck holding it shows
+// up as a slow probe. A file-backed repository also checks that it can still
+// write its directory.
+func (r *memoryWalletRepository) Ping(ctx context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.path == "" {
+		return nil
+	}
+	return PingFile(r.path)
+}
+
+// checkpoint returns a function that puts back the wallets in ids and drops
+// the outbox events appended since, for a save that failed. Without it the
+// relay would publish events, and readers would see balances, that the next
+// start reloads without.
+func (r *memoryWalletRepository) checkpoint(ids ...uint64) func() {
+	wallets := map[uint64]Wallet{}
+	for _, id := range ids {
+		if wallet, ok := r.wallets[id]; ok {
+			wallets[id] = wallet
+		}
+	}
+	events, lastID, lastEventID := len(r.events), r.lastID, r.lastEventID
+
+	return func() {
+		for _, id := range ids {
+			if wallet, ok := wallets[id]; ok {
+				r.wallets[id] = wallet
+			} else {
+				delete(r.wallets, id)
+			}
+		}
+		r.events = r.events[:events]
+		r.lastID, r.lastEventID = lastID, lastEventID
+	}
+}
+
+func (r *memoryWalletRepository) appendEvents(events []Event) {
+	for _, event := range events {
+		r.appendEvent(event)
+	}
+}
+
+func (r *memoryWalletRepository) appendEvent(event Event) {
+	r.lastEventID++
+	event.ID = r.lastEventID
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	r.events = append(r.events, event)
+}
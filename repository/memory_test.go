@@ -0,0 +1,224 @@
+// go:build unit
+package repository_test
+
+import (
+	"context"
+	"gotest/repository"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCreate(t *testing.T) {
+	// arrange
+	repo := repository.NewMemoryWalletRepository()
+	wallet := repository.Wallet{Name: "John Doe", Balance: 1000}
+	// act
+	err := repo.Create(context.Background(), &wallet, repository.Event{Type: "WalletOpened"})
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), wallet.ID)
+	events, _ := repo.PendingEvents(context.Background(), 10)
+	assert.Equal(t, wallet.ID, events[0].WalletID)
+}
+
+func TestMemoryGet(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		wallet := repository.Wallet{Name: "John Doe", Balance: 1000}
+		repo.Create(context.Background(), &wallet)
+		// act
+		result, err := repo.Get(context.Background(), wallet.ID)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, wallet, *result)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		// act
+		result, err := repo.Get(context.Background(), 1)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(0), result.ID)
+	})
+}
+
+func TestMemoryUpdateMany(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		john := repository.Wallet{Name: "John Doe", Balance: 1000}
+		jane := repository.Wallet{Name: "Jane Doe", Balance: 0}
+		repo.Create(context.Background(), &john)
+		repo.Create(context.Background(), &jane)
+		john.Balance, jane.Balance = 700, 300
+		// act
+		err := repo.UpdateMany(context.Background(), []*repository.Wallet{&john, &jane}, repository.Event{Type: "TransferCompleted", WalletID: john.ID})
+		// assert
+		assert.NoError(t, err)
+		result, _ := repo.Get(context.Background(), jane.ID)
+		assert.Equal(t, float32(300), result.Balance)
+	})
+
+	t.Run("Nothing Written When A Wallet Is Missing", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		john := repository.Wallet{Name: "John Doe", Balance: 1000}
+		repo.Create(context.Background(), &john)
+		john.Balance = 0
+		missing := repository.Wallet{ID: 9}
+		// act
+		err := repo.UpdateMany(context.Background(), []*repository.Wallet{&john, &missing}, repository.Event{Type: "TransferCompleted"})
+		// assert
+		assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+		result, _ := repo.Get(context.Background(), john.ID)
+		assert.Equal(t, float32(1000), result.Balance)
+		events, _ := repo.PendingEvents(context.Background(), 10)
+		assert.Empty(t, events)
+	})
+}
+
+func TestMemoryMarkEventPublished(t *testing.T) {
+	// arrange
+	repo := repository.NewMemoryWalletRepository()
+	repo.Create(context.Background(), &repository.Wallet{Name: "John Doe"}, repository.Event{Type: "WalletOpened"})
+	// act
+	err := repo.MarkEventPublished(context.Background(), 1)
+	// assert
+	assert.NoError(t, err)
+	events, _ := repo.PendingEvents(context.Background(), 10)
+	assert.Empty(t, events)
+	assert.ErrorIs(t, repo.MarkEventPublished(context.Background(), 2), repository.ErrEventNotFound)
+}
+
+func TestFileRepository(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "wallets.json")
+	repo, _ := repository.NewFileWalletRepository(path)
+	wallet := repository.Wallet{Name: "John Doe", Balance: 1000}
+	repo.Create(context.Background(), &wallet, repository.Event{Type: "WalletOpened"})
+	wallet.Balance = 800
+	repo.Update(context.Background(), wallet.ID, &wallet)
+	// act
+	reopened, err := repository.NewFileWalletRepository(path)
+	// assert
+	assert.NoError(t, err)
+	result, _ := reopened.Get(context.Background(), wallet.ID)
+	assert.Equal(t, float32(800), result.Balance)
+	next := repository.Wallet{Name: "Jane Doe"}
+	reopened.Create(context.Background(), &next)
+	assert.Equal(t, uint64(2), next.ID)
+	events, _ := reopened.PendingEvents(context.Background(), 10)
+	assert.Len(t, events, 1)
+}
+
+func TestFileRepositoryFailedWrite(t *testing.T) {
+	// arrange
+	dir := filepath.Join(t.TempDir(), "data")
+	os.Mkdir(dir, 0o755)
+	path := filepath.Join(dir, "wallets.json")
+	repo, _ := repository.NewFileWalletRepository(path)
+	wallet := repository.Wallet{Name: "John Doe", Balance: 1000}
+	repo.Create(context.Background(), &wallet, repository.Event{Type: "WalletOpened"})
+	os.RemoveAll(dir)
+	// act
+	updated := repository.Wallet{ID: wallet.ID, Name: "John Doe", Balance: 800}
+	updateErr := repo.UpdateMany(context.Background(), []*repository.Wallet{&updated}, repository.Event{WalletID: wallet.ID, Type: "FundsWithdrawn"})
+	next := repository.Wallet{Name: "Jane Doe"}
+	createErr := repo.Create(context.Background(), &next, repository.Event{Type: "WalletOpened"})
+	publishErr := repo.MarkEventPublished(context.Background(), 1)
+	// assert
+	assert.ErrorIs(t, updateErr, repository.ErrUnavailable)
+	assert.ErrorIs(t, createErr, repository.ErrUnavailable)
+	assert.ErrorIs(t, publishErr, repository.ErrUnavailable)
+	assert.Equal(t, uint64(0), next.ID)
+	result, _ := repo.Get(context.Background(), wallet.ID)
+	assert.Equal(t, float32(1000), result.Balance)
+	missing, _ := repo.Get(context.Background(), 2)
+	assert.Equal(t, uint64(0), missing.ID)
+	events, _ := repo.PendingEvents(context.Background(), 10)
+	assert.Len(t, events, 1)
+	os.Mkdir(dir, 0o755)
+	assert.NoError(t, repo.Create(context.Background(), &next, repository.Event{Type: "WalletOpened"}))
+	assert.Equal(t, uint64(2), next.ID)
+	reopened, _ := repository.NewFileWalletRepository(path)
+	events, _ = reopened.PendingEvents(context.Background(), 10)
+	assert.Equal(t, []uint64{1, 2}, []uint64{events[0].ID, events[1].ID})
+}
+
+func TestMemoryBalanceSnapshots(t *testing.T) {
+	// arrange
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	repo := repository.NewMemoryWalletRepository()
+	repo.Create(context.Background(), &repository.Wallet{Name: "John Doe"}, repository.Event{Type: "WalletOpened"}, repository.Event{Type: "FundsDeposited"})
+	repo.Create(context.Background(), &repository.Wallet{Name: "Jane Doe"}, repository.Event{Type: "WalletOpened"})
+	repo.Update(context.Background(), 1, &repository.Wallet{Name: "John Doe"}, repository.Event{Type: "FundsDeposited", WalletID: 1})
+	// act
+	repo.SaveBalanceSnapshot(context.Background(), repository.BalanceSnapshot{WalletID: 1, EventID: 1, Balance: 10, OccurredAt: start})
+	repo.SaveBalanceSnapshot(context.Background(), repository.BalanceSnapshot{WalletID: 1, EventID: 2, Balance: 20, OccurredAt: start.Add(time.Hour)})
+	repo.SaveBalanceSnapshot(context.Background(), repository.BalanceSnapshot{WalletID: 1, EventID: 1, Balance: 99, OccurredAt: start})
+	// assert
+	snapshot, err := repo.BalanceSnapshotAt(context.Background(), 1, start.Add(30*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, float32(10), snapshot.Balance)
+	snapshot, _ = repo.BalanceSnapshotAt(context.Background(), 1, start.Add(2*time.Hour))
+	assert.Equal(t, uint64(2), snapshot.EventID)
+	snapshot, _ = repo.BalanceSnapshotAt(context.Background(), 1, start.Add(-time.Hour))
+	assert.Equal(t, repository.BalanceSnapshot{WalletID: 1}, snapshot)
+
+	events, _ := repo.EventsSince(context.Background(), 1, snapshot.EventID)
+	assert.Len(t, events, 3)
+	events, _ = repo.EventsSince(context.Background(), 1, 2)
+	assert.Len(t, events, 1)
+	assert.Equal(t, uint64(4), events[0].ID)
+}
+
+func TestMemoryCanceledContext(t *testing.T) {
+	// arrange
+	repo := repository.NewMemoryWalletRepository()
+	wallet := repository.Wallet{Name: "John Doe", Balance: 1000}
+	repo.Create(context.Background(), &wallet)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	updated := wallet
+	updated.Balance = 0
+	// act
+	err := repo.Update(ctx, wallet.ID, &updated, repository.Event{Type: "FundsWithdrawn"})
+	// assert
+	assert.ErrorIs(t, err, context.Canceled)
+	_, getErr := repo.Get(ctx, wallet.ID)
+	assert.ErrorIs(t, getErr, context.Canceled)
+	result, _ := repo.Get(context.Background(), wallet.ID)
+	assert.Equal(t, float32(1000), result.Balance)
+	events, _ := repo.Events(context.Background(), wallet.ID)
+	assert.Empty(t, events)
+}
+
+func TestMemoryPing(t *testing.T) {
+	t.Run("Memory", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		// act
+		err := repo.Ping(context.Background())
+		// assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unwritable Directory", func(t *testing.T) {
+		// arrange
+		dir := filepath.Join(t.TempDir(), "data")
+		os.Mkdir(dir, 0o755)
+		repo, _ := repository.NewFileWalletRepository(filepath.Join(dir, "wallets.json"))
+		os.Remove(dir)
+		// act
+		err := repo.Ping(context.Background())
+		// assert
+		assert.Error(t, err)
+	})
+}
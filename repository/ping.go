@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// PingFile checks that the directory holding a file-backed store still
+// accepts the temporary files every save goes through.
+func PingFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".ping-*")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	tmp.Close()
+	return os.Remove(tmp.Name())
+}
@@ -1,13 +1,77 @@
 package repository
 
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrWalletNotFound = errors.New("wallet not found")
+	ErrEventNotFound  = errors.New("event not found")
+	ErrInvalidCursor  = errors.New("invalid cursor")
+	// ErrVersionConflict means the wallet changed since it was read; the
+	// caller should reload it and try again.
+	ErrVersionConflict = errors.New("wallet version conflict")
+	// ErrUnavailable means the storage could not be reached and nothing was
+	// written, so the call may be retried whatever it was.
+	ErrUnavailable = errors.New("storage unavailable")
+)
+
+const (
+	StatusActive = "ACTIVE"
+	StatusFrozen = "FROZEN"
+	StatusClosed = "CLOSED"
+)
+
 type Wallet struct {
-	ID      uint64  `json:"id" `
-	Name    string  `json:"name" validate:"required" `
-	Balance float32 `json:"balance" validate:"required,number"`
+	ID        uint64    `json:"id" `
+	Name      string    `json:"name" validate:"required" `
+	Balance   float32   `json:"balance" validate:"required,number"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	// Version counts the events in the wallet's stream. Event-sourced storage
+	// rejects writes whose Version is not the current one; other storage
+	// leaves it at 0.
+	Version uint64 `json:"version"`
+}
+
+// Event is an outbox record. It is stored in the same write as the wallet
+// change it describes and stays pending until a relay has published it.
+type Event struct {
+	ID          uint64          `json:"id"`
+	Type        string          `json:"type"`
+	WalletID    uint64          `json:"wallet_id"`
+	Payload     json.RawMessage `json:"payload"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	PublishedAt *time.Time      `json:"published_at,omitempty"`
+}
+
+// BalanceSnapshot records a wallet's balance right after the event EventID,
+// so a historical balance can be replayed from it instead of from the
+// wallet's first event.
+type BalanceSnapshot struct {
+	WalletID   uint64    `json:"wallet_id"`
+	EventID    uint64    `json:"event_id"`
+	Balance    float32   `json:"balance"`
+	OccurredAt time.Time `json:"occurred_at"`
 }
 
+// WalletRepository implementations return ctx.Err() instead of doing any
+// work once ctx has ended, including after waiting for a lock, so a write
+// whose deadline passed never lands.
 type WalletRepository interface {
-	Get(id uint64) (*Wallet, error)
-	Create(wallet *Wallet) error
-	Update(id uint64, wallet *Wallet) error
+	Get(ctx context.Context, id uint64) (*Wallet, error)
+	Create(ctx context.Context, wallet *Wallet, events ...Event) error
+	Update(ctx context.Context, id uint64, wallet *Wallet, events ...Event) error
+	UpdateMany(ctx context.Context, wallets []*Wallet, events ...Event) error
+	List(ctx context.Context, filter WalletFilter, sort WalletSort, cursor string, limit int) ([]Wallet, string, error)
+	Events(ctx context.Context, walletID uint64) ([]Event, error)
+	EventsSince(ctx context.Context, walletID uint64, afterID uint64) ([]Event, error)
+	SaveBalanceSnapshot(ctx context.Context, snapshot BalanceSnapshot) error
+	BalanceSnapshotAt(ctx context.Context, walletID uint64, at time.Time) (BalanceSnapshot, error)
+	PendingEvents(ctx context.Context, limit int) ([]Event, error)
+	MarkEventPublished(ctx context.Context, id uint64) error
+	Ping(ctx context.Context) error
 }
@@ -1,6 +1,11 @@
 package repository
 
-import "github.com/stretchr/testify/mock"
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
 
 type walletRepositoryMock struct {
 	mock.Mock
@@ -10,17 +15,62 @@ func NewWalletRepositoryMock() *walletRepositoryMock {
 	return &walletRepositoryMock{}
 }
 
-func (m *walletRepositoryMock) Get(id uint64) (*Wallet, error) {
+func (m *walletRepositoryMock) Get(ctx context.Context, id uint64) (*Wallet, error) {
 	c := m.Called(id)
 	return c.Get(0).(*Wallet), c.Error(1)
 }
 
-func (m *walletRepositoryMock) Create(wallet *Wallet) error {
+func (m *walletRepositoryMock) Create(ctx context.Context, wallet *Wallet, events ...Event) error {
 	c := m.Called(wallet)
 	return c.Error(0)
 }
 
-func (m *walletRepositoryMock) Update(id uint64, wallet *Wallet) error {
+func (m *walletRepositoryMock) Update(ctx context.Context, id uint64, wallet *Wallet, events ...Event) error {
+	c := m.Called(id)
+	return c.Error(0)
+}
+
+func (m *walletRepositoryMock) UpdateMany(ctx context.Context, wallets []*Wallet, events ...Event) error {
+	c := m.Called(wallets)
+	return c.Error(0)
+}
+
+func (m *walletRepositoryMock) List(ctx context.Context, filter WalletFilter, sort WalletSort, cursor string, limit int) ([]Wallet, string, error) {
+	c := m.Called(filter, sort, cursor, limit)
+	return c.Get(0).([]Wallet), c.String(1), c.Error(2)
+}
+
+func (m *walletRepositoryMock) Events(ctx context.Context, walletID uint64) ([]Event, error) {
+	c := m.Called(walletID)
+	return c.Get(0).([]Event), c.Error(1)
+}
+
+func (m *walletRepositoryMock) EventsSince(ctx context.Context, walletID uint64, afterID uint64) ([]Event, error) {
+	c := m.Called(walletID, afterID)
+	return c.Get(0).([]Event), c.Error(1)
+}
+
+func (m *walletRepositoryMock) SaveBalanceSnapshot(ctx context.Context, snapshot BalanceSnapshot) error {
+	c := m.Called(snapshot)
+	return c.Error(0)
+}
+
+func (m *walletRepositoryMock) BalanceSnapshotAt(ctx context.Context, walletID uint64, at time.Time) (BalanceSnapshot, error) {
+	c := m.Called(walletID, at)
+	return c.Get(0).(BalanceSnapshot), c.Error(1)
+}
+
+func (m *walletRepositoryMock) PendingEvents(ctx context.Context, limit int) ([]Event, error) {
+	c := m.Called(limit)
+	return c.Get(0).([]Event), c.Error(1)
+}
+
+func (m *walletRepositoryMock) MarkEventPublished(ctx context.Context, id uint64) error {
 	c := m.Called(id)
 	return c.Error(0)
 }
+
+func (m *walletRepositoryMock) Ping(ctx context.Context) error {
+	c := m.Called()
+	return c.Error(0)
+}
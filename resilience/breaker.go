@@ -0,0 +1,119 @@
+package resilience
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the circuit breaker state. Its value is what the state gauge
+// reports.
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "open"
+	}
+}
+
+// breaker opens after threshold failures in a row and then refuses calls
+// for cooldown. After that it lets a single probe through: success closes
+// it again, failure keeps it open for another cooldown.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	onChange  func(from, to State)
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow reports whether a call may go to storage. It must be followed by
+// record once the call returns.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		return true
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.set(StateHalfOpen)
+	}
+	if b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *breaker) record(class Class) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	probe := b.state == StateHalfOpen && b.probing
+	if probe {
+		b.probing = false
+	}
+	switch {
+	case class == Ignored:
+	case class == Healthy:
+		b.failures = 0
+		if probe {
+			b.set(StateClosed)
+		}
+	case probe:
+		b.trip()
+	case b.state == StateClosed:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.trip()
+		}
+	}
+}
+
+func (b *breaker) trip() {
+	b.failures = 0
+	b.openedAt = b.now()
+	b.set(StateOpen)
+}
+
+func (b *breaker) set(state State) {
+	if state == b.state {
+		return
+	}
+	from := b.state
+	b.state = state
+	if b.onChange != nil {
+		b.onChange(from, state)
+	}
+}
+
+// current reports an open breaker whose cooldown has passed as half-open,
+// since the next call will probe storage.
+func (b *breaker) current() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return StateHalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,300 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotest/repository"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	DefaultAttempts         = 3
+	DefaultBaseDelay        = 20 * time.Millisecond
+	DefaultMaxDelay         = 500 * time.Millisecond
+	DefaultFailureThreshold = 5
+	DefaultCooldown         = 10 * time.Second
+)
+
+// ErrCircuitOpen is returned without calling storage while the breaker is
+// open.
+var ErrCircuitOpen = fmt.Errorf("circuit breaker open: %w", repository.ErrUnavailable)
+
+// Class says what a repository error tells about storage.
+type Class int
+
+const (
+	// Healthy is a success or an answer from working storage, such as a
+	// missing wallet or a version conflict. It is never retried.
+	Healthy Class = iota
+	// Ignored says nothing about storage: the caller's context ended.
+	Ignored
+	// Failure is an error nobody knows to be temporary. It counts against
+	// the breaker but is not retried.
+	Failure
+	// Transient may pass on a retry, but the write may also have landed, so
+	// only idempotent calls retry it.
+	Transient
+	// Unavailable storage did nothing, so every call may retry it.
+	Unavailable
+)
+
+// Classify is the default classifier. Errors that report themselves as
+// temporary or as timeouts, as network and syscall errors do, are
+// transient; repository.ErrUnavailable is unavailable.
+func Classify(err error) Class {
+	var temporary interface{ Temporary() bool }
+	var timeout interface{ Timeout() bool }
+	switch {
+	case err == nil,
+		errors.Is(err, repository.ErrWalletNotFound),
+		errors.Is(err, repository.ErrEventNotFound),
+		errors.Is(err, repository.ErrInvalidCursor),
+		errors.Is(err, repository.ErrVersionConflict):
+		return Healthy
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return Ignored
+	case errors.Is(err, repository.ErrUnavailable):
+		return Unavailable
+	case errors.As(err, &temporary) && temporary.Temporary(),
+		errors.As(err, &timeout) && timeout.Timeout():
+		return Transient
+	}
+	return Failure
+}
+
+// walletRepository retries failed calls to next and stops calling it while
+// it keeps failing. Reads, MarkEventPublished and SaveBalanceSnapshot are
+// idempotent and retry any transient error; Create, Update and UpdateMany
+// only retry when storage is unavailable, since repeating a write that did
+// land would apply it twice. The file-backed stores report a failed save as
+// unavailable because they undo the write in memory first; a store that
+// keeps any part of a failed write must not.
+type walletRepository struct {
+	next      repository.WalletRepository
+	attempts  int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	classify  func(err error) Class
+	onRetry   func(operation string, err error)
+	breaker   *breaker
+}
+
+type Option func(r *walletRepository)
+
+func WithAttempts(attempts int) Option {
+	return func(r *walletRepository) {
+		if attempts > 0 {
+			r.attempts = attempts
+		}
+	}
+}
+
+// WithBackoff sets the delay before the first retry, doubled for each
+// further one up to max. Each delay is jittered down by up to half.
+func WithBackoff(base, max time.Duration) Option {
+	return func(r *walletRepository) {
+		if base > 0 && max >= base {
+			r.baseDelay, r.maxDelay = base, max
+		}
+	}
+}
+
+// WithBreaker opens the breaker after threshold failed calls in a row and
+// keeps it open for cooldown.
+func WithBreaker(threshold int, cooldown time.Duration) Option {
+	return func(r *walletRepository) {
+		if threshold > 0 {
+			r.breaker.threshold = threshold
+		}
+		if cooldown > 0 {
+			r.breaker.cooldown = cooldown
+		}
+	}
+}
+
+func WithClassifier(classify func(err error) Class) Option {
+	return func(r *walletRepository) {
+		r.classify = classify
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(r *walletRepository) {
+		r.breaker.now = now
+	}
+}
+
+// WithRetryHook calls hook before each retry with the error being retried.
+func WithRetryHook(hook func(operation string, err error)) Option {
+	return func(r *walletRepository) {
+		r.onRetry = hook
+	}
+}
+
+// WithStateHook calls hook on every breaker state change. It runs under the
+// breaker's lock and must not call the repository.
+func WithStateHook(hook func(from, to State)) Option {
+	return func(r *walletRepository) {
+		r.breaker.onChange = hook
+	}
+}
+
+func NewWalletRepository(next repository.WalletRepository, opts ...Option) *walletRepository {
+	r := &walletRepository{
+		next:      next,
+		attempts:  DefaultAttempts,
+		baseDelay: DefaultBaseDelay,
+		maxDelay:  DefaultMaxDelay,
+		classify:  Classify,
+		onRetry:   func(string, error) {},
+		breaker: &breaker{
+			threshold: DefaultFailureThreshold,
+			cooldown:  DefaultCooldown,
+			now:       time.Now,
+		},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// State returns the breaker state.
+func (r *walletRepository) State() State {
+	return r.breaker.current()
+}
+
+// Check fails while the breaker is open, for readiness probes. Once the
+// cooldown has passed it succeeds again, so traffic returns and can probe
+// storage.
+func (r *walletRepository) Check(ctx context.Context) error {
+	if r.breaker.current() == StateOpen {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// do runs call until it succeeds, fails in a way that is not worth
+// retrying, runs out of attempts or the breaker opens. Backoff waits end
+// with ctx.
+func (r *walletRepository) do(ctx context.Context, operation string, idempotent bool, call func() error) error {
+	for attempt := 1; ; attempt++ {
+		if !r.breaker.allow() {
+			return ErrCircuitOpen
+		}
+		err := call()
+		class := r.classify(err)
+		r.breaker.record(class)
+
+		retry := class == Unavailable || class == Transient && idempotent
+		if !retry || attempt == r.attempts {
+			return err
+		}
+		r.onRetry(operation, err)
+
+		timer := time.NewTimer(r.backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func (r *walletRepository) backoff(attempt int) time.Duration {
+	delay := r.maxDelay
+	if shift := attempt - 1; shift < 32 && r.baseDelay<<shift < r.maxDelay {
+		delay = r.baseDelay << shift
+	}
+	if half := delay / 2; half > 0 {
+		delay -= rand.N(half)
+	}
+	return delay
+}
+
+func (r *walletRepository) Get(ctx context.Context, id uint64) (wallet *repository.Wallet, err error) {
+	err = r.do(ctx, "Get", true, func() error {
+		wallet, err = r.next.Get(ctx, id)
+		return err
+	})
+	return wallet, err
+}
+
+func (r *walletRepository) Create(ctx context.Context, wallet *repository.Wallet, events ...repository.Event) error {
+	return r.do(ctx, "Create", false, func() error {
+		return r.next.Create(ctx, wallet, events...)
+	})
+}
+
+func (r *walletRepository) Update(ctx context.Context, id uint64, wallet *repository.Wallet, events ...repository.Event) error {
+	return r.do(ctx, "Update", false, func() error {
+		return r.next.Update(ctx, id, wallet, events...)
+	})
+}
+
+func (r *walletRepository) UpdateMany(ctx context.Context, wallets []*repository.Wallet, events ...repository.Event) error {
+	return r.do(ctx, "UpdateMany", false, func() error {
+		return r.next.UpdateMany(ctx, wallets, events...)
+	})
+}
+
+func (r *walletRepository) List(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) (wallets []repository.Wallet, next string, err error) {
+	err = r.do(ctx, "List", true, func() error {
+		wallets, next, err = r.next.List(ctx, filter, sort, cursor, limit)
+		return err
+	})
+	return wallets, next, err
+}
+
+func (r *walletRepository) Events(ctx context.Context, walletID uint64) (events []repository.Event, err error) {
+	err = r.do(ctx, "Events", true, func() error {
+		events, err = r.next.Events(ctx, walletID)
+		return err
+	})
+	return events, err
+}
+
+func (r *walletRepository) EventsSince(ctx context.Context, walletID uint64, afterID uint64) (events []repository.Event, err error) {
+	err = r.do(ctx, "EventsSince", true, func() error {
+		events, err = r.next.EventsSince(ctx, walletID, afterID)
+		return err
+	})
+	return events, err
+}
+
+func (r *walletRepository) SaveBalanceSnapshot(ctx context.Context, snapshot repository.BalanceSnapshot) error {
+	return r.do(ctx, "SaveBalanceSnapshot", true, func() error {
+		return r.next.SaveBalanceSnapshot(ctx, snapshot)
+	})
+}
+
+func (r *walletRepository) BalanceSnapshotAt(ctx context.Context, walletID uint64, at time.Time) (snapshot repository.BalanceSnapshot, err error) {
+	err = r.do(ctx, "BalanceSnapshotAt", true, func() error {
+		snapshot, err = r.next.BalanceSnapshotAt(ctx, walletID, at)
+		return err
+	})
+	return snapshot, err
+}
+
+func (r *walletRepository) PendingEvents(ctx context.Context, limit int) (events []repository.Event, err error) {
+	err = r.do(ctx, "PendingEvents", true, func() error {
+		events, err = r.next.PendingEvents(ctx, limit)
+		return err
+	})
+	return events, err
+}
+
+func (r *walletRepository) MarkEventPublished(ctx context.Context, id uint64) error {
+	return r.do(ctx, "MarkEventPublished", true, func() error {
+		return r.next.MarkEventPublished(ctx, id)
+	})
+}
+
+func (r *walletRepository) Ping(ctx context.Context) error {
+	return r.do(ctx, "Ping", true, func() error {
+		return r.next.Ping(ctx)
+	})
+}
@@ -0,0 +1,265 @@
+// go:build unit
+package resilience_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotest/repository"
+	"gotest/resilience"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var errTransient = &os.PathError{Op: "write", Path: "wallets.json", Err: syscall.EAGAIN}
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func fast(opts ...resilience.Option) []resilience.Option {
+	return append([]resilience.Option{resilience.WithBackoff(time.Microsecond, time.Microsecond)}, opts...)
+}
+
+func TestClassify(t *testing.T) {
+	for _, tc := range []struct {
+		err   error
+		class resilience.Class
+	}{
+		{nil, resilience.Healthy},
+		{repository.ErrVersionConflict, resilience.Healthy},
+		{fmt.Errorf("list: %w", repository.ErrInvalidCursor), resilience.Healthy},
+		{context.Canceled, resilience.Ignored},
+		{context.DeadlineExceeded, resilience.Ignored},
+		{fmt.Errorf("dial: %w", repository.ErrUnavailable), resilience.Unavailable},
+		{errTransient, resilience.Transient},
+		{&os.PathError{Op: "open", Path: "wallets.json", Err: syscall.EMFILE}, resilience.Transient},
+		{&os.PathError{Op: "write", Path: "wallets.json", Err: syscall.ENOSPC}, resilience.Failure},
+		{errors.New("database down"), resilience.Failure},
+	} {
+		t.Run(fmt.Sprint(tc.err), func(t *testing.T) {
+			// act
+			class := resilience.Classify(tc.err)
+			// assert
+			assert.Equal(t, tc.class, class)
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	wallet := repository.Wallet{ID: 1, Name: "John Doe", Balance: 100}
+
+	t.Run("Read Retries Transient Errors", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return((*repository.Wallet)(nil), errTransient).Once()
+		next.On("Get", uint64(1)).Return(&wallet, nil)
+		retried := []string{}
+		repo := resilience.NewWalletRepository(next, fast(resilience.WithRetryHook(func(operation string, err error) {
+			retried = append(retried, operation)
+		}))...)
+		// act
+		result, err := repo.Get(context.Background(), 1)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, wallet, *result)
+		assert.Equal(t, []string{"Get"}, retried)
+	})
+
+	t.Run("Gives Up After Attempts", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return((*repository.Wallet)(nil), errTransient)
+		repo := resilience.NewWalletRepository(next, fast(resilience.WithAttempts(4))...)
+		// act
+		_, err := repo.Get(context.Background(), 1)
+		// assert
+		assert.ErrorIs(t, err, syscall.EAGAIN)
+		next.AssertNumberOfCalls(t, "Get", 4)
+	})
+
+	t.Run("Write Does Not Retry Transient Errors", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("Update", uint64(1)).Return(errTransient)
+		repo := resilience.NewWalletRepository(next, fast()...)
+		// act
+		err := repo.Update(context.Background(), 1, &wallet)
+		// assert
+		assert.ErrorIs(t, err, syscall.EAGAIN)
+		next.AssertNumberOfCalls(t, "Update", 1)
+	})
+
+	t.Run("Write Retries Unavailable Storage", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("Create", mock.Anything).Return(repository.ErrUnavailable).Once()
+		next.On("Create", mock.Anything).Return(nil)
+		repo := resilience.NewWalletRepository(next, fast()...)
+		// act
+		err := repo.Create(context.Background(), &repository.Wallet{Name: "John Doe"})
+		// assert
+		assert.NoError(t, err)
+		next.AssertNumberOfCalls(t, "Create", 2)
+	})
+
+	t.Run("Write Retries Unwritable File Store", func(t *testing.T) {
+		// arrange
+		dir := filepath.Join(t.TempDir(), "data")
+		next, _ := repository.NewFileWalletRepository(filepath.Join(dir, "wallets.json"))
+		repo := resilience.NewWalletRepository(next, fast(resilience.WithRetryHook(func(operation string, err error) {
+			os.Mkdir(dir, 0o755)
+		}))...)
+		created := repository.Wallet{Name: "John Doe"}
+		// act
+		err := repo.Create(context.Background(), &created, repository.Event{Type: "WalletOpened"})
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), created.ID)
+		events, _ := repo.PendingEvents(context.Background(), 10)
+		assert.Len(t, events, 1)
+	})
+
+	t.Run("Idempotent Write Retries Transient Errors", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("MarkEventPublished", uint64(7)).Return(errTransient).Once()
+		next.On("MarkEventPublished", uint64(7)).Return(nil)
+		repo := resilience.NewWalletRepository(next, fast()...)
+		// act
+		err := repo.MarkEventPublished(context.Background(), 7)
+		// assert
+		assert.NoError(t, err)
+		next.AssertNumberOfCalls(t, "MarkEventPublished", 2)
+	})
+
+	t.Run("Other Errors Are Not Retried", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("Update", uint64(1)).Return(repository.ErrVersionConflict)
+		next.On("Get", uint64(1)).Return((*repository.Wallet)(nil), errors.New("database down"))
+		repo := resilience.NewWalletRepository(next, fast()...)
+		// act
+		updateErr := repo.Update(context.Background(), 1, &wallet)
+		_, getErr := repo.Get(context.Background(), 1)
+		// assert
+		assert.ErrorIs(t, updateErr, repository.ErrVersionConflict)
+		assert.EqualError(t, getErr, "database down")
+		next.AssertNumberOfCalls(t, "Update", 1)
+		next.AssertNumberOfCalls(t, "Get", 1)
+	})
+
+	t.Run("Backoff Ends With Context", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return((*repository.Wallet)(nil), errTransient)
+		repo := resilience.NewWalletRepository(next, resilience.WithBackoff(time.Hour, time.Hour))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		// act
+		_, err := repo.Get(ctx, 1)
+		// assert
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+		next.AssertNumberOfCalls(t, "Get", 1)
+	})
+}
+
+func TestBreaker(t *testing.T) {
+	down := errors.New("database down")
+
+	t.Run("Opens After Threshold", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return((*repository.Wallet)(nil), down)
+		changes := []string{}
+		repo := resilience.NewWalletRepository(next, resilience.WithBreaker(2, time.Minute), resilience.WithStateHook(func(from, to resilience.State) {
+			changes = append(changes, from.String()+" -> "+to.String())
+		}))
+		// act
+		repo.Get(context.Background(), 1)
+		repo.Get(context.Background(), 1)
+		_, err := repo.Get(context.Background(), 1)
+		// assert
+		assert.ErrorIs(t, err, resilience.ErrCircuitOpen)
+		assert.ErrorIs(t, err, repository.ErrUnavailable)
+		next.AssertNumberOfCalls(t, "Get", 2)
+		assert.Equal(t, resilience.StateOpen, repo.State())
+		assert.ErrorIs(t, repo.Check(context.Background()), resilience.ErrCircuitOpen)
+		assert.Equal(t, []string{"closed -> open"}, changes)
+	})
+
+	t.Run("Successful Probe Closes", func(t *testing.T) {
+		// arrange
+		c := &clock{now: time.Unix(1700000000, 0)}
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return((*repository.Wallet)(nil), down).Once()
+		next.On("Get", uint64(1)).Return(&repository.Wallet{ID: 1}, nil)
+		repo := resilience.NewWalletRepository(next, resilience.WithBreaker(1, time.Minute), resilience.WithClock(c.Now))
+		repo.Get(context.Background(), 1)
+		// act
+		c.now = c.now.Add(time.Minute)
+		probing := repo.State()
+		checkErr := repo.Check(context.Background())
+		_, err := repo.Get(context.Background(), 1)
+		// assert
+		assert.Equal(t, resilience.StateHalfOpen, probing)
+		assert.NoError(t, checkErr)
+		assert.NoError(t, err)
+		assert.Equal(t, resilience.StateClosed, repo.State())
+	})
+
+	t.Run("Failed Probe Reopens", func(t *testing.T) {
+		// arrange
+		c := &clock{now: time.Unix(1700000000, 0)}
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return((*repository.Wallet)(nil), errTransient)
+		repo := resilience.NewWalletRepository(next, fast(resilience.WithBreaker(1, time.Minute), resilience.WithClock(c.Now))...)
+		repo.Get(context.Background(), 1)
+		// act
+		c.now = c.now.Add(time.Minute)
+		_, err := repo.Get(context.Background(), 1)
+		// assert
+		assert.ErrorIs(t, err, resilience.ErrCircuitOpen)
+		next.AssertNumberOfCalls(t, "Get", 2)
+		assert.Equal(t, resilience.StateOpen, repo.State())
+	})
+
+	t.Run("Answers Reset Failures", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return((*repository.Wallet)(nil), down)
+		next.On("Update", uint64(1)).Return(repository.ErrVersionConflict)
+		repo := resilience.NewWalletRepository(next, resilience.WithBreaker(2, time.Minute))
+		// act
+		repo.Get(context.Background(), 1)
+		repo.Update(context.Background(), 1, &repository.Wallet{ID: 1})
+		repo.Get(context.Background(), 1)
+		// assert
+		assert.Equal(t, resilience.StateClosed, repo.State())
+	})
+
+	t.Run("Context Errors Do Not Count", func(t *testing.T) {
+		// arrange
+		next := repository.NewWalletRepositoryMock()
+		next.On("Get", uint64(1)).Return((*repository.Wallet)(nil), context.DeadlineExceeded)
+		repo := resilience.NewWalletRepository(next, resilience.WithBreaker(1, time.Minute))
+		// act
+		repo.Get(context.Background(), 1)
+		repo.Get(context.Background(), 1)
+		// assert
+		next.AssertNumberOfCalls(t, "Get", 2)
+		assert.Equal(t, resilience.StateClosed, repo.State())
+	})
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gotest/event"
+	"gotest/repository"
+	"sync"
+)
+
+const (
+	BatchAtomic     = "atomic"
+	BatchBestEffort = "best_effort"
+
+	OperationDeposit  = "deposit"
+	OperationWithdraw = "withdraw"
+
+	MaxBatchSize = 10000
+
+	ResultOK      = "ok"
+	ResultFailed  = "failed"
+	ResultAborted = "aborted"
+)
+
+type Operation struct {
+	WalletID uint64  `json:"wallet_id"`
+	Type     string  `json:"type"`
+	Amount   float32 `json:"amount"`
+}
+
+type OperationResult struct {
+	Index    int       `json:"index"`
+	WalletID uint64    `json:"wallet_id"`
+	Status   string    `json:"status"`
+	Before   float32   `json:"balance_before"`
+	Balance  float32   `json:"balance"`
+	Code     ErrorCode `json:"code,omitempty"`
+	Message  string    `json:"message,omitempty"`
+}
+
+func (r *OperationResult) fail(err error) {
+	var e WalletError
+	if !errors.As(err, &e) {
+		e = ErrUnexpected
+	}
+	r.Status = ResultFailed
+	r.Code = e.Code
+	r.Message = e.Message
+}
+
+func validateOperation(op Operation, limits Limits) error {
+	if op.Type != OperationDeposit && op.Type != OperationWithdraw {
+		return NewErrorBadRequest("INVALID OPERATION TYPE")
+	}
+	if !validAmount(op.Amount) {
+		return NewErrorBadRequest("INVALID AMOUNT")
+	}
+
+	limit := limits.MaxDeposit
+	if op.Type == OperationWithdraw {
+		limit = limits.MaxWithdrawal
+	}
+	if overLimit(limit, op.Amount) {
+		return NewErrorLimitExceeded()
+	}
+	return nil
+}
+
+func (s walletService) Batch(ctx context.Context, ops []Operation, mode string) ([]OperationResult, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Batch)
+	defer cancel()
+
+	if len(ops) == 0 || len(ops) > MaxBatchSize {
+		return nil, NewErrorBadRequest("INVALID BATCH SIZE")
+	}
+
+	switch mode {
+	case BatchAtomic:
+		return s.batchAtomic(ctx, ops)
+	case BatchBestEffort:
+		return s.batchBestEffort(ctx, ops), nil
+	default:
+		return nil, NewErrorBadRequest("INVALID BATCH MODE")
+	}
+}
+
+// batchAtomic applies every operation to in-memory copies and writes them
+// with a single UpdateMany, so either all operations land or none do.
+func (s walletService) batchAtomic(ctx context.Context, ops []Operation) ([]OperationResult, error) {
+	results := make([]OperationResult, len(ops))
+	walletIDs := []uint64{}
+	seen := map[uint64]bool{}
+	for i, op := range ops {
+		results[i] = OperationResult{Index: i, WalletID: op.WalletID, Status: ResultAborted}
+		if !seen[op.WalletID] {
+			seen[op.WalletID] = true
+			walletIDs = append(walletIDs, op.WalletID)
+		}
+	}
+
+	limits := s.policy().Limits
+	loaded := s.loadWallets(ctx, walletIDs)
+	wallets := []*repository.Wallet{}
+	for _, id := range walletIDs {
+		if loaded[id].wallet != nil {
+			wallets = append(wallets, loaded[id].wallet)
+		}
+	}
+
+	records := []repository.Event{}
+	for i, op := range ops {
+		err := validateOperation(op, limits)
+		if err == nil {
+			err = loaded[op.WalletID].err
+		}
+		if err == nil {
+			wallet := loaded[op.WalletID].wallet
+			results[i].Before = wallet.Balance
+			var record repository.Event
+			record, err = apply(wallet, op)
+			records = append(records, record)
+			results[i].Balance = wallet.Balance
+		}
+		if err != nil {
+			unapplied(results)
+			results[i].fail(err)
+			return results, nil
+		}
+	}
+
+	if err := s.walletRepo.UpdateMany(ctx, wallets, records...); err != nil {
+		e := s.writeError(ctx, err)
+		unapplied(results)
+		for i := range results {
+			results[i].fail(e)
+		}
+		return results, nil
+	}
+
+	for i := range results {
+		results[i].Status = ResultOK
+	}
+	return results, nil
+}
+
+// unapplied clears the balances worked out for results that were never
+// saved.
+func unapplied(results []OperationResult) {
+	for i := range results {
+		results[i].Before = 0
+		results[i].Balance = 0
+	}
+}
+
+// batchBestEffort runs each wallet's operations in order on one worker and
+// spreads different wallets over at most batchConcurrency workers.
+// Operations not yet started when ctx ends are aborted.
+func (s walletService) batchBestEffort(ctx context.Context, ops []Operation) []OperationResult {
+	results := make([]OperationResult, len(ops))
+	groups := map[uint64][]int{}
+	walletIDs := []uint64{}
+	for i, op := range ops {
+		results[i] = OperationResult{Index: i, WalletID: op.WalletID}
+		if _, ok := groups[op.WalletID]; !ok {
+			walletIDs = append(walletIDs, op.WalletID)
+		}
+		groups[op.WalletID] = append(groups[op.WalletID], i)
+	}
+
+	jobs := make(chan []int)
+	var wg sync.WaitGroup
+	for w := 0; w < s.batchConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for indexes := range jobs {
+				for _, i := range indexes {
+					results[i] = s.runOperation(ctx, i, ops[i])
+				}
+			}
+		}()
+	}
+
+	for _, id := range walletIDs {
+		jobs <- groups[id]
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func (s walletService) runOperation(ctx context.Context, index int, op Operation) OperationResult {
+	result := OperationResult{Index: index, WalletID: op.WalletID}
+	if e, ok := contextError(ctx.Err()); ok {
+		result.fail(e)
+		result.Status = ResultAborted
+		return result
+	}
+	if err := validateOperation(op, s.policy().Limits); err != nil {
+		result.fail(err)
+		return result
+	}
+
+	var balance float32
+	var err error
+	if op.Type == OperationDeposit {
+		balance, err = s.Deposit(ctx, op.WalletID, op.Amount)
+		result.Before = balance - op.Amount
+	} else {
+		balance, err = s.Withdraw(ctx, op.WalletID, op.Amount)
+		result.Before = balance + op.Amount
+	}
+	if err != nil {
+		result.Before = 0
+		result.fail(err)
+		return result
+	}
+
+	result.Status = ResultOK
+	result.Balance = balance
+	return result
+}
+
+type loadedWallet struct {
+	wallet *repository.Wallet
+	err    error
+}
+
+func (s walletService) loadWallets(ctx context.Context, ids []uint64) map[uint64]loadedWallet {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.batchConcurrency)
+	loaded := map[uint64]loadedWallet{}
+	for _, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id uint64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			wallet, err := s.GetAccount(ctx, id)
+			mu.Lock()
+			loaded[id] = loadedWallet{wallet: wallet, err: err}
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+	return loaded
+}
+
+func apply(wallet *repository.Wallet, op Operation) (repository.Event, error) {
+	if wallet.Status == repository.StatusFrozen {
+		return repository.Event{}, NewErrorWalletFrozen()
+	}
+	if op.Type == OperationDeposit {
+		wallet.Balance = wallet.Balance + op.Amount
+		return event.Encode(event.FundsDeposited{WalletID: wallet.ID, Amount: op.Amount, Balance: wallet.Balance})
+	}
+
+	if wallet.Balance < op.Amount {
+		return repository.Event{}, NewErrorNotEnoughMoney()
+	}
+	wallet.Balance = wallet.Balance - op.Amount
+	return event.Encode(event.FundsWithdrawn{WalletID: wallet.ID, Amount: op.Amount, Balance: wallet.Balance})
+}
@@ -0,0 +1,161 @@
+// go:build unit
+package service_test
+
+import (
+	"context"
+	"errors"
+	"gotest/repository"
+	"gotest/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newBatchService(balances ...float32) service.WalletService {
+	repo := repository.NewMemoryWalletRepository()
+	serv := service.NewWalletService(repo, service.WithBatchConcurrency(2))
+	for _, balance := range balances {
+		serv.OpenAccount(context.Background(), &repository.Wallet{Name: "John Doe", Balance: balance})
+	}
+	return serv
+}
+
+func statuses(results []service.OperationResult) []string {
+	result := []string{}
+	for _, r := range results {
+		result = append(result, r.Status)
+	}
+	return result
+}
+
+func TestBatchAtomic(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		serv := newBatchService(1000, 0)
+		ops := []service.Operation{
+			{WalletID: 1, Type: service.OperationWithdraw, Amount: 300},
+			{WalletID: 2, Type: service.OperationDeposit, Amount: 300},
+			{WalletID: 1, Type: service.OperationWithdraw, Amount: 700},
+		}
+		// act
+		results, err := serv.Batch(context.Background(), ops, service.BatchAtomic)
+		// assert
+		assert.Nil(t, err)
+		assert.Equal(t, []string{service.ResultOK, service.ResultOK, service.ResultOK}, statuses(results))
+		assert.Equal(t, float32(0), results[2].Balance)
+		first, _ := serv.GetAccount(context.Background(), 1)
+		second, _ := serv.GetAccount(context.Background(), 2)
+		assert.Equal(t, float32(0), first.Balance)
+		assert.Equal(t, float32(300), second.Balance)
+	})
+
+	t.Run("Nothing Applied On Failure", func(t *testing.T) {
+		// arrange
+		serv := newBatchService(1000, 0)
+		ops := []service.Operation{
+			{WalletID: 2, Type: service.OperationDeposit, Amount: 300},
+			{WalletID: 1, Type: service.OperationWithdraw, Amount: 2000},
+			{WalletID: 3, Type: service.OperationDeposit, Amount: 300},
+		}
+		// act
+		results, err := serv.Batch(context.Background(), ops, service.BatchAtomic)
+		// assert
+		assert.Nil(t, err)
+		assert.Equal(t, []string{service.ResultAborted, service.ResultFailed, service.ResultAborted}, statuses(results))
+		assert.Equal(t, service.CodeInsufficientFunds, results[1].Code)
+		assert.Equal(t, "NOT ENOUGH MONEY", results[1].Message)
+		assert.Equal(t, float32(0), results[0].Before)
+		assert.Equal(t, float32(0), results[0].Balance)
+		second, _ := serv.GetAccount(context.Background(), 2)
+		assert.Equal(t, float32(0), second.Balance)
+	})
+
+	t.Run("Error Unexpected", func(t *testing.T) {
+		// arrange
+		wallet := repository.Wallet{ID: 1, Name: "John Doe", Balance: 1000}
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", uint64(1)).Return(&wallet, nil)
+		repo.On("UpdateMany", mock.Anything).Return(errors.New("database down"))
+		serv := service.NewWalletService(repo)
+		ops := []service.Operation{{WalletID: 1, Type: service.OperationDeposit, Amount: 300}}
+		// act
+		results, err := serv.Batch(context.Background(), ops, service.BatchAtomic)
+		// assert
+		assert.Nil(t, err)
+		assert.Equal(t, service.CodeUnexpected, results[0].Code)
+		assert.Equal(t, float32(0), results[0].Before)
+		assert.Equal(t, float32(0), results[0].Balance)
+	})
+}
+
+func TestBatchBestEffort(t *testing.T) {
+	// arrange
+	serv := newBatchService(1000, 0)
+	ops := []service.Operation{
+		{WalletID: 1, Type: service.OperationWithdraw, Amount: 300},
+		{WalletID: 1, Type: service.OperationWithdraw, Amount: 800},
+		{WalletID: 2, Type: service.OperationDeposit, Amount: 50},
+		{WalletID: 9, Type: service.OperationDeposit, Amount: 50},
+		{WalletID: 2, Type: "refund", Amount: 50},
+		{WalletID: 1, Type: service.OperationDeposit, Amount: 100},
+	}
+	// act
+	results, err := serv.Batch(context.Background(), ops, service.BatchBestEffort)
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		service.ResultOK,
+		service.ResultFailed,
+		service.ResultOK,
+		service.ResultFailed,
+		service.ResultFailed,
+		service.ResultOK,
+	}, statuses(results))
+	assert.Equal(t, service.CodeNotFound, results[3].Code)
+	assert.Equal(t, float32(700), results[0].Balance)
+	assert.Equal(t, float32(800), results[5].Balance)
+	assert.Equal(t, float32(700), results[5].Before)
+}
+
+func TestBatchError(t *testing.T) {
+	t.Run("Invalid Mode", func(t *testing.T) {
+		// arrange
+		serv := newBatchService(1000)
+		// act
+		_, err := serv.Batch(context.Background(), []service.Operation{{WalletID: 1, Type: service.OperationDeposit, Amount: 1}}, "sometimes")
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorBadRequest("INVALID BATCH MODE"))
+	})
+
+	t.Run("Empty Batch", func(t *testing.T) {
+		// arrange
+		serv := newBatchService(1000)
+		// act
+		_, err := serv.Batch(context.Background(), nil, service.BatchAtomic)
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorBadRequest("INVALID BATCH SIZE"))
+	})
+}
+
+func TestBatchBestEffortTimeout(t *testing.T) {
+	// arrange
+	repo := repository.NewMemoryWalletRepository()
+	serv := service.NewWalletService(blockingRepository{repo},
+		service.WithBatchConcurrency(1),
+		service.WithTimeouts(service.Timeouts{Batch: 20 * time.Millisecond}),
+	)
+	serv.OpenAccount(context.Background(), &repository.Wallet{Name: "John Doe", Balance: 1000})
+	ops := []service.Operation{
+		{WalletID: 1, Type: service.OperationDeposit, Amount: 10},
+		{WalletID: 1, Type: service.OperationDeposit, Amount: 20},
+	}
+	// act
+	results, err := serv.Batch(context.Background(), ops, service.BatchBestEffort)
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{service.ResultFailed, service.ResultAborted}, statuses(results))
+	assert.Equal(t, service.CodeTimeout, results[0].Code)
+	assert.Equal(t, service.CodeTimeout, results[1].Code)
+}
@@ -1,38 +1,210 @@
 package service
 
+import (
+	"context"
+	"errors"
+	"gotest/repository"
+	"log/slog"
+)
+
+// ErrorCode classifies a WalletError independently of any transport. Codes
+// are part of the API and must not be renamed.
+type ErrorCode string
+
+const (
+	CodeUnexpected        ErrorCode = "UNEXPECTED"
+	CodeNotFound          ErrorCode = "NOT_FOUND"
+	CodeInvalidRequest    ErrorCode = "INVALID_REQUEST"
+	CodeInvalidParameter  ErrorCode = "INVALID_PARAMETER"
+	CodeInsufficientFunds ErrorCode = "INSUFFICIENT_FUNDS"
+	CodeConflict          ErrorCode = "CONFLICT"
+	CodeTimeout           ErrorCode = "TIMEOUT"
+	CodeCanceled          ErrorCode = "CANCELED"
+	CodeLimitExceeded     ErrorCode = "LIMIT_EXCEEDED"
+	CodeWalletFrozen      ErrorCode = "WALLET_FROZEN"
+	CodeUnavailable       ErrorCode = "UNAVAILABLE"
+	CodeBusy              ErrorCode = "BUSY"
+)
+
+// WalletError is a domain error with a stable Code and a Message safe to
+// show to clients. The cause, if any, is kept for errors.Is and errors.As
+// but never becomes part of Message.
 type WalletError struct {
-	Code    int
+	Code    ErrorCode
 	Message string
+	cause   error
 }
 
+var (
+	ErrUnexpected     = NewErrorWalletUnexpected()
+	ErrWalletNotFound = NewErrorWalletNotFound()
+	ErrNotEnoughMoney = NewErrorNotEnoughMoney()
+	ErrConflict       = NewErrorConflict()
+	ErrTimeout        = NewErrorTimeout()
+	ErrCanceled       = NewErrorCanceled()
+	ErrLimitExceeded  = NewErrorLimitExceeded()
+	ErrWalletFrozen   = NewErrorWalletFrozen()
+	ErrUnavailable    = NewErrorUnavailable()
+	ErrBusy           = NewErrorBusy()
+)
+
 func (e WalletError) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
 	return e.Message
 }
 
-func NewErrorWalletNotFound() WalletError {
-	return WalletError{
-		Code:    404,
-		Message: "WALLET NOT FOUND",
+func (e WalletError) Unwrap() error {
+	return e.cause
+}
+
+// Is matches another WalletError with the same code, and the same message
+// unless target leaves Message empty, so WalletError{Code: CodeNotFound}
+// matches any not-found error. The cause is ignored.
+func (e WalletError) Is(target error) bool {
+	t, ok := target.(WalletError)
+	return ok && t.Code == e.Code && (t.Message == "" || t.Message == e.Message)
+}
+
+// Wrap returns a copy of e caused by err.
+func (e WalletError) Wrap(err error) WalletError {
+	e.cause = err
+	return e
+}
+
+// MessageOf returns the client-facing message of the WalletError in err's
+// chain, hiding anything else behind the unexpected error's message.
+func MessageOf(err error) string {
+	var e WalletError
+	if errors.As(err, &e) {
+		return e.Message
 	}
+	return ErrUnexpected.Message
+}
+
+func NewErrorWalletNotFound() WalletError {
+	return NewErrorNotFound("WALLET NOT FOUND")
 }
 
 func NewErrorWalletUnexpected() WalletError {
 	return WalletError{
-		Code:    500,
+		Code:    CodeUnexpected,
 		Message: "UNEXPECTED ERROR",
 	}
 }
 
 func NewErrorBadRequest(msg string) WalletError {
 	return WalletError{
-		Code:    400,
+		Code:    CodeInvalidRequest,
 		Message: msg,
 	}
 }
 
 func NewErrorUnprocessableEntity() WalletError {
 	return WalletError{
-		Code:    422,
+		Code:    CodeInvalidParameter,
 		Message: "INVALID PARAMETER",
 	}
 }
+
+func NewErrorNotFound(msg string) WalletError {
+	return WalletError{
+		Code:    CodeNotFound,
+		Message: msg,
+	}
+}
+
+func NewErrorNotEnoughMoney() WalletError {
+	return WalletError{
+		Code:    CodeInsufficientFunds,
+		Message: "NOT ENOUGH MONEY",
+	}
+}
+
+func NewErrorConflict() WalletError {
+	return WalletError{
+		Code:    CodeConflict,
+		Message: "CONCURRENT UPDATE",
+	}
+}
+
+func NewErrorTimeout() WalletError {
+	return WalletError{
+		Code:    CodeTimeout,
+		Message: "TIMEOUT",
+	}
+}
+
+func NewErrorCanceled() WalletError {
+	return WalletError{
+		Code:    CodeCanceled,
+		Message: "CANCELED",
+	}
+}
+
+func NewErrorLimitExceeded() WalletError {
+	return WalletError{
+		Code:    CodeLimitExceeded,
+		Message: "AMOUNT OVER LIMIT",
+	}
+}
+
+func NewErrorWalletFrozen() WalletError {
+	return WalletError{
+		Code:    CodeWalletFrozen,
+		Message: "WALLET FROZEN",
+	}
+}
+
+func NewErrorUnavailable() WalletError {
+	return WalletError{
+		Code:    CodeUnavailable,
+		Message: "SERVICE UNAVAILABLE",
+	}
+}
+
+func NewErrorBusy() WalletError {
+	return WalletError{
+		Code:    CodeBusy,
+		Message: "TOO MANY PENDING OPERATIONS",
+	}
+}
+
+// contextError maps err to ErrTimeout or ErrCanceled when it comes from a
+// context that ended.
+func contextError(err error) (WalletError, bool) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout.Wrap(err), true
+	case errors.Is(err, context.Canceled):
+		return ErrCanceled.Wrap(err), true
+	}
+	return WalletError{}, false
+}
+
+// unexpected logs err and wraps it in the generic error clients see, unless
+// the operation simply ran out of time, was canceled or found the storage
+// unavailable.
+func (s walletService) unexpected(ctx context.Context, err error) WalletError {
+	if e, ok := contextError(err); ok {
+		s.logger.WarnContext(ctx, "operation aborted", slog.Any("error", err))
+		return e
+	}
+	if errors.Is(err, repository.ErrUnavailable) {
+		s.logger.WarnContext(ctx, "storage unavailable", slog.Any("error", err))
+		return ErrUnavailable.Wrap(err)
+	}
+	s.logger.ErrorContext(ctx, "unexpected error", slog.Any("error", err))
+	return ErrUnexpected.Wrap(err)
+}
+
+// writeError maps a failed repository write: a version conflict is worth
+// retrying, anything else is unexpected.
+func (s walletService) writeError(ctx context.Context, err error) WalletError {
+	if errors.Is(err, repository.ErrVersionConflict) {
+		s.logger.WarnContext(ctx, "concurrent update", slog.Any("error", err))
+		return ErrConflict.Wrap(err)
+	}
+	return s.unexpected(ctx, err)
+}
@@ -0,0 +1,47 @@
+// go:build unit
+package service_test
+
+import (
+	"errors"
+	"fmt"
+	"gotest/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletError(t *testing.T) {
+	t.Run("Wrap", func(t *testing.T) {
+		// arrange
+		cause := errors.New("database down")
+		// act
+		err := fmt.Errorf("withdraw: %w", service.ErrUnexpected.Wrap(cause))
+		// assert
+		assert.ErrorIs(t, err, cause)
+		assert.ErrorIs(t, err, service.ErrUnexpected)
+		assert.Equal(t, "withdraw: UNEXPECTED ERROR: database down", err.Error())
+		var walletErr service.WalletError
+		assert.True(t, errors.As(err, &walletErr))
+		assert.Equal(t, service.CodeUnexpected, walletErr.Code)
+		assert.Equal(t, "UNEXPECTED ERROR", walletErr.Message)
+	})
+
+	t.Run("Is", func(t *testing.T) {
+		// arrange
+		err := service.NewErrorNotFound("SUBSCRIPTION NOT FOUND")
+		// act
+		// assert
+		assert.ErrorIs(t, err, service.WalletError{Code: service.CodeNotFound})
+		assert.NotErrorIs(t, err, service.ErrWalletNotFound)
+		assert.ErrorIs(t, service.NewErrorWalletNotFound(), service.ErrWalletNotFound)
+		assert.NotErrorIs(t, service.ErrNotEnoughMoney, service.WalletError{Code: service.CodeInvalidRequest})
+	})
+
+	t.Run("Message Of", func(t *testing.T) {
+		// arrange
+		// act
+		// assert
+		assert.Equal(t, "NOT ENOUGH MONEY", service.MessageOf(service.ErrNotEnoughMoney.Wrap(errors.New("balance 10"))))
+		assert.Equal(t, "UNEXPECTED ERROR", service.MessageOf(errors.New("database down")))
+	})
+}
@@ -0,0 +1,42 @@
+package service
+
+import "math"
+
+// Limits caps the amount of a single operation. Zero leaves it uncapped.
+type Limits struct {
+	MaxDeposit    float32
+	MaxWithdrawal float32
+	MaxTransfer   float32
+}
+
+// Fees are flat amounts charged on top of an operation to the wallet money
+// leaves. Only transfers carry a fee so far, paid by the sender.
+type Fees struct {
+	Transfer float32
+}
+
+type Policy struct {
+	Limits Limits
+	Fees   Fees
+}
+
+func overLimit(limit float32, amount float32) bool {
+	return limit > 0 && amount > limit
+}
+
+// validAmount refuses amounts that are not positive or not finite. NaN fails
+// every comparison, so a NaN amount would pass a balance check and stick in
+// the balance for good.
+func validAmount(amount float32) bool {
+	return amount > 0 && !math.IsInf(float64(amount), 1)
+}
+
+// WithPolicy has every operation read its limits and fees from policy when
+// it starts, so they can change while the service runs.
+func WithPolicy(policy func() Policy) Option {
+	return func(s *walletService) {
+		if policy != nil {
+			s.policy = policy
+		}
+	}
+}
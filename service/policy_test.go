@@ -0,0 +1,128 @@
+// go:build unit
+package service_test
+
+import (
+	"context"
+	"gotest/repository"
+	"gotest/service"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPolicyService(policy service.Policy, balances ...float32) service.WalletService {
+	repo := repository.NewMemoryWalletRepository()
+	serv := service.NewWalletService(repo, service.WithPolicy(func() service.Policy { return policy }))
+	for _, balance := range balances {
+		serv.OpenAccount(context.Background(), &repository.Wallet{Name: "John Doe", Balance: balance})
+	}
+	return serv
+}
+
+func TestLimits(t *testing.T) {
+	policy := service.Policy{Limits: service.Limits{MaxDeposit: 500, MaxWithdrawal: 200, MaxTransfer: 300}}
+
+	t.Run("At Limit", func(t *testing.T) {
+		// arrange
+		serv := newPolicyService(policy, 1000, 0)
+		// act
+		_, depositErr := serv.Deposit(context.Background(), 1, 500)
+		_, withdrawErr := serv.Withdraw(context.Background(), 1, 200)
+		transferErr := serv.Transfer(context.Background(), 1, 2, 300)
+		// assert
+		assert.Nil(t, depositErr)
+		assert.Nil(t, withdrawErr)
+		assert.Nil(t, transferErr)
+	})
+
+	t.Run("Over Limit", func(t *testing.T) {
+		// arrange
+		serv := newPolicyService(policy, 1000, 0)
+		// act
+		_, depositErr := serv.Deposit(context.Background(), 1, 501)
+		_, withdrawErr := serv.Withdraw(context.Background(), 1, 201)
+		transferErr := serv.Transfer(context.Background(), 1, 2, 301)
+		// assert
+		assert.ErrorIs(t, depositErr, service.ErrLimitExceeded)
+		assert.ErrorIs(t, withdrawErr, service.ErrLimitExceeded)
+		assert.ErrorIs(t, transferErr, service.ErrLimitExceeded)
+		wallet, _ := serv.GetAccount(context.Background(), 1)
+		assert.Equal(t, float32(1000), wallet.Balance)
+	})
+
+	t.Run("Batch Atomic", func(t *testing.T) {
+		// arrange
+		serv := newPolicyService(policy, 1000, 0)
+		ops := []service.Operation{
+			{WalletID: 2, Type: service.OperationDeposit, Amount: 100},
+			{WalletID: 1, Type: service.OperationWithdraw, Amount: 250},
+		}
+		// act
+		results, err := serv.Batch(context.Background(), ops, service.BatchAtomic)
+		// assert
+		assert.Nil(t, err)
+		assert.Equal(t, []string{service.ResultAborted, service.ResultFailed}, statuses(results))
+		assert.Equal(t, service.CodeLimitExceeded, results[1].Code)
+		wallet, _ := serv.GetAccount(context.Background(), 2)
+		assert.Equal(t, float32(0), wallet.Balance)
+	})
+
+	t.Run("Batch Best Effort", func(t *testing.T) {
+		// arrange
+		serv := newPolicyService(policy, 1000)
+		ops := []service.Operation{
+			{WalletID: 1, Type: service.OperationDeposit, Amount: 600},
+			{WalletID: 1, Type: service.OperationDeposit, Amount: 100},
+		}
+		// act
+		results, err := serv.Batch(context.Background(), ops, service.BatchBestEffort)
+		// assert
+		assert.Nil(t, err)
+		assert.Equal(t, []string{service.ResultFailed, service.ResultOK}, statuses(results))
+		assert.Equal(t, service.CodeLimitExceeded, results[0].Code)
+	})
+}
+
+func TestTransferFee(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		serv := newPolicyService(service.Policy{Fees: service.Fees{Transfer: 2}}, 1000, 0)
+		// act
+		err := serv.Transfer(context.Background(), 1, 2, 300)
+		// assert
+		assert.Nil(t, err)
+		from, _ := serv.GetAccount(context.Background(), 1)
+		to, _ := serv.GetAccount(context.Background(), 2)
+		assert.Equal(t, float32(698), from.Balance)
+		assert.Equal(t, float32(300), to.Balance)
+		history, _ := serv.History(context.Background(), 1)
+		assert.Equal(t, float32(-302), history[1].Amount)
+		assert.Equal(t, float32(698), history[1].Balance)
+	})
+
+	t.Run("Error Not Enough Money For Fee", func(t *testing.T) {
+		// arrange
+		serv := newPolicyService(service.Policy{Fees: service.Fees{Transfer: 2}}, 300, 0)
+		// act
+		err := serv.Transfer(context.Background(), 1, 2, 300)
+		// assert
+		assert.ErrorIs(t, err, service.ErrNotEnoughMoney)
+	})
+}
+
+func TestPolicyChange(t *testing.T) {
+	// arrange
+	var current atomic.Value
+	current.Store(service.Policy{})
+	repo := repository.NewMemoryWalletRepository()
+	serv := service.NewWalletService(repo, service.WithPolicy(func() service.Policy { return current.Load().(service.Policy) }))
+	serv.OpenAccount(context.Background(), &repository.Wallet{Name: "John Doe", Balance: 1000})
+	_, before := serv.Withdraw(context.Background(), 1, 100)
+	// act
+	current.Store(service.Policy{Limits: service.Limits{MaxWithdrawal: 50}})
+	_, after := serv.Withdraw(context.Background(), 1, 100)
+	// assert
+	assert.Nil(t, before)
+	assert.ErrorIs(t, after, service.ErrLimitExceeded)
+}
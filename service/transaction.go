@@ -0,0 +1,51 @@
+package service
+
+import (
+	"gotest/event"
+	"gotest/repository"
+	"time"
+)
+
+// Transaction is a ledger line derived from a wallet's events. Amount is
+// signed: negative for money leaving the wallet. Adjustments have a zero
+// Amount, so the amounts always sum to what the wallet should hold.
+type Transaction struct {
+	ID         uint64    `json:"id"`
+	WalletID   uint64    `json:"wallet_id"`
+	Type       string    `json:"type"`
+	Amount     float32   `json:"amount"`
+	Balance    float32   `json:"balance"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// newTransaction reports false for events that do not move money on their
+// own, such as TransferCompleted, whose legs are recorded separately.
+func newTransaction(record repository.Event) (Transaction, bool, error) {
+	decoded, err := event.Decode(record)
+	if err != nil {
+		return Transaction{}, false, err
+	}
+
+	transaction := Transaction{
+		ID:         record.ID,
+		WalletID:   record.WalletID,
+		Type:       record.Type,
+		OccurredAt: record.OccurredAt,
+	}
+	switch e := decoded.(type) {
+	case event.WalletOpened:
+		transaction.Amount = e.Balance
+		transaction.Balance = e.Balance
+	case event.FundsDeposited:
+		transaction.Amount = e.Amount
+		transaction.Balance = e.Balance
+	case event.FundsWithdrawn:
+		transaction.Amount = -(e.Amount + e.Fee)
+		transaction.Balance = e.Balance
+	case event.BalanceAdjusted:
+		transaction.Balance = e.Balance
+	default:
+		return Transaction{}, false, nil
+	}
+	return transaction, true, nil
+}
@@ -1,34 +1,134 @@
 package service
 
-import "gotest/repository"
+import (
+	"context"
+	"errors"
+	"gotest/event"
+	"gotest/repository"
+	"log/slog"
+	"time"
+)
 
 type WalletService interface {
-	OpenAccount(wallet *repository.Wallet) error
-	GetAccount(id uint64) (*repository.Wallet, error)
-	Withdraw(id uint64, amount float32) (float32, error)
-	Deposit(id uint64, amount float32) (float32, error)
+	OpenAccount(ctx context.Context, wallet *repository.Wallet) error
+	GetAccount(ctx context.Context, id uint64) (*repository.Wallet, error)
+	ListAccounts(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) ([]repository.Wallet, string, error)
+	Withdraw(ctx context.Context, id uint64, amount float32) (float32, error)
+	Deposit(ctx context.Context, id uint64, amount float32) (float32, error)
+	Transfer(ctx context.Context, fromID uint64, toID uint64, amount float32) error
+	History(ctx context.Context, id uint64) ([]Transaction, error)
+	GetBalanceAt(ctx context.Context, id uint64, at time.Time) (float32, error)
+	AdjustBalance(ctx context.Context, id uint64, balance float32, reason string) error
+	SetStatus(ctx context.Context, id uint64, status string, reason string) (*repository.Wallet, bool, error)
+	Batch(ctx context.Context, ops []Operation, mode string) ([]OperationResult, error)
 }
 
+const (
+	DefaultPageSize         = 20
+	MaxPageSize             = 100
+	DefaultBatchConcurrency = 8
+	DefaultSnapshotInterval = 100
+)
+
 type walletService struct {
-	walletRepo repository.WalletRepository
+	walletRepo       repository.WalletRepository
+	batchConcurrency int
+	snapshotInterval int
+	logger           *slog.Logger
+	timeouts         Timeouts
+	policy           func() Policy
+}
+
+// Timeouts bounds each kind of WalletService call on top of the caller's own
+// deadline. Zero leaves that kind unbounded.
+type Timeouts struct {
+	Read  time.Duration
+	Write time.Duration
+	Batch time.Duration
+}
+
+var DefaultTimeouts = Timeouts{Read: 2 * time.Second, Write: 5 * time.Second, Batch: 30 * time.Second}
+
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+type Option func(s *walletService)
+
+func WithBatchConcurrency(n int) Option {
+	return func(s *walletService) {
+		if n > 0 {
+			s.batchConcurrency = n
+		}
+	}
+}
+
+// WithSnapshotInterval sets how many replayed transactions GetBalanceAt
+// allows between balance snapshots.
+func WithSnapshotInterval(n int) Option {
+	return func(s *walletService) {
+		if n > 0 {
+			s.snapshotInterval = n
+		}
+	}
+}
+
+// WithLogger sets where unexpected errors are logged with their cause,
+// slog.Default() otherwise.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *walletService) {
+		if logger != nil {
+			s.logger = logger
+		}
+	}
+}
+
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(s *walletService) {
+		s.timeouts = timeouts
+	}
 }
 
-func NewWalletService(walletRepo repository.WalletRepository) WalletService {
-	return walletService{walletRepo: walletRepo}
+func NewWalletService(walletRepo repository.WalletRepository, opts ...Option) WalletService {
+	s := walletService{walletRepo: walletRepo, batchConcurrency: DefaultBatchConcurrency, snapshotInterval: DefaultSnapshotInterval, logger: slog.Default(), policy: func() Policy { return Policy{} }}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
 }
 
-func (s walletService) OpenAccount(wallet *repository.Wallet) error {
-	err := s.walletRepo.Create(wallet)
+func (s walletService) OpenAccount(ctx context.Context, wallet *repository.Wallet) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	wallet.Status = repository.StatusActive
+	wallet.CreatedAt = time.Now().UTC()
+
+	opened, err := event.Encode(event.WalletOpened{
+		Name:    wallet.Name,
+		Balance: wallet.Balance,
+	})
+	if err != nil {
+		return s.unexpected(ctx, err)
+	}
+
+	err = s.walletRepo.Create(ctx, wallet, opened)
 	if err != nil {
-		return NewErrorWalletUnexpected()
+		return s.writeError(ctx, err)
 	}
 	return nil
 }
 
-func (s walletService) GetAccount(id uint64) (*repository.Wallet, error) {
-	wallet, err := s.walletRepo.Get(id)
+func (s walletService) GetAccount(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	wallet, err := s.walletRepo.Get(ctx, id)
 	if err != nil {
-		return nil, NewErrorWalletUnexpected()
+		return nil, s.unexpected(ctx, err)
 	}
 
 	if wallet.ID == 0 {
@@ -38,36 +138,307 @@ func (s walletService) GetAccount(id uint64) (*repository.Wallet, error) {
 	return wallet, nil
 }
 
-func (s walletService) Withdraw(id uint64, amount float32) (float32, error) {
-	wallet, err := s.GetAccount(id)
+func (s walletService) ListAccounts(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) ([]repository.Wallet, string, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	if !sort.Valid() {
+		return nil, "", NewErrorBadRequest("INVALID SORT")
+	}
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	wallets, next, err := s.walletRepo.List(ctx, filter, sort, cursor, limit)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, "", NewErrorBadRequest("INVALID CURSOR")
+	}
+	if err != nil {
+		return nil, "", s.unexpected(ctx, err)
+	}
+
+	return wallets, next, nil
+}
+
+func (s walletService) Withdraw(ctx context.Context, id uint64, amount float32) (float32, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	if !validAmount(amount) {
+		return 0, NewErrorBadRequest("INVALID AMOUNT")
+	}
+	if overLimit(s.policy().Limits.MaxWithdrawal, amount) {
+		return 0, NewErrorLimitExceeded()
+	}
+
+	wallet, err := s.GetAccount(ctx, id)
 	if err != nil {
 		return 0, err
 	}
 
+	if wallet.Status == repository.StatusFrozen {
+		return 0, NewErrorWalletFrozen()
+	}
 	if wallet.Balance < amount {
-		return 0, NewErrorBadRequest("NOT ENOUGH MONEY")
+		return 0, NewErrorNotEnoughMoney()
 	}
 
 	wallet.Balance = wallet.Balance - amount
-	err = s.walletRepo.Update(id, wallet)
+	withdrawn, err := event.Encode(event.FundsWithdrawn{
+		WalletID: id,
+		Amount:   amount,
+		Balance:  wallet.Balance,
+	})
+	if err != nil {
+		return 0, s.unexpected(ctx, err)
+	}
+
+	err = s.walletRepo.Update(ctx, id, wallet, withdrawn)
 	if err != nil {
-		return 0, NewErrorWalletUnexpected()
+		return 0, s.writeError(ctx, err)
 	}
 
 	return wallet.Balance, nil
 }
 
-func (s walletService) Deposit(id uint64, amount float32) (float32, error) {
-	wallet, err := s.GetAccount(id)
+func (s walletService) Deposit(ctx context.Context, id uint64, amount float32) (float32, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	if !validAmount(amount) {
+		return 0, NewErrorBadRequest("INVALID AMOUNT")
+	}
+	if overLimit(s.policy().Limits.MaxDeposit, amount) {
+		return 0, NewErrorLimitExceeded()
+	}
+
+	wallet, err := s.GetAccount(ctx, id)
 	if err != nil {
 		return 0, err
 	}
 
+	if wallet.Status == repository.StatusFrozen {
+		return 0, NewErrorWalletFrozen()
+	}
 	wallet.Balance = wallet.Balance + amount
-	err = s.walletRepo.Update(id, wallet)
+	deposited, err := event.Encode(event.FundsDeposited{
+		WalletID: id,
+		Amount:   amount,
+		Balance:  wallet.Balance,
+	})
+	if err != nil {
+		return 0, s.unexpected(ctx, err)
+	}
+
+	err = s.walletRepo.Update(ctx, id, wallet, deposited)
 	if err != nil {
-		return 0, NewErrorWalletUnexpected()
+		return 0, s.writeError(ctx, err)
 	}
 
 	return wallet.Balance, nil
 }
+
+func (s walletService) Transfer(ctx context.Context, fromID uint64, toID uint64, amount float32) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	if fromID == toID {
+		return NewErrorBadRequest("SAME WALLET")
+	}
+	if !validAmount(amount) {
+		return NewErrorBadRequest("INVALID AMOUNT")
+	}
+	policy := s.policy()
+	if overLimit(policy.Limits.MaxTransfer, amount) {
+		return NewErrorLimitExceeded()
+	}
+
+	from, err := s.GetAccount(ctx, fromID)
+	if err != nil {
+		return err
+	}
+
+	to, err := s.GetAccount(ctx, toID)
+	if err != nil {
+		return err
+	}
+
+	if from.Status == repository.StatusFrozen || to.Status == repository.StatusFrozen {
+		return NewErrorWalletFrozen()
+	}
+	fee := policy.Fees.Transfer
+	if from.Balance < amount+fee {
+		return NewErrorNotEnoughMoney()
+	}
+
+	from.Balance = from.Balance - amount - fee
+	to.Balance = to.Balance + amount
+
+	events := []repository.Event{}
+	for _, e := range []event.DomainEvent{
+		event.FundsWithdrawn{WalletID: fromID, Amount: amount, Fee: fee, Balance: from.Balance},
+		event.FundsDeposited{WalletID: toID, Amount: amount, Balance: to.Balance},
+		event.TransferCompleted{WalletID: fromID, ToWalletID: toID, Amount: amount},
+	} {
+		record, err := event.Encode(e)
+		if err != nil {
+			return s.unexpected(ctx, err)
+		}
+		events = append(events, record)
+	}
+
+	err = s.walletRepo.UpdateMany(ctx, []*repository.Wallet{from, to}, events...)
+	if err != nil {
+		return s.writeError(ctx, err)
+	}
+
+	return nil
+}
+
+func (s walletService) History(ctx context.Context, id uint64) ([]Transaction, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	if _, err := s.GetAccount(ctx, id); err != nil {
+		return nil, err
+	}
+
+	events, err := s.walletRepo.Events(ctx, id)
+	if err != nil {
+		return nil, s.unexpected(ctx, err)
+	}
+
+	transactions := []Transaction{}
+	for _, e := range events {
+		transaction, ok, err := newTransaction(e)
+		if err != nil {
+			return nil, s.unexpected(ctx, err)
+		}
+		if ok {
+			transactions = append(transactions, transaction)
+		}
+	}
+	return transactions, nil
+}
+
+// GetBalanceAt replays the ledger up to and including at, starting from the
+// closest earlier snapshot. While replaying it saves a snapshot every
+// snapshotInterval transactions, so repeated queries stay short. Before the
+// wallet was opened the balance is 0.
+func (s walletService) GetBalanceAt(ctx context.Context, id uint64, at time.Time) (float32, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	if _, err := s.GetAccount(ctx, id); err != nil {
+		return 0, err
+	}
+
+	snapshot, err := s.walletRepo.BalanceSnapshotAt(ctx, id, at)
+	if err != nil {
+		return 0, s.unexpected(ctx, err)
+	}
+
+	events, err := s.walletRepo.EventsSince(ctx, id, snapshot.EventID)
+	if err != nil {
+		return 0, s.unexpected(ctx, err)
+	}
+
+	balance, replayed := snapshot.Balance, 0
+	for _, e := range events {
+		if e.OccurredAt.After(at) {
+			break
+		}
+
+		transaction, ok, err := newTransaction(e)
+		if err != nil {
+			return 0, s.unexpected(ctx, err)
+		}
+		if !ok {
+			continue
+		}
+
+		balance = transaction.Balance
+		if replayed++; replayed%s.snapshotInterval == 0 {
+			err := s.walletRepo.SaveBalanceSnapshot(ctx, repository.BalanceSnapshot{
+				WalletID:   id,
+				EventID:    e.ID,
+				Balance:    balance,
+				OccurredAt: e.OccurredAt,
+			})
+			if err != nil {
+				return 0, s.unexpected(ctx, err)
+			}
+		}
+	}
+	return balance, nil
+}
+
+// AdjustBalance overwrites the stored balance, recording the correction as a
+// BalanceAdjusted event. It is meant for reconciliation, not for moving money.
+func (s walletService) AdjustBalance(ctx context.Context, id uint64, balance float32, reason string) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	wallet, err := s.GetAccount(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	adjusted, err := event.Encode(event.BalanceAdjusted{
+		WalletID: id,
+		Previous: wallet.Balance,
+		Balance:  balance,
+		Reason:   reason,
+	})
+	if err != nil {
+		return s.unexpected(ctx, err)
+	}
+
+	wallet.Balance = balance
+	err = s.walletRepo.Update(ctx, id, wallet, adjusted)
+	if err != nil {
+		return s.writeError(ctx, err)
+	}
+	return nil
+}
+
+// SetStatus freezes or reactivates a wallet and reports whether its status
+// changed. A frozen wallet keeps its balance but refuses deposits,
+// withdrawals and transfers. Setting the status the wallet already has
+// records nothing.
+func (s walletService) SetStatus(ctx context.Context, id uint64, status string, reason string) (*repository.Wallet, bool, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	if status != repository.StatusActive && status != repository.StatusFrozen {
+		return nil, false, NewErrorBadRequest("INVALID STATUS")
+	}
+
+	wallet, err := s.GetAccount(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if wallet.Status == status {
+		return wallet, false, nil
+	}
+
+	changed, err := event.Encode(event.StatusChanged{
+		WalletID: id,
+		Previous: wallet.Status,
+		Status:   status,
+		Reason:   reason,
+	})
+	if err != nil {
+		return nil, false, s.unexpected(ctx, err)
+	}
+
+	wallet.Status = status
+	err = s.walletRepo.Update(ctx, id, wallet, changed)
+	if err != nil {
+		return nil, false, s.writeError(ctx, err)
+	}
+	return wallet, true, nil
+}
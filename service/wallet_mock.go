@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"gotest/repository"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -14,22 +16,57 @@ func NewWalletServiceMock() *walletServiceMock {
 	return &walletServiceMock{}
 }
 
-func (m *walletServiceMock) OpenAccount(wallet *repository.Wallet) error {
+func (m *walletServiceMock) OpenAccount(ctx context.Context, wallet *repository.Wallet) error {
 	c := m.Called(wallet)
 	return c.Error(0)
 }
 
-func (m *walletServiceMock) GetAccount(id uint64) (*repository.Wallet, error) {
+func (m *walletServiceMock) GetAccount(ctx context.Context, id uint64) (*repository.Wallet, error) {
 	c := m.Called(id)
 	return c.Get(0).(*repository.Wallet), c.Error(1)
 }
 
-func (m *walletServiceMock) Withdraw(id uint64, amount float32) (float32, error) {
+func (m *walletServiceMock) ListAccounts(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) ([]repository.Wallet, string, error) {
+	c := m.Called(filter, sort, cursor, limit)
+	return c.Get(0).([]repository.Wallet), c.String(1), c.Error(2)
+}
+
+func (m *walletServiceMock) Withdraw(ctx context.Context, id uint64, amount float32) (float32, error) {
 	c := m.Called(id, amount)
 	return c.Get(0).(float32), c.Error(1)
 }
 
-func (m *walletServiceMock) Deposit(id uint64, amount float32) (float32, error) {
+func (m *walletServiceMock) Deposit(ctx context.Context, id uint64, amount float32) (float32, error) {
 	c := m.Called(id, amount)
 	return c.Get(0).(float32), c.Error(1)
 }
+
+func (m *walletServiceMock) Transfer(ctx context.Context, fromID uint64, toID uint64, amount float32) error {
+	c := m.Called(fromID, toID, amount)
+	return c.Error(0)
+}
+
+func (m *walletServiceMock) History(ctx context.Context, id uint64) ([]Transaction, error) {
+	c := m.Called(id)
+	return c.Get(0).([]Transaction), c.Error(1)
+}
+
+func (m *walletServiceMock) GetBalanceAt(ctx context.Context, id uint64, at time.Time) (float32, error) {
+	c := m.Called(id, at)
+	return c.Get(0).(float32), c.Error(1)
+}
+
+func (m *walletServiceMock) AdjustBalance(ctx context.Context, id uint64, balance float32, reason string) error {
+	c := m.Called(id, balance, reason)
+	return c.Error(0)
+}
+
+func (m *walletServiceMock) SetStatus(ctx context.Context, id uint64, status string, reason string) (*repository.Wallet, bool, error) {
+	c := m.Called(id, status, reason)
+	return c.Get(0).(*repository.Wallet), c.Bool(1), c.Error(2)
+}
+
+func (m *walletServiceMock) Batch(ctx context.Context, ops []Operation, mode string) ([]OperationResult, error) {
+	c := m.Called(ops, mode)
+	return c.Get(0).([]OperationResult), c.Error(1)
+}
@@ -2,12 +2,19 @@
 package service_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"gotest/event"
 	"gotest/repository"
 	"gotest/service"
+	"log/slog"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestOpenAccount(t *testing.T) {
@@ -23,7 +30,7 @@ func TestOpenAccount(t *testing.T) {
 		serv := service.NewWalletService(repo)
 
 		// act
-		err := serv.OpenAccount(&successInput)
+		err := serv.OpenAccount(context.Background(), &successInput)
 		// assert
 		assert.Nil(t, err)
 	})
@@ -42,7 +49,7 @@ func TestOpenAccount(t *testing.T) {
 		serv := service.NewWalletService(repo)
 
 		// act
-		err := serv.OpenAccount(&errorInput)
+		err := serv.OpenAccount(context.Background(), &errorInput)
 		// assert
 		assert.ErrorIs(t, err, service.NewErrorWalletUnexpected())
 	})
@@ -62,7 +69,7 @@ func TestGetAccount(t *testing.T) {
 		repo.On("Get", input).Return(&wallet, nil)
 		serv := service.NewWalletService(repo)
 		// act
-		result, _ := serv.GetAccount(input)
+		result, _ := serv.GetAccount(context.Background(), input)
 		// assert
 		assert.Equal(t, wallet.ID, result.ID)
 	})
@@ -80,7 +87,7 @@ func TestGetAccount(t *testing.T) {
 		repo.On("Get", input).Return(&wallet, nil)
 		serv := service.NewWalletService(repo)
 		// act
-		result, err := serv.GetAccount(input)
+		result, err := serv.GetAccount(context.Background(), input)
 		_ = result
 		// assert
 		assert.ErrorIs(t, err, service.NewErrorWalletNotFound())
@@ -99,11 +106,24 @@ func TestGetAccount(t *testing.T) {
 		repo.On("Get", input).Return(&wallet, errors.New("database down"))
 		serv := service.NewWalletService(repo)
 		// act
-		result, err := serv.GetAccount(input)
+		result, err := serv.GetAccount(context.Background(), input)
 		_ = result
 		// assert
 		assert.ErrorIs(t, err, service.NewErrorWalletUnexpected())
 	})
+
+	t.Run("ErrorUnavailable", func(t *testing.T) {
+		// arrange
+		var input uint64 = 4
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", input).Return((*repository.Wallet)(nil), repository.ErrUnavailable)
+		serv := service.NewWalletService(repo)
+		// act
+		_, err := serv.GetAccount(context.Background(), input)
+		// assert
+		assert.ErrorIs(t, err, service.ErrUnavailable)
+		assert.ErrorIs(t, err, repository.ErrUnavailable)
+	})
 }
 
 func TestWithdrawSuccessful(t *testing.T) {
@@ -132,13 +152,26 @@ func TestWithdrawSuccessful(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.Name, func(t *testing.T) {
-			result, _ := serv.Withdraw(test.ID, test.Amount)
+			result, _ := serv.Withdraw(context.Background(), test.ID, test.Amount)
 			assert.Equal(t, result, test.Expected)
 		})
 	}
 }
 
 func TestWithdrawError(t *testing.T) {
+	t.Run("Error Invalid Amount", func(t *testing.T) {
+		for _, amount := range []float32{0, -5, float32(math.NaN()), float32(math.Inf(1))} {
+			// arrange
+			repo := repository.NewWalletRepositoryMock()
+			serv := service.NewWalletService(repo)
+			// act
+			_, err := serv.Withdraw(context.Background(), 1, amount)
+			// assert
+			assert.ErrorIs(t, err, service.NewErrorBadRequest("INVALID AMOUNT"))
+			repo.AssertNotCalled(t, "Get", mock.Anything)
+		}
+	})
+
 	t.Run("Error From GetAccount", func(t *testing.T) {
 		// arrange
 		var id uint64 = 1
@@ -153,7 +186,7 @@ func TestWithdrawError(t *testing.T) {
 		repo.On("Get", uint64(1)).Return(&wallet, nil)
 		serv := service.NewWalletService(repo)
 		// act
-		result, err := serv.Withdraw(id, amount)
+		result, err := serv.Withdraw(context.Background(), id, amount)
 		_ = result
 		// assert
 		assert.Error(t, err)
@@ -174,10 +207,10 @@ func TestWithdrawError(t *testing.T) {
 		repo.On("Update", id).Return(nil)
 		serv := service.NewWalletService(repo)
 		// act
-		result, err := serv.Withdraw(id, amount)
+		result, err := serv.Withdraw(context.Background(), id, amount)
 		_ = result
 		// assert
-		assert.ErrorIs(t, err, service.NewErrorBadRequest("NOT ENOUGH MONEY"))
+		assert.ErrorIs(t, err, service.ErrNotEnoughMoney)
 	})
 
 	t.Run("Error Unexpected", func(t *testing.T) {
@@ -195,11 +228,43 @@ func TestWithdrawError(t *testing.T) {
 		repo.On("Update", id).Return(errors.New("database down"))
 		serv := service.NewWalletService(repo)
 		// act
-		result, err := serv.Withdraw(id, amount)
+		result, err := serv.Withdraw(context.Background(), id, amount)
 		_ = result
 		// assert
 		assert.ErrorIs(t, err, service.NewErrorWalletUnexpected())
 	})
+
+	t.Run("Logs Cause", func(t *testing.T) {
+		// arrange
+		var logs bytes.Buffer
+		wallet := repository.Wallet{ID: 1, Name: "John Doe", Balance: 1000}
+
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", uint64(1)).Return(&wallet, nil)
+		repo.On("Update", uint64(1)).Return(errors.New("database down"))
+		serv := service.NewWalletService(repo, service.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))))
+		// act
+		_, err := serv.Withdraw(context.Background(), 1, 100)
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorWalletUnexpected())
+		assert.Contains(t, logs.String(), `"level":"ERROR"`)
+		assert.Contains(t, logs.String(), `"error":"database down"`)
+	})
+
+	t.Run("Error Conflict", func(t *testing.T) {
+		// arrange
+		wallet := repository.Wallet{ID: 1, Name: "John Doe", Balance: 1000, Version: 3}
+
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", uint64(1)).Return(&wallet, nil)
+		repo.On("Update", uint64(1)).Return(repository.ErrVersionConflict)
+		serv := service.NewWalletService(repo)
+		// act
+		_, err := serv.Withdraw(context.Background(), 1, 100)
+		// assert
+		assert.ErrorIs(t, err, service.ErrConflict)
+		assert.ErrorIs(t, err, repository.ErrVersionConflict)
+	})
 }
 
 func TestDepositSuccessful(t *testing.T) {
@@ -228,13 +293,26 @@ func TestDepositSuccessful(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.Name, func(t *testing.T) {
-			result, _ := serv.Deposit(test.ID, test.Amount)
+			result, _ := serv.Deposit(context.Background(), test.ID, test.Amount)
 			assert.Equal(t, result, test.Expected)
 		})
 	}
 }
 
 func TestDepositError(t *testing.T) {
+	t.Run("Error Invalid Amount", func(t *testing.T) {
+		for _, amount := range []float32{0, -5, float32(math.NaN()), float32(math.Inf(1))} {
+			// arrange
+			repo := repository.NewWalletRepositoryMock()
+			serv := service.NewWalletService(repo)
+			// act
+			_, err := serv.Deposit(context.Background(), 1, amount)
+			// assert
+			assert.ErrorIs(t, err, service.NewErrorBadRequest("INVALID AMOUNT"))
+			repo.AssertNotCalled(t, "Get", mock.Anything)
+		}
+	})
+
 	t.Run("Error From GetAccount", func(t *testing.T) {
 		// arrange
 		var id uint64 = 1
@@ -249,7 +327,7 @@ func TestDepositError(t *testing.T) {
 		repo.On("Get", uint64(1)).Return(&wallet, nil)
 		serv := service.NewWalletService(repo)
 		// act
-		result, err := serv.Deposit(id, amount)
+		result, err := serv.Deposit(context.Background(), id, amount)
 		_ = result
 		// assert
 		assert.Error(t, err)
@@ -270,9 +348,512 @@ func TestDepositError(t *testing.T) {
 		repo.On("Update", id).Return(errors.New("database down"))
 		serv := service.NewWalletService(repo)
 		// act
-		result, err := serv.Deposit(id, amount)
+		result, err := serv.Deposit(context.Background(), id, amount)
 		_ = result
 		// assert
 		assert.ErrorIs(t, err, service.NewErrorWalletUnexpected())
 	})
 }
+
+func TestTransfer(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		from := repository.Wallet{ID: 1, Name: "John Doe", Balance: 1000}
+		to := repository.Wallet{ID: 2, Name: "Jane Doe", Balance: 500}
+
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", uint64(1)).Return(&from, nil)
+		repo.On("Get", uint64(2)).Return(&to, nil)
+		repo.On("UpdateMany", mock.Anything).Return(nil)
+		serv := service.NewWalletService(repo)
+		// act
+		err := serv.Transfer(context.Background(), 1, 2, 300)
+		// assert
+		assert.Nil(t, err)
+		assert.Equal(t, float32(700), from.Balance)
+		assert.Equal(t, float32(800), to.Balance)
+	})
+
+	t.Run("Error Same Wallet", func(t *testing.T) {
+		// arrange
+		repo := repository.NewWalletRepositoryMock()
+		serv := service.NewWalletService(repo)
+		// act
+		err := serv.Transfer(context.Background(), 1, 1, 300)
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorBadRequest("SAME WALLET"))
+	})
+
+	t.Run("Error Invalid Amount", func(t *testing.T) {
+		// arrange
+		repo := repository.NewWalletRepositoryMock()
+		serv := service.NewWalletService(repo)
+		// act
+		zero := serv.Transfer(context.Background(), 1, 2, 0)
+		negative := serv.Transfer(context.Background(), 1, 2, -300)
+		// assert
+		assert.ErrorIs(t, zero, service.NewErrorBadRequest("INVALID AMOUNT"))
+		assert.ErrorIs(t, negative, service.NewErrorBadRequest("INVALID AMOUNT"))
+		repo.AssertNotCalled(t, "Get", mock.Anything)
+	})
+
+	t.Run("Error Not Enough Money", func(t *testing.T) {
+		// arrange
+		from := repository.Wallet{ID: 1, Name: "John Doe", Balance: 100}
+		to := repository.Wallet{ID: 2, Name: "Jane Doe", Balance: 500}
+
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", uint64(1)).Return(&from, nil)
+		repo.On("Get", uint64(2)).Return(&to, nil)
+		serv := service.NewWalletService(repo)
+		// act
+		err := serv.Transfer(context.Background(), 1, 2, 300)
+		// assert
+		assert.ErrorIs(t, err, service.ErrNotEnoughMoney)
+		repo.AssertNotCalled(t, "UpdateMany", mock.Anything)
+	})
+
+	t.Run("Error Unexpected", func(t *testing.T) {
+		// arrange
+		from := repository.Wallet{ID: 1, Name: "John Doe", Balance: 1000}
+		to := repository.Wallet{ID: 2, Name: "Jane Doe", Balance: 500}
+
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", uint64(1)).Return(&from, nil)
+		repo.On("Get", uint64(2)).Return(&to, nil)
+		repo.On("UpdateMany", mock.Anything).Return(errors.New("database down"))
+		serv := service.NewWalletService(repo)
+		// act
+		err := serv.Transfer(context.Background(), 1, 2, 300)
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorWalletUnexpected())
+	})
+}
+
+func TestDomainEvents(t *testing.T) {
+	// arrange
+	repo := repository.NewMemoryWalletRepository()
+	serv := service.NewWalletService(repo)
+	john := repository.Wallet{Name: "John Doe", Balance: 1000}
+	jane := repository.Wallet{Name: "Jane Doe", Balance: 0}
+	serv.OpenAccount(context.Background(), &john)
+	serv.OpenAccount(context.Background(), &jane)
+	// act
+	serv.Deposit(context.Background(), john.ID, 200)
+	serv.Withdraw(context.Background(), john.ID, 100)
+	serv.Transfer(context.Background(), john.ID, jane.ID, 300)
+	// assert
+	records, _ := repo.PendingEvents(context.Background(), 100)
+	types := []string{}
+	for _, record := range records {
+		types = append(types, record.Type)
+	}
+	assert.Equal(t, []string{
+		event.TypeWalletOpened,
+		event.TypeWalletOpened,
+		event.TypeFundsDeposited,
+		event.TypeFundsWithdrawn,
+		event.TypeFundsWithdrawn,
+		event.TypeFundsDeposited,
+		event.TypeTransferCompleted,
+	}, types)
+
+	opened, _ := event.Decode(records[1])
+	assert.Equal(t, event.WalletOpened{WalletID: jane.ID, Name: "Jane Doe", Balance: 0}, opened)
+
+	transfer, _ := event.Decode(records[6])
+	assert.Equal(t, event.TransferCompleted{WalletID: john.ID, ToWalletID: jane.ID, Amount: 300}, transfer)
+}
+
+func TestHistory(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		serv := service.NewWalletService(repo)
+		john := repository.Wallet{Name: "John Doe", Balance: 1000}
+		jane := repository.Wallet{Name: "Jane Doe", Balance: 0}
+		serv.OpenAccount(context.Background(), &john)
+		serv.OpenAccount(context.Background(), &jane)
+		serv.Deposit(context.Background(), john.ID, 200)
+		serv.Transfer(context.Background(), john.ID, jane.ID, 300)
+		// act
+		result, err := serv.History(context.Background(), john.ID)
+		// assert
+		assert.Nil(t, err)
+		amounts := []float32{}
+		balances := []float32{}
+		for _, transaction := range result {
+			amounts = append(amounts, transaction.Amount)
+			balances = append(balances, transaction.Balance)
+		}
+		assert.Equal(t, []float32{1000, 200, -300}, amounts)
+		assert.Equal(t, []float32{1000, 1200, 900}, balances)
+	})
+
+	t.Run("Error Not Found", func(t *testing.T) {
+		// arrange
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", uint64(1)).Return(&repository.Wallet{}, nil)
+		serv := service.NewWalletService(repo)
+		// act
+		_, err := serv.History(context.Background(), 1)
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorWalletNotFound())
+		repo.AssertNotCalled(t, "Events", mock.Anything)
+	})
+
+	t.Run("Error Unexpected", func(t *testing.T) {
+		// arrange
+		wallet := repository.Wallet{ID: 1, Name: "John Doe", Balance: 1000}
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", uint64(1)).Return(&wallet, nil)
+		repo.On("Events", uint64(1)).Return([]repository.Event{}, errors.New("database down"))
+		serv := service.NewWalletService(repo)
+		// act
+		_, err := serv.History(context.Background(), 1)
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorWalletUnexpected())
+	})
+}
+
+func TestListAccounts(t *testing.T) {
+	t.Run("Default Limit", func(t *testing.T) {
+		// arrange
+		wallets := []repository.Wallet{{ID: 1, Name: "John Doe", Balance: 1000}}
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("List", repository.WalletFilter{}, repository.WalletSort{}, "", service.DefaultPageSize).Return(wallets, "next", nil)
+		serv := service.NewWalletService(repo)
+		// act
+		result, next, err := serv.ListAccounts(context.Background(), repository.WalletFilter{}, repository.WalletSort{}, "", 0)
+		// assert
+		assert.Nil(t, err)
+		assert.Equal(t, wallets, result)
+		assert.Equal(t, "next", next)
+	})
+
+	t.Run("Clamp Limit", func(t *testing.T) {
+		// arrange
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("List", repository.WalletFilter{}, repository.WalletSort{}, "", service.MaxPageSize).Return([]repository.Wallet{}, "", nil)
+		serv := service.NewWalletService(repo)
+		// act
+		_, _, err := serv.ListAccounts(context.Background(), repository.WalletFilter{}, repository.WalletSort{}, "", 5000)
+		// assert
+		assert.Nil(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Error Invalid Sort", func(t *testing.T) {
+		// arrange
+		repo := repository.NewWalletRepositoryMock()
+		serv := service.NewWalletService(repo)
+		// act
+		_, _, err := serv.ListAccounts(context.Background(), repository.WalletFilter{}, repository.WalletSort{Field: "password"}, "", 10)
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorBadRequest("INVALID SORT"))
+	})
+
+	t.Run("Error Invalid Cursor", func(t *testing.T) {
+		// arrange
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("List", repository.WalletFilter{}, repository.WalletSort{}, "bad", 10).Return([]repository.Wallet{}, "", repository.ErrInvalidCursor)
+		serv := service.NewWalletService(repo)
+		// act
+		_, _, err := serv.ListAccounts(context.Background(), repository.WalletFilter{}, repository.WalletSort{}, "bad", 10)
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorBadRequest("INVALID CURSOR"))
+	})
+}
+
+func TestGetBalanceAt(t *testing.T) {
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	ledger := func(repo repository.WalletRepository, deposits int) uint64 {
+		opened, _ := event.Encode(event.WalletOpened{Name: "John Doe", Balance: 100})
+		opened.OccurredAt = start
+		wallet := repository.Wallet{Name: "John Doe", Balance: 100}
+		repo.Create(context.Background(), &wallet, opened)
+		for i := 1; i <= deposits; i++ {
+			wallet.Balance += 10
+			deposited, _ := event.Encode(event.FundsDeposited{WalletID: wallet.ID, Amount: 10, Balance: wallet.Balance})
+			deposited.OccurredAt = start.Add(time.Duration(i) * time.Hour)
+			repo.Update(context.Background(), wallet.ID, &wallet, deposited)
+		}
+		return wallet.ID
+	}
+
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		id := ledger(repo, 3)
+		serv := service.NewWalletService(repo)
+		// act
+		before, _ := serv.GetBalanceAt(context.Background(), id, start.Add(-time.Second))
+		opened, _ := serv.GetBalanceAt(context.Background(), id, start)
+		middle, _ := serv.GetBalanceAt(context.Background(), id, start.Add(150*time.Minute))
+		now, err := serv.GetBalanceAt(context.Background(), id, time.Now())
+		// assert
+		assert.Nil(t, err)
+		assert.Equal(t, float32(0), before)
+		assert.Equal(t, float32(100), opened)
+		assert.Equal(t, float32(120), middle)
+		assert.Equal(t, float32(130), now)
+	})
+
+	t.Run("Snapshots", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		id := ledger(repo, 9)
+		serv := service.NewWalletService(repo, service.WithSnapshotInterval(4))
+		// act
+		balance, err := serv.GetBalanceAt(context.Background(), id, start.Add(9*time.Hour))
+		// assert
+		assert.Nil(t, err)
+		assert.Equal(t, float32(190), balance)
+		snapshot, _ := repo.BalanceSnapshotAt(context.Background(), id, start.Add(9*time.Hour))
+		assert.Equal(t, uint64(8), snapshot.EventID)
+		assert.Equal(t, float32(170), snapshot.Balance)
+		balance, _ = serv.GetBalanceAt(context.Background(), id, start.Add(5*time.Hour))
+		assert.Equal(t, float32(150), balance)
+	})
+
+	t.Run("Replays From Snapshot", func(t *testing.T) {
+		// arrange
+		at := start.Add(3 * time.Hour)
+		deposited, _ := event.Encode(event.FundsDeposited{WalletID: 1, Amount: 10, Balance: 60})
+		deposited.OccurredAt = start.Add(time.Hour)
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", uint64(1)).Return(&repository.Wallet{ID: 1}, nil)
+		repo.On("BalanceSnapshotAt", uint64(1), at).Return(repository.BalanceSnapshot{WalletID: 1, EventID: 7, Balance: 50}, nil)
+		repo.On("EventsSince", uint64(1), uint64(7)).Return([]repository.Event{deposited}, nil)
+		serv := service.NewWalletService(repo)
+		// act
+		balance, err := serv.GetBalanceAt(context.Background(), 1, at)
+		// assert
+		assert.Nil(t, err)
+		assert.Equal(t, float32(60), balance)
+		repo.AssertNotCalled(t, "Events", mock.Anything)
+	})
+
+	t.Run("Error Not Found", func(t *testing.T) {
+		// arrange
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", uint64(1)).Return(&repository.Wallet{}, nil)
+		serv := service.NewWalletService(repo)
+		// act
+		_, err := serv.GetBalanceAt(context.Background(), 1, start)
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorWalletNotFound())
+		repo.AssertNotCalled(t, "EventsSince", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error Unexpected", func(t *testing.T) {
+		// arrange
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", uint64(1)).Return(&repository.Wallet{ID: 1}, nil)
+		repo.On("BalanceSnapshotAt", uint64(1), start).Return(repository.BalanceSnapshot{}, errors.New("unexpected"))
+		serv := service.NewWalletService(repo)
+		// act
+		_, err := serv.GetBalanceAt(context.Background(), 1, start)
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorWalletUnexpected())
+	})
+}
+
+func TestAdjustBalance(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		serv := service.NewWalletService(repo)
+		wallet := repository.Wallet{Name: "John Doe", Balance: 100}
+		serv.OpenAccount(context.Background(), &wallet)
+		// act
+		err := serv.AdjustBalance(context.Background(), wallet.ID, 80, "drift")
+		// assert
+		assert.Nil(t, err)
+		result, _ := serv.GetAccount(context.Background(), wallet.ID)
+		assert.Equal(t, float32(80), result.Balance)
+		history, _ := serv.History(context.Background(), wallet.ID)
+		assert.Equal(t, service.Transaction{
+			ID:         history[1].ID,
+			WalletID:   wallet.ID,
+			Type:       event.TypeBalanceAdjusted,
+			Amount:     0,
+			Balance:    80,
+			OccurredAt: history[1].OccurredAt,
+		}, history[1])
+		events, _ := repo.Events(context.Background(), wallet.ID)
+		decoded, _ := event.Decode(events[1])
+		assert.Equal(t, event.BalanceAdjusted{WalletID: wallet.ID, Previous: 100, Balance: 80, Reason: "drift"}, decoded)
+	})
+
+	t.Run("Error Not Found", func(t *testing.T) {
+		// arrange
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", uint64(1)).Return(&repository.Wallet{}, nil)
+		serv := service.NewWalletService(repo)
+		// act
+		err := serv.AdjustBalance(context.Background(), 1, 80, "drift")
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorWalletNotFound())
+		repo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestSetStatus(t *testing.T) {
+	t.Run("Freeze", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		serv := service.NewWalletService(repo)
+		wallet := repository.Wallet{Name: "John Doe", Balance: 100}
+		serv.OpenAccount(context.Background(), &wallet)
+		// act
+		result, changed, err := serv.SetStatus(context.Background(), wallet.ID, repository.StatusFrozen, "fraud check")
+		// assert
+		assert.Nil(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, repository.StatusFrozen, result.Status)
+		events, _ := repo.Events(context.Background(), wallet.ID)
+		decoded, _ := event.Decode(events[1])
+		assert.Equal(t, event.StatusChanged{WalletID: wallet.ID, Previous: repository.StatusActive, Status: repository.StatusFrozen, Reason: "fraud check"}, decoded)
+		history, _ := serv.History(context.Background(), wallet.ID)
+		assert.Len(t, history, 1)
+	})
+
+	t.Run("Same Status", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		serv := service.NewWalletService(repo)
+		wallet := repository.Wallet{Name: "John Doe", Balance: 100}
+		serv.OpenAccount(context.Background(), &wallet)
+		// act
+		result, changed, err := serv.SetStatus(context.Background(), wallet.ID, repository.StatusActive, "")
+		// assert
+		assert.Nil(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, repository.StatusActive, result.Status)
+		events, _ := repo.Events(context.Background(), wallet.ID)
+		assert.Len(t, events, 1)
+	})
+
+	t.Run("Frozen Wallet Refuses Money Movements", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		serv := service.NewWalletService(repo)
+		john := repository.Wallet{Name: "John Doe", Balance: 100}
+		jane := repository.Wallet{Name: "Jane Doe", Balance: 100}
+		serv.OpenAccount(context.Background(), &john)
+		serv.OpenAccount(context.Background(), &jane)
+		serv.SetStatus(context.Background(), john.ID, repository.StatusFrozen, "")
+		ops := []service.Operation{{WalletID: john.ID, Type: service.OperationDeposit, Amount: 10}}
+		// act
+		_, depositErr := serv.Deposit(context.Background(), john.ID, 10)
+		_, withdrawErr := serv.Withdraw(context.Background(), john.ID, 10)
+		transferErr := serv.Transfer(context.Background(), jane.ID, john.ID, 10)
+		results, _ := serv.Batch(context.Background(), ops, service.BatchAtomic)
+		// assert
+		assert.ErrorIs(t, depositErr, service.ErrWalletFrozen)
+		assert.ErrorIs(t, withdrawErr, service.ErrWalletFrozen)
+		assert.ErrorIs(t, transferErr, service.ErrWalletFrozen)
+		assert.Equal(t, service.CodeWalletFrozen, results[0].Code)
+		result, _ := serv.GetAccount(context.Background(), john.ID)
+		assert.Equal(t, float32(100), result.Balance)
+	})
+
+	t.Run("Unfreeze", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		serv := service.NewWalletService(repo)
+		wallet := repository.Wallet{Name: "John Doe", Balance: 100}
+		serv.OpenAccount(context.Background(), &wallet)
+		serv.SetStatus(context.Background(), wallet.ID, repository.StatusFrozen, "")
+		// act
+		_, _, err := serv.SetStatus(context.Background(), wallet.ID, repository.StatusActive, "cleared")
+		balance, depositErr := serv.Deposit(context.Background(), wallet.ID, 10)
+		// assert
+		assert.Nil(t, err)
+		assert.Nil(t, depositErr)
+		assert.Equal(t, float32(110), balance)
+	})
+
+	t.Run("Error Invalid Status", func(t *testing.T) {
+		// arrange
+		repo := repository.NewWalletRepositoryMock()
+		serv := service.NewWalletService(repo)
+		// act
+		_, _, err := serv.SetStatus(context.Background(), 1, repository.StatusClosed, "")
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorBadRequest("INVALID STATUS"))
+		repo.AssertNotCalled(t, "Get", mock.Anything)
+	})
+
+	t.Run("Error Not Found", func(t *testing.T) {
+		// arrange
+		repo := repository.NewWalletRepositoryMock()
+		repo.On("Get", uint64(1)).Return(&repository.Wallet{}, nil)
+		serv := service.NewWalletService(repo)
+		// act
+		_, _, err := serv.SetStatus(context.Background(), 1, repository.StatusFrozen, "")
+		// assert
+		assert.ErrorIs(t, err, service.NewErrorWalletNotFound())
+		repo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+// blockingRepository holds every Update until ctx ends, like a storage call
+// stuck on a lock or the network.
+type blockingRepository struct {
+	repository.WalletRepository
+}
+
+func (r blockingRepository) Update(ctx context.Context, id uint64, wallet *repository.Wallet, events ...repository.Event) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestTimeouts(t *testing.T) {
+	t.Run("Deadline Aborts Write", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		serv := service.NewWalletService(blockingRepository{repo}, service.WithTimeouts(service.Timeouts{Write: 20 * time.Millisecond}))
+		wallet := repository.Wallet{Name: "John Doe", Balance: 1000}
+		serv.OpenAccount(context.Background(), &wallet)
+		start := time.Now()
+		// act
+		_, err := serv.Withdraw(context.Background(), wallet.ID, 100)
+		// assert
+		assert.Less(t, time.Since(start), time.Second)
+		assert.ErrorIs(t, err, service.ErrTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		result, _ := repo.Get(context.Background(), wallet.ID)
+		assert.Equal(t, float32(1000), result.Balance)
+	})
+
+	t.Run("Caller Cancels", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		serv := service.NewWalletService(blockingRepository{repo})
+		wallet := repository.Wallet{Name: "John Doe", Balance: 1000}
+		serv.OpenAccount(context.Background(), &wallet)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+		// act
+		_, err := serv.Deposit(ctx, wallet.ID, 100)
+		// assert
+		assert.ErrorIs(t, err, service.ErrCanceled)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Caller Deadline Wins", func(t *testing.T) {
+		// arrange
+		repo := repository.NewMemoryWalletRepository()
+		serv := service.NewWalletService(blockingRepository{repo}, service.WithTimeouts(service.Timeouts{Write: time.Hour}))
+		wallet := repository.Wallet{Name: "John Doe", Balance: 1000}
+		serv.OpenAccount(context.Background(), &wallet)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		// act
+		err := serv.AdjustBalance(ctx, wallet.ID, 0, "test")
+		// assert
+		assert.ErrorIs(t, err, service.ErrTimeout)
+	})
+}
@@ -0,0 +1,35 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+var Header = []string{"id", "occurred_at", "type", "amount", "balance"}
+
+// WriteCSV writes the opening and closing balances as the first and last
+// rows so the file reconciles on its own.
+func WriteCSV(w io.Writer, statement Statement) error {
+	writer := csv.NewWriter(w)
+	writer.Write(Header)
+	writer.Write([]string{"", statement.From.Format(time.RFC3339), "OPENING_BALANCE", "", formatAmount(statement.Opening)})
+	for _, transaction := range statement.Transactions {
+		writer.Write([]string{
+			strconv.FormatUint(transaction.ID, 10),
+			transaction.OccurredAt.Format(time.RFC3339),
+			transaction.Type,
+			formatAmount(transaction.Amount),
+			formatAmount(transaction.Balance),
+		})
+	}
+	writer.Write([]string{"", statement.To.Format(time.RFC3339), "CLOSING_BALANCE", "", formatAmount(statement.Closing)})
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatAmount(amount float32) string {
+	return strconv.FormatFloat(float64(amount), 'f', 2, 32)
+}
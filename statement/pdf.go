@@ -0,0 +1,120 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	margin       = 50
+	fontSize     = 10
+	lineHeight   = 14
+	linesPerPage = (pageHeight - 2*margin) / lineHeight
+)
+
+// WritePDF renders the statement as a plain A4 PDF using the built-in
+// Courier font, so columns line up without measuring glyphs.
+func WritePDF(w io.Writer, statement Statement) error {
+	return writePDF(w, paginate(lines(statement)))
+}
+
+func lines(statement Statement) []string {
+	result := []string{
+		"ACCOUNT STATEMENT",
+		"",
+		fmt.Sprintf("Wallet:  %d %s", statement.WalletID, statement.Name),
+		fmt.Sprintf("Period:  %s - %s", statement.From.Format(time.RFC3339), statement.To.Format(time.RFC3339)),
+		"",
+		fmt.Sprintf("%-20s  %-16s  %12s  %12s", "Date", "Type", "Amount", "Balance"),
+		strings.Repeat("-", 66),
+		fmt.Sprintf("%-20s  %-16s  %12s  %12s", statement.From.Format(time.RFC3339), "Opening balance", "", formatAmount(statement.Opening)),
+	}
+	for _, transaction := range statement.Transactions {
+		result = append(result, fmt.Sprintf("%-20s  %-16s  %12s  %12s",
+			transaction.OccurredAt.Format(time.RFC3339),
+			transaction.Type,
+			formatAmount(transaction.Amount),
+			formatAmount(transaction.Balance),
+		))
+	}
+	return append(result,
+		fmt.Sprintf("%-20s  %-16s  %12s  %12s", statement.To.Format(time.RFC3339), "Closing balance", "", formatAmount(statement.Closing)),
+		strings.Repeat("-", 66),
+	)
+}
+
+func paginate(lines []string) [][]string {
+	pages := [][]string{}
+	for len(lines) > linesPerPage {
+		pages = append(pages, lines[:linesPerPage])
+		lines = lines[linesPerPage:]
+	}
+	return append(pages, lines)
+}
+
+// writePDF lays out objects as: 1 catalog, 2 page tree, 3 font, then a
+// page and content stream pair per page.
+func writePDF(w io.Writer, pages [][]string) error {
+	var buf bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>")
+
+	for i, page := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 5+2*i))
+
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, lineHeight, margin, pageHeight-margin)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) '\n", escape(line))
+		}
+		content.WriteString("ET")
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// escape keeps text inside a PDF literal string; characters outside ASCII
+// have no glyph in the standard encoding and become "?".
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
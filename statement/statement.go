@@ -0,0 +1,67 @@
+package statement
+
+import (
+	"context"
+	"gotest/service"
+	"time"
+)
+
+const (
+	FormatCSV = "csv"
+	FormatPDF = "pdf"
+)
+
+// Statement covers the half-open period [From, To). Opening is the balance
+// after the last transaction before From, Closing the balance after the last
+// transaction before To.
+type Statement struct {
+	WalletID     uint64                `json:"wallet_id"`
+	Name         string                `json:"name"`
+	From         time.Time             `json:"from"`
+	To           time.Time             `json:"to"`
+	Opening      float32               `json:"opening_balance"`
+	Closing      float32               `json:"closing_balance"`
+	Transactions []service.Transaction `json:"transactions"`
+}
+
+func Generate(ctx context.Context, walletServ service.WalletService, id uint64, from, to time.Time) (Statement, error) {
+	wallet, err := walletServ.GetAccount(ctx, id)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	transactions, err := walletServ.History(ctx, id)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	statement := Build(transactions, from, to)
+	statement.WalletID = wallet.ID
+	statement.Name = wallet.Name
+	return statement, nil
+}
+
+// Build expects transactions in ledger order, as returned by History.
+func Build(transactions []service.Transaction, from, to time.Time) Statement {
+	statement := Statement{From: from, To: to, Transactions: []service.Transaction{}}
+	for _, transaction := range transactions {
+		switch {
+		case transaction.OccurredAt.Before(from):
+			statement.Opening = transaction.Balance
+		case transaction.OccurredAt.Before(to):
+			statement.Transactions = append(statement.Transactions, transaction)
+		}
+	}
+
+	statement.Closing = statement.Opening
+	if n := len(statement.Transactions); n > 0 {
+		statement.Closing = statement.Transactions[n-1].Balance
+	}
+	return statement
+}
+
+// MonthOf returns the calendar month containing t, in t's location.
+func MonthOf(t time.Time) (time.Time, time.Time) {
+	from := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return from, from.AddDate(0, 1, 0)
+}
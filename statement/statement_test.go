@@ -0,0 +1,147 @@
+// go:build unit
+package statement_test
+
+import (
+	"bytes"
+	"context"
+	"gotest/event"
+	"gotest/repository"
+	"gotest/service"
+	"gotest/statement"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	september = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	october   = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func ledger() []service.Transaction {
+	return []service.Transaction{
+		{ID: 1, WalletID: 1, Type: event.TypeWalletOpened, Amount: 100, Balance: 100, OccurredAt: september.AddDate(0, -1, 0)},
+		{ID: 2, WalletID: 1, Type: event.TypeFundsDeposited, Amount: 50, Balance: 150, OccurredAt: september},
+		{ID: 3, WalletID: 1, Type: event.TypeFundsWithdrawn, Amount: -30, Balance: 120, OccurredAt: september.AddDate(0, 0, 14)},
+		{ID: 4, WalletID: 1, Type: event.TypeFundsDeposited, Amount: 10, Balance: 130, OccurredAt: october},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	t.Run("Period With Transactions", func(t *testing.T) {
+		// act
+		result := statement.Build(ledger(), september, october)
+		// assert
+		assert.Equal(t, float32(100), result.Opening)
+		assert.Equal(t, float32(120), result.Closing)
+		assert.Len(t, result.Transactions, 2)
+		assert.Equal(t, uint64(2), result.Transactions[0].ID)
+	})
+
+	t.Run("Quiet Period", func(t *testing.T) {
+		// act
+		result := statement.Build(ledger(), october.AddDate(0, 0, 1), october.AddDate(0, 1, 0))
+		// assert
+		assert.Equal(t, float32(130), result.Opening)
+		assert.Equal(t, float32(130), result.Closing)
+		assert.Empty(t, result.Transactions)
+	})
+
+	t.Run("Before Wallet Opened", func(t *testing.T) {
+		// act
+		result := statement.Build(ledger(), september.AddDate(-1, 0, 0), september.AddDate(0, -1, 0))
+		// assert
+		assert.Equal(t, float32(0), result.Opening)
+		assert.Equal(t, float32(0), result.Closing)
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("From Ledger", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletService(repository.NewMemoryWalletRepository())
+		wallet := repository.Wallet{Name: "John Doe", Balance: 100}
+		serv.OpenAccount(context.Background(), &wallet)
+		serv.Deposit(context.Background(), wallet.ID, 25)
+		now := time.Now()
+		// act
+		result, err := statement.Generate(context.Background(), serv, wallet.ID, now.Add(-time.Hour), now.Add(time.Hour))
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "John Doe", result.Name)
+		assert.Equal(t, float32(0), result.Opening)
+		assert.Equal(t, float32(125), result.Closing)
+		assert.Len(t, result.Transactions, 2)
+	})
+
+	t.Run("Wallet Not Found", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletService(repository.NewMemoryWalletRepository())
+		// act
+		_, err := statement.Generate(context.Background(), serv, 42, september, october)
+		// assert
+		assert.Equal(t, service.NewErrorWalletNotFound(), err)
+	})
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Run("Opening And Closing Rows", func(t *testing.T) {
+		// arrange
+		var buf bytes.Buffer
+		// act
+		err := statement.WriteCSV(&buf, statement.Build(ledger(), september, october))
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Join([]string{
+			"id,occurred_at,type,amount,balance",
+			",2026-09-01T00:00:00Z,OPENING_BALANCE,,100.00",
+			"2,2026-09-01T00:00:00Z," + event.TypeFundsDeposited + ",50.00,150.00",
+			"3,2026-09-15T00:00:00Z," + event.TypeFundsWithdrawn + ",-30.00,120.00",
+			",2026-10-01T00:00:00Z,CLOSING_BALANCE,,120.00",
+			"",
+		}, "\n"), buf.String())
+	})
+}
+
+func TestWritePDF(t *testing.T) {
+	t.Run("Well Formed", func(t *testing.T) {
+		// arrange
+		var buf bytes.Buffer
+		result := statement.Build(ledger(), september, october)
+		result.Name = "John (JD) Doe"
+		// act
+		err := statement.WritePDF(&buf, result)
+		// assert
+		assert.NoError(t, err)
+		pdf := buf.String()
+		assert.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+		assert.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+		assert.Contains(t, pdf, `John \(JD\) Doe`)
+		assert.Contains(t, pdf, "120.00")
+
+		startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+		offset, _ := strconv.Atoi(startxref[1])
+		assert.True(t, strings.HasPrefix(pdf[offset:], "xref\n"))
+		for i, entry := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf, -1) {
+			offset, _ := strconv.Atoi(entry[1])
+			assert.True(t, strings.HasPrefix(pdf[offset:], strconv.Itoa(i+1)+" 0 obj"))
+		}
+	})
+
+	t.Run("Paginates Long Statements", func(t *testing.T) {
+		// arrange
+		var buf bytes.Buffer
+		transactions := []service.Transaction{}
+		for i := 0; i < 200; i++ {
+			transactions = append(transactions, service.Transaction{ID: uint64(i + 1), Amount: 1, Balance: float32(i + 1), OccurredAt: september})
+		}
+		// act
+		statement.WritePDF(&buf, statement.Build(transactions, september, october))
+		// assert
+		assert.Contains(t, buf.String(), "/Count 4")
+	})
+}
@@ -0,0 +1,159 @@
+package stream
+
+import (
+	"gotest/event"
+	"gotest/repository"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type BalanceUpdate struct {
+	WalletID   uint64    `json:"wallet_id"`
+	Balance    float32   `json:"balance"`
+	EventID    uint64    `json:"event_id,omitempty"`
+	EventType  string    `json:"event_type,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Hub is an event.Publisher that fans balance changes out to the
+// subscribers of each wallet.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[uint64]map[*Subscription]struct{}
+	closed      bool
+	BufferSize  int
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: map[uint64]map[*Subscription]struct{}{}, BufferSize: 16}
+}
+
+type Subscription struct {
+	hub      *Hub
+	walletID uint64
+	ch       chan BalanceUpdate
+	once     sync.Once
+	dropped  uint64
+}
+
+func (s *Subscription) Updates() <-chan BalanceUpdate {
+	return s.ch
+}
+
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	delete(s.hub.subscribers[s.walletID], s)
+	if len(s.hub.subscribers[s.walletID]) == 0 {
+		delete(s.hub.subscribers, s.walletID)
+	}
+	s.close()
+}
+
+func (s *Subscription) close() {
+	s.once.Do(func() { close(s.ch) })
+}
+
+// send never blocks the publisher. A slow subscriber loses its oldest
+// queued update; balances are absolute, so the newest one is what matters.
+func (s *Subscription) send(update BalanceUpdate) {
+	for {
+		select {
+		case s.ch <- update:
+			return
+		default:
+		}
+
+		select {
+		case <-s.ch:
+			atomic.AddUint64(&s.dropped, 1)
+		default:
+		}
+	}
+}
+
+func (h *Hub) Subscribe(walletID uint64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &Subscription{hub: h, walletID: walletID, ch: make(chan BalanceUpdate, h.BufferSize)}
+	if h.closed {
+		sub.close()
+		return sub
+	}
+
+	if h.subscribers[walletID] == nil {
+		h.subscribers[walletID] = map[*Subscription]struct{}{}
+	}
+	h.subscribers[walletID][sub] = struct{}{}
+	return sub
+}
+
+func (h *Hub) Subscribers(walletID uint64) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers[walletID])
+}
+
+func (h *Hub) Publish(e repository.Event) error {
+	update, ok := balanceUpdate(e)
+	if !ok {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers[update.WalletID] {
+		sub.send(update)
+	}
+	return nil
+}
+
+// Close ends every subscription, letting open streams finish during
+// shutdown.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for walletID, subs := range h.subscribers {
+		for sub := range subs {
+			sub.close()
+		}
+		delete(h.subscribers, walletID)
+	}
+}
+
+func balanceUpdate(e repository.Event) (BalanceUpdate, bool) {
+	decoded, err := event.Decode(e)
+	if err != nil {
+		return BalanceUpdate{}, false
+	}
+
+	update := BalanceUpdate{
+		WalletID:   e.WalletID,
+		EventID:    e.ID,
+		EventType:  e.Type,
+		OccurredAt: e.OccurredAt,
+	}
+	switch v := decoded.(type) {
+	case event.WalletOpened:
+		update.Balance = v.Balance
+	case event.FundsDeposited:
+		update.Balance = v.Balance
+	case event.FundsWithdrawn:
+		update.Balance = v.Balance
+	case event.BalanceAdjusted:
+		update.Balance = v.Balance
+	default:
+		return BalanceUpdate{}, false
+	}
+	return update, true
+}
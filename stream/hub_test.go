@@ -0,0 +1,73 @@
+// go:build unit
+package stream_test
+
+import (
+	"gotest/event"
+	"gotest/repository"
+	"gotest/stream"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func deposited(id uint64, walletID uint64, balance float32) repository.Event {
+	e, _ := event.Encode(event.FundsDeposited{WalletID: walletID, Amount: 1, Balance: balance})
+	e.ID = id
+	return e
+}
+
+func TestHubPublish(t *testing.T) {
+	t.Run("Successful", func(t *testing.T) {
+		// arrange
+		hub := stream.NewHub()
+		sub := hub.Subscribe(1)
+		other := hub.Subscribe(2)
+		// act
+		hub.Publish(deposited(1, 1, 1100))
+		// assert
+		update := <-sub.Updates()
+		assert.Equal(t, uint64(1), update.WalletID)
+		assert.Equal(t, float32(1100), update.Balance)
+		assert.Empty(t, other.Updates())
+	})
+
+	t.Run("Ignore Events Without Balance", func(t *testing.T) {
+		// arrange
+		hub := stream.NewHub()
+		sub := hub.Subscribe(1)
+		e, _ := event.Encode(event.TransferCompleted{WalletID: 1, ToWalletID: 2, Amount: 5})
+		// act
+		hub.Publish(e)
+		// assert
+		assert.Empty(t, sub.Updates())
+	})
+
+	t.Run("Slow Subscriber Keeps Latest", func(t *testing.T) {
+		// arrange
+		hub := stream.NewHub()
+		hub.BufferSize = 2
+		sub := hub.Subscribe(1)
+		// act
+		hub.Publish(deposited(1, 1, 100))
+		hub.Publish(deposited(2, 1, 200))
+		hub.Publish(deposited(3, 1, 300))
+		// assert
+		assert.Equal(t, uint64(1), sub.Dropped())
+		assert.Equal(t, float32(200), (<-sub.Updates()).Balance)
+		assert.Equal(t, float32(300), (<-sub.Updates()).Balance)
+	})
+
+	t.Run("Close Ends Subscriptions", func(t *testing.T) {
+		// arrange
+		hub := stream.NewHub()
+		sub := hub.Subscribe(1)
+		// act
+		hub.Close()
+		// assert
+		_, ok := <-sub.Updates()
+		assert.False(t, ok)
+		assert.Equal(t, 0, hub.Subscribers(1))
+		_, ok = <-hub.Subscribe(1).Updates()
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string {
+	return h.c.Get(key)
+}
+
+func (h headerCarrier) Set(key string, value string) {
+	h.c.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := []string{}
+	h.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// Middleware starts a server span for every request, continuing the trace
+// from an incoming W3C traceparent header when there is one. The span is
+// stored in the request's user context so service and repository spans
+// become its children, and the trace context is echoed back on the response.
+func Middleware(provider trace.TracerProvider, propagator propagation.TextMapPropagator) fiber.Handler {
+	tracer := provider.Tracer(instrumentation)
+	return func(c *fiber.Ctx) error {
+		carrier := headerCarrier{c: c}
+		ctx := propagator.Extract(c.UserContext(), carrier)
+
+		method := strings.Clone(c.Method())
+		ctx, span := tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("url.path", strings.Clone(c.Path())),
+		))
+		defer span.End()
+
+		c.SetUserContext(ctx)
+		propagator.Inject(ctx, carrier)
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		route := c.Route().Path
+		span.SetName(fmt.Sprintf("%s %s", method, route))
+		span.SetAttributes(
+			attribute.String("http.route", route),
+			attribute.Int("http.response.status_code", status),
+		)
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprint(status))
+		}
+		return err
+	}
+}
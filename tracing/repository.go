@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"context"
+	"gotest/repository"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type walletRepository struct {
+	next   repository.WalletRepository
+	tracer trace.Tracer
+}
+
+// NewWalletRepository wraps every call to next in a
+// "WalletRepository.<Method>" span, a child of whatever span ctx carries.
+func NewWalletRepository(next repository.WalletRepository, provider trace.TracerProvider) repository.WalletRepository {
+	return walletRepository{next: next, tracer: provider.Tracer(instrumentation)}
+}
+
+func (r walletRepository) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return r.tracer.Start(ctx, "WalletRepository."+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+func (r walletRepository) Get(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	ctx, span := r.start(ctx, "Get", attribute.Int64("wallet.id", int64(id)))
+	wallet, err := r.next.Get(ctx, id)
+	end(span, err)
+	return wallet, err
+}
+
+func (r walletRepository) Create(ctx context.Context, wallet *repository.Wallet, events ...repository.Event) error {
+	ctx, span := r.start(ctx, "Create", attribute.Int("events", len(events)))
+	err := r.next.Create(ctx, wallet, events...)
+	end(span, err)
+	return err
+}
+
+func (r walletRepository) Update(ctx context.Context, id uint64, wallet *repository.Wallet, events ...repository.Event) error {
+	ctx, span := r.start(ctx, "Update", attribute.Int64("wallet.id", int64(id)), attribute.Int("events", len(events)))
+	err := r.next.Update(ctx, id, wallet, events...)
+	end(span, err)
+	return err
+}
+
+func (r walletRepository) UpdateMany(ctx context.Context, wallets []*repository.Wallet, events ...repository.Event) error {
+	ctx, span := r.start(ctx, "UpdateMany", attribute.Int("wallets", len(wallets)), attribute.Int("events", len(events)))
+	err := r.next.UpdateMany(ctx, wallets, events...)
+	end(span, err)
+	return err
+}
+
+func (r walletRepository) List(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) ([]repository.Wallet, string, error) {
+	ctx, span := r.start(ctx, "List", attribute.Int("limit", limit))
+	wallets, next, err := r.next.List(ctx, filter, sort, cursor, limit)
+	end(span, err)
+	return wallets, next, err
+}
+
+func (r walletRepository) Events(ctx context.Context, walletID uint64) ([]repository.Event, error) {
+	ctx, span := r.start(ctx, "Events", attribute.Int64("wallet.id", int64(walletID)))
+	events, err := r.next.Events(ctx, walletID)
+	end(span, err)
+	return events, err
+}
+
+func (r walletRepository) EventsSince(ctx context.Context, walletID uint64, afterID uint64) ([]repository.Event, error) {
+	ctx, span := r.start(ctx, "EventsSince", attribute.Int64("wallet.id", int64(walletID)), attribute.Int64("event.after_id", int64(afterID)))
+	events, err := r.next.EventsSince(ctx, walletID, afterID)
+	end(span, err)
+	return events, err
+}
+
+func (r walletRepository) SaveBalanceSnapshot(ctx context.Context, snapshot repository.BalanceSnapshot) error {
+	ctx, span := r.start(ctx, "SaveBalanceSnapshot", attribute.Int64("wallet.id", int64(snapshot.WalletID)))
+	err := r.next.SaveBalanceSnapshot(ctx, snapshot)
+	end(span, err)
+	return err
+}
+
+func (r walletRepository) BalanceSnapshotAt(ctx context.Context, walletID uint64, at time.Time) (repository.BalanceSnapshot, error) {
+	ctx, span := r.start(ctx, "BalanceSnapshotAt", attribute.Int64("wallet.id", int64(walletID)))
+	snapshot, err := r.next.BalanceSnapshotAt(ctx, walletID, at)
+	end(span, err)
+	return snapshot, err
+}
+
+func (r walletRepository) PendingEvents(ctx context.Context, limit int) ([]repository.Event, error) {
+	ctx, span := r.start(ctx, "PendingEvents", attribute.Int("limit", limit))
+	events, err := r.next.PendingEvents(ctx, limit)
+	end(span, err)
+	return events, err
+}
+
+func (r walletRepository) MarkEventPublished(ctx context.Context, id uint64) error {
+	ctx, span := r.start(ctx, "MarkEventPublished", attribute.Int64("event.id", int64(id)))
+	err := r.next.MarkEventPublished(ctx, id)
+	end(span, err)
+	return err
+}
+
+// Ping is not traced: readiness probes would bury real traces.
+func (r walletRepository) Ping(ctx context.Context) error {
+	return r.next.Ping(ctx)
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"gotest/repository"
+	"gotest/service"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type walletService struct {
+	next   service.WalletService
+	tracer trace.Tracer
+}
+
+// NewWalletService wraps every call to next in a "WalletService.<Method>"
+// span, a child of whatever span ctx carries.
+func NewWalletService(next service.WalletService, provider trace.TracerProvider) service.WalletService {
+	return walletService{next: next, tracer: provider.Tracer(instrumentation)}
+}
+
+func (s walletService) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "WalletService."+method, trace.WithAttributes(attrs...))
+}
+
+func (s walletService) OpenAccount(ctx context.Context, wallet *repository.Wallet) error {
+	ctx, span := s.start(ctx, "OpenAccount")
+	err := s.next.OpenAccount(ctx, wallet)
+	span.SetAttributes(attribute.Int64("wallet.id", int64(wallet.ID)))
+	end(span, err)
+	return err
+}
+
+func (s walletService) GetAccount(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	ctx, span := s.start(ctx, "GetAccount", attribute.Int64("wallet.id", int64(id)))
+	wallet, err := s.next.GetAccount(ctx, id)
+	end(span, err)
+	return wallet, err
+}
+
+func (s walletService) ListAccounts(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) ([]repository.Wallet, string, error) {
+	ctx, span := s.start(ctx, "ListAccounts", attribute.Int("limit", limit))
+	wallets, next, err := s.next.ListAccounts(ctx, filter, sort, cursor, limit)
+	end(span, err)
+	return wallets, next, err
+}
+
+func (s walletService) Withdraw(ctx context.Context, id uint64, amount float32) (float32, error) {
+	ctx, span := s.start(ctx, "Withdraw", attribute.Int64("wallet.id", int64(id)), attribute.Float64("amount", float64(amount)))
+	balance, err := s.next.Withdraw(ctx, id, amount)
+	end(span, err)
+	return balance, err
+}
+
+func (s walletService) Deposit(ctx context.Context, id uint64, amount float32) (float32, error) {
+	ctx, span := s.start(ctx, "Deposit", attribute.Int64("wallet.id", int64(id)), attribute.Float64("amount", float64(amount)))
+	balance, err := s.next.Deposit(ctx, id, amount)
+	end(span, err)
+	return balance, err
+}
+
+func (s walletService) Transfer(ctx context.Context, fromID uint64, toID uint64, amount float32) error {
+	ctx, span := s.start(ctx, "Transfer",
+		attribute.Int64("wallet.from_id", int64(fromID)),
+		attribute.Int64("wallet.to_id", int64(toID)),
+		attribute.Float64("amount", float64(amount)),
+	)
+	err := s.next.Transfer(ctx, fromID, toID, amount)
+	end(span, err)
+	return err
+}
+
+func (s walletService) History(ctx context.Context, id uint64) ([]service.Transaction, error) {
+	ctx, span := s.start(ctx, "History", attribute.Int64("wallet.id", int64(id)))
+	transactions, err := s.next.History(ctx, id)
+	end(span, err)
+	return transactions, err
+}
+
+func (s walletService) GetBalanceAt(ctx context.Context, id uint64, at time.Time) (float32, error) {
+	ctx, span := s.start(ctx, "GetBalanceAt", attribute.Int64("wallet.id", int64(id)), attribute.String("at", at.Format(time.RFC3339)))
+	balance, err := s.next.GetBalanceAt(ctx, id, at)
+	end(span, err)
+	return balance, err
+}
+
+func (s walletService) AdjustBalance(ctx context.Context, id uint64, balance float32, reason string) error {
+	ctx, span := s.start(ctx, "AdjustBalance", attribute.Int64("wallet.id", int64(id)))
+	err := s.next.AdjustBalance(ctx, id, balance, reason)
+	end(span, err)
+	return err
+}
+
+func (s walletService) SetStatus(ctx context.Context, id uint64, status string, reason string) (*repository.Wallet, bool, error) {
+	ctx, span := s.start(ctx, "SetStatus", attribute.Int64("wallet.id", int64(id)), attribute.String("status", status))
+	wallet, changed, err := s.next.SetStatus(ctx, id, status, reason)
+	span.SetAttributes(attribute.Bool("changed", changed))
+	end(span, err)
+	return wallet, changed, err
+}
+
+func (s walletService) Batch(ctx context.Context, ops []service.Operation, mode string) ([]service.OperationResult, error) {
+	ctx, span := s.start(ctx, "Batch", attribute.Int("operations", len(ops)), attribute.String("mode", mode))
+	results, err := s.next.Batch(ctx, ops, mode)
+	end(span, err)
+	return results, err
+}
@@ -0,0 +1,124 @@
+// go:build unit
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"gotest/handler"
+	"gotest/repository"
+	"gotest/service"
+	"gotest/tracing"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
+
+func byName(spans tracetest.SpanStubs) map[string]tracetest.SpanStub {
+	result := map[string]tracetest.SpanStub{}
+	for _, span := range spans {
+		result[span.Name] = span
+	}
+	return result
+}
+
+func newApp(provider *sdktrace.TracerProvider) *fiber.App {
+	repo := tracing.NewWalletRepository(repository.NewMemoryWalletRepository(), provider)
+	serv := tracing.NewWalletService(service.NewWalletService(repo), provider)
+	serv.OpenAccount(context.Background(), &repository.Wallet{Name: "John Doe", Balance: 100})
+
+	app := fiber.New()
+	app.Use(tracing.Middleware(provider, propagation.TraceContext{}))
+	app.Post("/v1/wallets/:id/withdrawals", handler.NewWalletHandler(serv).Withdraw)
+	return app
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("Span Structure", func(t *testing.T) {
+		// arrange
+		provider, exporter := newProvider()
+		app := newApp(provider)
+		exporter.Reset()
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets/1/withdrawals", strings.NewReader(`{"amount": 30}`))
+		req.Header.Set("Content-Type", "application/json")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 200, resp.StatusCode)
+		spans := byName(exporter.GetSpans())
+		server := spans["POST /v1/wallets/:id/withdrawals"]
+		withdraw := spans["WalletService.Withdraw"]
+		get := spans["WalletRepository.Get"]
+		update := spans["WalletRepository.Update"]
+		assert.True(t, server.SpanContext.IsValid())
+		assert.False(t, server.Parent.IsValid())
+		assert.Equal(t, server.SpanContext.SpanID(), withdraw.Parent.SpanID())
+		assert.Equal(t, withdraw.SpanContext.SpanID(), get.Parent.SpanID())
+		assert.Equal(t, withdraw.SpanContext.SpanID(), update.Parent.SpanID())
+		assert.Equal(t, server.SpanContext.TraceID(), update.SpanContext.TraceID())
+		assert.Equal(t, server.SpanContext.TraceID().String(), strings.Split(resp.Header.Get("traceparent"), "-")[1])
+	})
+
+	t.Run("Trace Context Propagation", func(t *testing.T) {
+		// arrange
+		provider, exporter := newProvider()
+		app := newApp(provider)
+		exporter.Reset()
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets/1/withdrawals", strings.NewReader(`{"amount": 30}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		// act
+		app.Test(req)
+		// assert
+		server := byName(exporter.GetSpans())["POST /v1/wallets/:id/withdrawals"]
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+		assert.True(t, server.Parent.IsRemote())
+	})
+
+	t.Run("Error Status", func(t *testing.T) {
+		// arrange
+		provider, exporter := newProvider()
+		app := newApp(provider)
+		exporter.Reset()
+		req := httptest.NewRequest(http.MethodPost, "/v1/wallets/1/withdrawals", strings.NewReader(`{"amount": 500}`))
+		req.Header.Set("Content-Type", "application/json")
+		// act
+		resp, _ := app.Test(req)
+		// assert
+		assert.Equal(t, 400, resp.StatusCode)
+		spans := byName(exporter.GetSpans())
+		assert.Equal(t, codes.Error, spans["WalletService.Withdraw"].Status.Code)
+		assert.Len(t, spans["WalletService.Withdraw"].Events, 1)
+		assert.Equal(t, codes.Unset, spans["POST /v1/wallets/:id/withdrawals"].Status.Code)
+	})
+}
+
+func TestWalletRepository(t *testing.T) {
+	// arrange
+	provider, exporter := newProvider()
+	next := repository.NewWalletRepositoryMock()
+	next.On("Update", uint64(1)).Return(errors.New("database down"))
+	repo := tracing.NewWalletRepository(next, provider)
+	// act
+	err := repo.Update(context.Background(), 1, &repository.Wallet{ID: 1})
+	// assert
+	assert.Error(t, err)
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "WalletRepository.Update", spans[0].Name)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "database down", spans[0].Status.Description)
+}
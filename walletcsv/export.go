@@ -0,0 +1,78 @@
+package walletcsv
+
+import (
+	"context"
+	"encoding/csv"
+	"gotest/repository"
+	"gotest/service"
+	"io"
+	"strconv"
+	"time"
+)
+
+var Header = []string{"id", "name", "balance", "status", "created_at"}
+
+// Page is one page of wallets and the cursor of the page after it.
+type Page struct {
+	Wallets []repository.Wallet
+	Next    string
+}
+
+// FirstPage fetches the page an export starts with, so a caller can report a
+// failure before anything has been written.
+func FirstPage(ctx context.Context, walletServ service.WalletService) (Page, error) {
+	return page(ctx, walletServ, "")
+}
+
+func page(ctx context.Context, walletServ service.WalletService, cursor string) (Page, error) {
+	wallets, next, err := walletServ.ListAccounts(ctx, repository.WalletFilter{}, repository.WalletSort{}, cursor, service.MaxPageSize)
+	return Page{Wallets: wallets, Next: next}, err
+}
+
+// Export writes every wallet ordered by ID, one page at a time, so memory use
+// does not grow with the number of wallets.
+func Export(ctx context.Context, w io.Writer, walletServ service.WalletService) error {
+	first, err := FirstPage(ctx, walletServ)
+	if err != nil {
+		return err
+	}
+	return ExportFrom(ctx, w, walletServ, first)
+}
+
+// ExportFrom is Export starting from a page fetched by FirstPage.
+func ExportFrom(ctx context.Context, w io.Writer, walletServ service.WalletService, first Page) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(Header); err != nil {
+		return err
+	}
+
+	current := first
+	for {
+		for _, wallet := range current.Wallets {
+			err := writer.Write([]string{
+				strconv.FormatUint(wallet.ID, 10),
+				wallet.Name,
+				strconv.FormatFloat(float64(wallet.Balance), 'f', -1, 32),
+				wallet.Status,
+				wallet.CreatedAt.Format(time.RFC3339),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+		if current.Next == "" {
+			return nil
+		}
+
+		next, err := page(ctx, walletServ, current.Next)
+		if err != nil {
+			return err
+		}
+		current = next
+	}
+}
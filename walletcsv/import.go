@@ -0,0 +1,131 @@
+package walletcsv
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"gotest/repository"
+	"gotest/service"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type RowError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportedWallet struct {
+	Line    int     `json:"line"`
+	ID      uint64  `json:"id"`
+	Name    string  `json:"name"`
+	Balance float32 `json:"balance"`
+}
+
+type Report struct {
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Wallets  []ImportedWallet `json:"wallets"`
+	Errors   []RowError       `json:"errors"`
+}
+
+var ErrInvalidHeader = errors.New(`csv header must contain "name" and "balance"`)
+
+// Import opens one wallet per row. The header row names the columns, in any
+// order; unknown columns are ignored. Invalid rows are reported and skipped,
+// the rest are still imported. When ctx ends the import stops, and the report
+// covers the rows read so far.
+func Import(ctx context.Context, r io.Reader, walletServ service.WalletService) (Report, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return Report{}, ErrInvalidHeader
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	nameCol, hasName := columns["name"]
+	balanceCol, hasBalance := columns["balance"]
+	if !hasName || !hasBalance {
+		return Report{}, ErrInvalidHeader
+	}
+
+	report := Report{Wallets: []ImportedWallet{}, Errors: []RowError{}}
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return report, err
+			}
+			report.fail(RowError{Line: parseErr.Line, Message: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		wallet, rowErr := parseRow(record, nameCol, balanceCol)
+		if rowErr != nil {
+			rowErr.Line = line
+			report.fail(*rowErr)
+			continue
+		}
+
+		if err := walletServ.OpenAccount(ctx, &wallet); err != nil {
+			report.fail(RowError{Line: line, Message: service.MessageOf(err)})
+			continue
+		}
+
+		report.Imported++
+		report.Wallets = append(report.Wallets, ImportedWallet{
+			Line:    line,
+			ID:      wallet.ID,
+			Name:    wallet.Name,
+			Balance: wallet.Balance,
+		})
+	}
+	return report, nil
+}
+
+func (r *Report) fail(rowErr RowError) {
+	r.Failed++
+	r.Errors = append(r.Errors, rowErr)
+}
+
+func parseRow(record []string, nameCol, balanceCol int) (repository.Wallet, *RowError) {
+	if nameCol >= len(record) || balanceCol >= len(record) {
+		return repository.Wallet{}, &RowError{Message: "missing columns"}
+	}
+
+	name := strings.TrimSpace(record[nameCol])
+	if name == "" {
+		return repository.Wallet{}, &RowError{Field: "name", Message: "required"}
+	}
+
+	balance, err := strconv.ParseFloat(strings.TrimSpace(record[balanceCol]), 32)
+	if math.IsInf(balance, 0) {
+		return repository.Wallet{}, &RowError{Field: "balance", Message: fmt.Sprintf("out of range: %q", record[balanceCol])}
+	}
+	if err != nil || math.IsNaN(balance) {
+		return repository.Wallet{}, &RowError{Field: "balance", Message: fmt.Sprintf("not a number: %q", record[balanceCol])}
+	}
+	if balance < 0 {
+		return repository.Wallet{}, &RowError{Field: "balance", Message: "must not be negative"}
+	}
+
+	return repository.Wallet{Name: name, Balance: float32(balance)}, nil
+}
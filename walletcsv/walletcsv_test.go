@@ -0,0 +1,100 @@
+// go:build unit
+package walletcsv_test
+
+import (
+	"bytes"
+	"context"
+	"gotest/repository"
+	"gotest/service"
+	"gotest/walletcsv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport(t *testing.T) {
+	t.Run("Successful With Row Errors", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletService(repository.NewMemoryWalletRepository())
+		input := strings.Join([]string{
+			"Balance,Name,Note",
+			"1000,John Doe,vip",
+			"abc,Jane Doe,",
+			"500,,",
+			"-1,Bob,",
+			`"250","Alice ""Al"" Smith",`,
+		}, "\n")
+		// act
+		report, err := walletcsv.Import(context.Background(), strings.NewReader(input), serv)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 2, report.Imported)
+		assert.Equal(t, 3, report.Failed)
+		assert.Equal(t, []walletcsv.RowError{
+			{Line: 3, Field: "balance", Message: `not a number: "abc"`},
+			{Line: 4, Field: "name", Message: "required"},
+			{Line: 5, Field: "balance", Message: "must not be negative"},
+		}, report.Errors)
+		assert.Equal(t, `Alice "Al" Smith`, report.Wallets[1].Name)
+		wallet, _ := serv.GetAccount(context.Background(), report.Wallets[0].ID)
+		assert.Equal(t, float32(1000), wallet.Balance)
+	})
+
+	t.Run("Invalid Header", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		// act
+		_, err := walletcsv.Import(context.Background(), strings.NewReader("id,amount\n1,2\n"), serv)
+		// assert
+		assert.ErrorIs(t, err, walletcsv.ErrInvalidHeader)
+		serv.AssertNotCalled(t, "OpenAccount")
+	})
+
+	t.Run("Non Finite Balance", func(t *testing.T) {
+		// arrange
+		serv := service.NewWalletServiceMock()
+		input := "name,balance\nJohn Doe,NaN\nJane Doe,+Inf\nBob,1e39\nAlice,-1e39\n"
+		// act
+		report, err := walletcsv.Import(context.Background(), strings.NewReader(input), serv)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 0, report.Imported)
+		assert.Equal(t, []walletcsv.RowError{
+			{Line: 2, Field: "balance", Message: `not a number: "NaN"`},
+			{Line: 3, Field: "balance", Message: `out of range: "+Inf"`},
+			{Line: 4, Field: "balance", Message: `out of range: "1e39"`},
+			{Line: 5, Field: "balance", Message: `out of range: "-1e39"`},
+		}, report.Errors)
+		serv.AssertNotCalled(t, "OpenAccount")
+	})
+
+	t.Run("Service Error", func(t *testing.T) {
+		// arrange
+		wallet := repository.Wallet{Name: "John Doe", Balance: 1000}
+		serv := service.NewWalletServiceMock()
+		serv.On("OpenAccount", &wallet).Return(service.NewErrorWalletUnexpected())
+		// act
+		report, err := walletcsv.Import(context.Background(), strings.NewReader("name,balance\nJohn Doe,1000\n"), serv)
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []walletcsv.RowError{{Line: 2, Message: "UNEXPECTED ERROR"}}, report.Errors)
+	})
+}
+
+func TestExport(t *testing.T) {
+	// arrange
+	serv := service.NewWalletService(repository.NewMemoryWalletRepository())
+	for i := 0; i < service.MaxPageSize+5; i++ {
+		serv.OpenAccount(context.Background(), &repository.Wallet{Name: "John, Doe", Balance: 10.5})
+	}
+	var buff bytes.Buffer
+	// act
+	err := walletcsv.Export(context.Background(), &buff, serv)
+	// assert
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	assert.Len(t, lines, service.MaxPageSize+6)
+	assert.Equal(t, "id,name,balance,status,created_at", lines[0])
+	assert.True(t, strings.HasPrefix(lines[105], `105,"John, Doe",10.5,ACTIVE,`))
+}
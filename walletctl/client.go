@@ -0,0 +1,87 @@
+// Package walletctl implements the walletctl admin command. It works either
+// in-process against a WalletService over the configured repository, or
+// against a running server's HTTP API. File-backed storage must not be opened
+// directly while a server is using it; go through the API instead.
+package walletctl
+
+import (
+	"context"
+	"errors"
+	"gotest/audit"
+	"gotest/auth"
+	"gotest/reconcile"
+	"gotest/repository"
+	"gotest/service"
+)
+
+var ErrUnsupported = errors.New("not available over the HTTP API; use -store and -db instead of -api")
+
+// Client is what walletctl needs from a wallet backend.
+type Client interface {
+	Open(ctx context.Context, name string, balance float32) (*repository.Wallet, error)
+	Get(ctx context.Context, id uint64) (*repository.Wallet, error)
+	Deposit(ctx context.Context, id uint64, amount float32) (float32, error)
+	Withdraw(ctx context.Context, id uint64, amount float32) (float32, error)
+	SetStatus(ctx context.Context, id uint64, status string, reason string) (*repository.Wallet, error)
+	List(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) ([]repository.Wallet, string, error)
+	History(ctx context.Context, id uint64) ([]service.Transaction, error)
+	Reconcile(ctx context.Context, fix bool) (reconcile.Report, error)
+}
+
+// serviceClient calls the service in-process through the audit decorator,
+// with actor as the identity, so every change is recorded as the HTTP and
+// gRPC APIs record theirs. Whoever can open the storage directly is trusted
+// to name themselves.
+type serviceClient struct {
+	walletServ service.WalletService
+	auditLog   audit.Logger
+	actor      string
+}
+
+func NewServiceClient(walletServ service.WalletService, auditLog audit.Logger, actor string) Client {
+	return serviceClient{walletServ: audit.NewWalletService(walletServ, auditLog), auditLog: auditLog, actor: actor}
+}
+
+func (c serviceClient) context(ctx context.Context) context.Context {
+	return auth.WithIdentity(ctx, auth.Identity{Name: c.actor})
+}
+
+func (c serviceClient) Open(ctx context.Context, name string, balance float32) (*repository.Wallet, error) {
+	wallet := repository.Wallet{Name: name, Balance: balance}
+	if err := c.walletServ.OpenAccount(c.context(ctx), &wallet); err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+func (c serviceClient) Get(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	return c.walletServ.GetAccount(ctx, id)
+}
+
+func (c serviceClient) Deposit(ctx context.Context, id uint64, amount float32) (float32, error) {
+	return c.walletServ.Deposit(c.context(ctx), id, amount)
+}
+
+func (c serviceClient) Withdraw(ctx context.Context, id uint64, amount float32) (float32, error) {
+	return c.walletServ.Withdraw(c.context(ctx), id, amount)
+}
+
+func (c serviceClient) SetStatus(ctx context.Context, id uint64, status string, reason string) (*repository.Wallet, error) {
+	wallet, _, err := c.walletServ.SetStatus(c.context(ctx), id, status, reason)
+	return wallet, err
+}
+
+func (c serviceClient) List(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) ([]repository.Wallet, string, error) {
+	return c.walletServ.ListAccounts(ctx, filter, sort, cursor, limit)
+}
+
+func (c serviceClient) History(ctx context.Context, id uint64) ([]service.Transaction, error) {
+	return c.walletServ.History(ctx, id)
+}
+
+func (c serviceClient) Reconcile(ctx context.Context, fix bool) (reconcile.Report, error) {
+	reconciler := reconcile.NewReconciler(c.walletServ, c.auditLog)
+	reconciler.Fix = fix
+	reconciler.Actor = c.actor
+	return reconciler.Run(ctx)
+}
@@ -0,0 +1,316 @@
+package walletctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"gotest/audit"
+	"gotest/config"
+	"gotest/repository"
+	"gotest/service"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const Usage = `usage: walletctl [-api url | -config file.yaml -store memory|events -db file -audit-log file] [-o table|json] [-actor name] [-token token] <command>
+
+commands:
+  open <name> <balance>
+  get <id>
+  deposit <id> <amount>
+  withdraw <id> <amount>
+  freeze [-reason text] <id>
+  unfreeze [-reason text] <id>
+  list [-name prefix] [-status status] [-min-balance n] [-max-balance n] [-sort field] [-limit n] [-cursor c] [-all]
+  history <id>
+  reconcile [-fix]`
+
+// ErrUsage is returned for a malformed command line.
+var ErrUsage = errors.New("invalid usage")
+
+func usageError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrUsage, fmt.Sprintf(format, args...))
+}
+
+// storageFlags are passed on to config.Load, so direct access reads the
+// same file, WALLET_* variables and defaults as serve.
+var storageFlags = []string{"config", "store", "db", "audit-log"}
+
+// Run executes one walletctl command line. Without -api it opens the
+// configured repository itself.
+func Run(ctx context.Context, args []string, environ []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("walletctl", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	api := flags.String("api", "", "base URL of a running server, e.g. http://localhost:8000")
+	output := flags.String("o", OutputTable, "output format: table or json")
+	actor := flags.String("actor", lookup(environ, "USER"), "name recorded in the audit log")
+	token := flags.String("token", lookup(environ, "WALLET_TOKEN"), "bearer token for -api, required by freeze and unfreeze")
+	for _, name := range storageFlags {
+		flags.String(name, "", "see serve")
+	}
+	if err := flags.Parse(args); err != nil {
+		return usageError("%s", err)
+	}
+	if *output != OutputTable && *output != OutputJSON {
+		return usageError("unknown output format %q", *output)
+	}
+	if flags.NArg() == 0 {
+		return usageError("missing command")
+	}
+	if *actor == "" {
+		*actor = "walletctl"
+	}
+
+	var client Client
+	if *api != "" {
+		client = NewHTTPClient(*api, nil, *actor, *token)
+	} else {
+		forwarded := []string{}
+		flags.Visit(func(f *flag.Flag) {
+			for _, name := range storageFlags {
+				if f.Name == name {
+					forwarded = append(forwarded, "-"+name, f.Value.String())
+				}
+			}
+		})
+		cfg, err := config.Load("walletctl", forwarded, environ)
+		if err != nil {
+			return err
+		}
+
+		walletRepo, err := cfg.Storage.Open()
+		if err != nil {
+			return err
+		}
+		auditLog, err := audit.NewFileSink(cfg.Audit.Path)
+		if err != nil {
+			return err
+		}
+		defer auditLog.Close()
+
+		walletServ := service.NewWalletService(walletRepo,
+			service.WithTimeouts(cfg.Timeouts()),
+			service.WithPolicy(cfg.Policy),
+		)
+		client = NewServiceClient(walletServ, auditLog, *actor)
+	}
+
+	return execute(ctx, client, printer{w: stdout, format: *output}, flags.Args())
+}
+
+func lookup(environ []string, key string) string {
+	for _, entry := range environ {
+		if k, v, ok := strings.Cut(entry, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+func execute(ctx context.Context, client Client, out printer, args []string) error {
+	command, args := args[0], args[1:]
+	switch command {
+	case "open":
+		if len(args) != 2 {
+			return usageError("open needs <name> <balance>")
+		}
+		balance, err := parseAmount(args[1])
+		if err != nil {
+			return err
+		}
+		wallet, err := client.Open(ctx, args[0], balance)
+		if err != nil {
+			return err
+		}
+		return out.wallet(wallet)
+	case "get":
+		id, err := parseID(command, args)
+		if err != nil {
+			return err
+		}
+		wallet, err := client.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		return out.wallet(wallet)
+	case "deposit", "withdraw":
+		if len(args) != 2 {
+			return usageError("%s needs <id> <amount>", command)
+		}
+		id, err := parseID(command, args[:1])
+		if err != nil {
+			return err
+		}
+		amount, err := parseAmount(args[1])
+		if err != nil {
+			return err
+		}
+		move := client.Deposit
+		if command == "withdraw" {
+			move = client.Withdraw
+		}
+		balance, err := move(ctx, id, amount)
+		if err != nil {
+			return err
+		}
+		return out.balance(id, balance)
+	case "freeze", "unfreeze":
+		return setStatus(ctx, client, out, command, args)
+	case "list":
+		return list(ctx, client, out, args)
+	case "history":
+		id, err := parseID(command, args)
+		if err != nil {
+			return err
+		}
+		transactions, err := client.History(ctx, id)
+		if err != nil {
+			return err
+		}
+		return out.transactions(transactions)
+	case "reconcile":
+		return reconcileWallets(ctx, client, out, args)
+	default:
+		return usageError("unknown command %q", command)
+	}
+}
+
+func parseID(command string, args []string) (uint64, error) {
+	if len(args) != 1 {
+		return 0, usageError("%s needs <id>", command)
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return 0, usageError("invalid wallet id %q", args[0])
+	}
+	return id, nil
+}
+
+// parseAmount accepts the finite, positive amounts the service does, so a
+// typo is reported as usage rather than sent on.
+func parseAmount(raw string) (float32, error) {
+	amount, err := parseNumber(raw)
+	if err != nil || amount <= 0 {
+		return 0, usageError("invalid amount %q", raw)
+	}
+	return amount, nil
+}
+
+func parseBound(raw string) (*float32, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	bound, err := parseNumber(raw)
+	if err != nil {
+		return nil, usageError("invalid balance bound %q", raw)
+	}
+	return &bound, nil
+}
+
+// parseNumber refuses NaN, infinities and values that overflow float32.
+func parseNumber(raw string) (float32, error) {
+	number, err := strconv.ParseFloat(raw, 32)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, fmt.Errorf("invalid number %q", raw)
+	}
+	return float32(number), nil
+}
+
+func setStatus(ctx context.Context, client Client, out printer, command string, args []string) error {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	reason := flags.String("reason", "", "why the status changes, kept in the event and audit log")
+	if err := flags.Parse(args); err != nil {
+		return usageError("%s", err)
+	}
+	id, err := parseID(command, flags.Args())
+	if err != nil {
+		return err
+	}
+
+	status := repository.StatusFrozen
+	if command == "unfreeze" {
+		status = repository.StatusActive
+	}
+	wallet, err := client.SetStatus(ctx, id, status, *reason)
+	if err != nil {
+		return err
+	}
+	return out.wallet(wallet)
+}
+
+// list prints one page, or with -all follows the cursor to the last page.
+func list(ctx context.Context, client Client, out printer, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	name := flags.String("name", "", "name prefix")
+	status := flags.String("status", "", "ACTIVE, FROZEN or CLOSED")
+	minBalance := flags.String("min-balance", "", "lowest balance")
+	maxBalance := flags.String("max-balance", "", "highest balance")
+	sortBy := flags.String("sort", "", "id, name, balance or created_at; prefix - for descending")
+	limit := flags.Int("limit", 0, "page size")
+	cursor := flags.String("cursor", "", "next cursor of the previous page")
+	all := flags.Bool("all", false, "fetch every page")
+	if err := flags.Parse(args); err != nil {
+		return usageError("%s", err)
+	}
+	if flags.NArg() > 0 {
+		return usageError("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	filter := repository.WalletFilter{NamePrefix: *name, Status: strings.ToUpper(*status)}
+	var err error
+	if filter.MinBalance, err = parseBound(*minBalance); err != nil {
+		return err
+	}
+	if filter.MaxBalance, err = parseBound(*maxBalance); err != nil {
+		return err
+	}
+
+	sort := repository.WalletSort{Field: *sortBy}
+	if strings.HasPrefix(sort.Field, "-") {
+		sort = repository.WalletSort{Field: sort.Field[1:], Desc: true}
+	}
+
+	wallets := []repository.Wallet{}
+	next := *cursor
+	for {
+		page, pageNext, err := client.List(ctx, filter, sort, next, *limit)
+		if err != nil {
+			return err
+		}
+		wallets = append(wallets, page...)
+		next = pageNext
+		if !*all || next == "" {
+			break
+		}
+	}
+	return out.page(wallets, next)
+}
+
+// reconcileWallets fails when wallets are left out of balance, like the
+// reconcile command of the server binary.
+func reconcileWallets(ctx context.Context, client Client, out printer, args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	fix := flags.Bool("fix", false, "adjust drifted balances to the ledger total")
+	if err := flags.Parse(args); err != nil {
+		return usageError("%s", err)
+	}
+
+	report, err := client.Reconcile(ctx, *fix)
+	if err != nil {
+		return err
+	}
+	if err := out.report(report); err != nil {
+		return err
+	}
+
+	if unresolved := report.Unresolved(); unresolved > 0 {
+		return fmt.Errorf("%d wallets out of balance", unresolved)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package walletctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gotest/handler"
+	"gotest/reconcile"
+	"gotest/repository"
+	"gotest/service"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError is a response outside 2xx; Message is the body the server sent.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e APIError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.StatusCode)
+}
+
+// httpClient calls the v1 API at baseURL, sending actor as X-Actor. The
+// server's audit log keeps it as an unverified claim next to the identity of
+// token, or "anonymous" on routes that take none.
+type httpClient struct {
+	baseURL string
+	client  *http.Client
+	actor   string
+	token   string
+}
+
+func NewHTTPClient(baseURL string, client *http.Client, actor string, token string) Client {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return httpClient{baseURL: strings.TrimRight(baseURL, "/") + "/v1", client: client, actor: actor, token: token}
+}
+
+func (c httpClient) do(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(content)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-Actor", c.actor)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (c httpClient) Open(ctx context.Context, name string, balance float32) (*repository.Wallet, error) {
+	wallet := repository.Wallet{}
+	err := c.do(ctx, http.MethodPost, "/wallets", repository.Wallet{Name: name, Balance: balance}, &wallet)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+func (c httpClient) Get(ctx context.Context, id uint64) (*repository.Wallet, error) {
+	wallet := repository.Wallet{}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/wallets/%d", id), nil, &wallet); err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+func (c httpClient) Deposit(ctx context.Context, id uint64, amount float32) (float32, error) {
+	result := handler.BalanceResponse{}
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/wallets/%d/deposits", id), handler.TransactionRequest{Amount: amount}, &result)
+	return result.Balance, err
+}
+
+func (c httpClient) Withdraw(ctx context.Context, id uint64, amount float32) (float32, error) {
+	result := handler.BalanceResponse{}
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/wallets/%d/withdrawals", id), handler.TransactionRequest{Amount: amount}, &result)
+	return result.Balance, err
+}
+
+func (c httpClient) SetStatus(ctx context.Context, id uint64, status string, reason string) (*repository.Wallet, error) {
+	wallet := repository.Wallet{}
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/wallets/%d/status", id), handler.StatusRequest{Status: status, Reason: reason}, &wallet)
+	if err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+func (c httpClient) List(ctx context.Context, filter repository.WalletFilter, sort repository.WalletSort, cursor string, limit int) ([]repository.Wallet, string, error) {
+	query := url.Values{}
+	setQuery(query, "name", filter.NamePrefix)
+	setQuery(query, "status", filter.Status)
+	if filter.MinBalance != nil {
+		query.Set("min_balance", strconv.FormatFloat(float64(*filter.MinBalance), 'f', -1, 32))
+	}
+	if filter.MaxBalance != nil {
+		query.Set("max_balance", strconv.FormatFloat(float64(*filter.MaxBalance), 'f', -1, 32))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query.Set("created_after", filter.CreatedAfter.Format(time.RFC3339))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query.Set("created_before", filter.CreatedBefore.Format(time.RFC3339))
+	}
+	if sort.Field != "" {
+		if sort.Desc {
+			query.Set("sort", "-"+sort.Field)
+		} else {
+			query.Set("sort", sort.Field)
+		}
+	}
+	setQuery(query, "cursor", cursor)
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	page := handler.WalletPage{}
+	if err := c.do(ctx, http.MethodGet, "/wallets?"+query.Encode(), nil, &page); err != nil {
+		return nil, "", err
+	}
+	return page.Items, page.NextCursor, nil
+}
+
+func setQuery(query url.Values, key string, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+func (c httpClient) History(ctx context.Context, id uint64) ([]service.Transaction, error) {
+	transactions := []service.Transaction{}
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/wallets/%d/transactions", id), nil, &transactions)
+	return transactions, err
+}
+
+// Reconcile needs the ledger and the stored balances side by side, which the
+// API does not expose as one operation.
+func (c httpClient) Reconcile(ctx context.Context, fix bool) (reconcile.Report, error) {
+	return reconcile.Report{}, ErrUnsupported
+}
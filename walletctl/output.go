@@ -0,0 +1,101 @@
+package walletctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"gotest/handler"
+	"gotest/reconcile"
+	"gotest/repository"
+	"gotest/service"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// printer writes command results. JSON output is the same shape the HTTP
+// API returns, so scripts can switch between the two.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func (p printer) json(v interface{}) error {
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func (p printer) table(header string, rows func(w io.Writer)) error {
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, header)
+	rows(tw)
+	return tw.Flush()
+}
+
+func (p printer) page(wallets []repository.Wallet, next string) error {
+	if p.format == OutputJSON {
+		return p.json(handler.WalletPage{Items: wallets, NextCursor: next})
+	}
+
+	if err := p.walletRows(wallets); err != nil || next == "" {
+		return err
+	}
+	_, err := fmt.Fprintf(p.w, "next cursor: %s\n", next)
+	return err
+}
+
+func (p printer) wallet(wallet *repository.Wallet) error {
+	if p.format == OutputJSON {
+		return p.json(wallet)
+	}
+	return p.walletRows([]repository.Wallet{*wallet})
+}
+
+func (p printer) walletRows(wallets []repository.Wallet) error {
+	return p.table("ID\tNAME\tBALANCE\tSTATUS\tCREATED", func(w io.Writer) {
+		for _, wallet := range wallets {
+			fmt.Fprintf(w, "%d\t%s\t%.2f\t%s\t%s\n", wallet.ID, wallet.Name, wallet.Balance, wallet.Status, wallet.CreatedAt.Format(time.RFC3339))
+		}
+	})
+}
+
+func (p printer) balance(id uint64, balance float32) error {
+	if p.format == OutputJSON {
+		return p.json(handler.BalanceResponse{ID: id, Balance: balance})
+	}
+	return p.table("ID\tBALANCE", func(w io.Writer) {
+		fmt.Fprintf(w, "%d\t%.2f\n", id, balance)
+	})
+}
+
+func (p printer) transactions(transactions []service.Transaction) error {
+	if p.format == OutputJSON {
+		return p.json(transactions)
+	}
+	return p.table("ID\tTYPE\tAMOUNT\tBALANCE\tOCCURRED", func(w io.Writer) {
+		for _, t := range transactions {
+			fmt.Fprintf(w, "%d\t%s\t%.2f\t%.2f\t%s\n", t.ID, t.Type, t.Amount, t.Balance, t.OccurredAt.Format(time.RFC3339))
+		}
+	})
+}
+
+func (p printer) report(report reconcile.Report) error {
+	if p.format == OutputJSON {
+		return p.json(report)
+	}
+
+	_, err := fmt.Fprintf(p.w, "checked %d, mismatched %d, adjusted %d\n", report.Checked, report.Mismatched, report.Adjusted)
+	if err != nil || len(report.Mismatches) == 0 {
+		return err
+	}
+	return p.table("WALLET\tSTORED\tLEDGER\tDIFFERENCE\tADJUSTED\tERROR", func(w io.Writer) {
+		for _, m := range report.Mismatches {
+			fmt.Fprintf(w, "%d\t%.2f\t%.2f\t%.2f\t%t\t%s\n", m.WalletID, m.Stored, m.Ledger, m.Difference, m.Adjusted, m.Error)
+		}
+	})
+}
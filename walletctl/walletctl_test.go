@@ -0,0 +1,168 @@
+// go:build unit
+package walletctl_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"gotest/auth"
+	"gotest/handler"
+	"gotest/repository"
+	"gotest/service"
+	"gotest/walletctl"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// direct runs walletctl against event-sourced storage in dir.
+func direct(dir string) func(args ...string) (string, error) {
+	return func(args ...string) (string, error) {
+		var out bytes.Buffer
+		args = append([]string{"-store", "events", "-db", filepath.Join(dir, "events.json"), "-audit-log", filepath.Join(dir, "audit.log"), "-actor", "alice"}, args...)
+		err := walletctl.Run(context.Background(), args, nil, &out)
+		return out.String(), err
+	}
+}
+
+// remote runs walletctl against a server listening on a random port.
+func remote(t *testing.T) func(args ...string) (string, error) {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	serv := service.NewWalletService(repository.NewMemoryWalletRepository())
+	tokens := auth.Tokens{"s3cret": {Name: "alice", Scopes: []string{auth.ScopeAdmin}}}
+	handler.RegisterRoutes(app, handler.Handlers{Wallet: handler.NewWalletHandler(serv), Auth: tokens})
+	go app.Listener(listener)
+	t.Cleanup(func() { app.Shutdown() })
+
+	return func(args ...string) (string, error) {
+		var out bytes.Buffer
+		args = append([]string{"-api", "http://" + listener.Addr().String(), "-token", "s3cret"}, args...)
+		err := walletctl.Run(context.Background(), args, nil, &out)
+		return out.String(), err
+	}
+}
+
+func TestCommands(t *testing.T) {
+	backends := map[string]func(args ...string) (string, error){
+		"Direct": direct(t.TempDir()),
+		"HTTP":   remote(t),
+	}
+
+	for name, run := range backends {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			run("open", "John Doe", "100")
+			run("open", "Jane Doe", "50")
+			// act
+			deposited, depositErr := run("-o", "json", "deposit", "1", "25")
+			frozen, freezeErr := run("freeze", "-reason", "fraud check", "1")
+			_, frozenErr := run("withdraw", "1", "10")
+			_, unfreezeErr := run("unfreeze", "1")
+			withdrawn, withdrawErr := run("withdraw", "1", "5")
+			history, historyErr := run("-o", "json", "history", "1")
+			listed, listErr := run("-o", "json", "list", "-sort", "-balance", "-limit", "1", "-all")
+			// assert
+			assert.NoError(t, depositErr)
+			assert.JSONEq(t, `{"id":1,"balance":125}`, deposited)
+
+			assert.NoError(t, freezeErr)
+			assert.Contains(t, frozen, "FROZEN")
+			assert.ErrorContains(t, frozenErr, "WALLET FROZEN")
+
+			assert.NoError(t, unfreezeErr)
+			assert.NoError(t, withdrawErr)
+			assert.Equal(t, "ID  BALANCE\n1   120.00\n", withdrawn)
+
+			assert.NoError(t, historyErr)
+			transactions := []service.Transaction{}
+			json.Unmarshal([]byte(history), &transactions)
+			amounts := []float32{}
+			for _, transaction := range transactions {
+				amounts = append(amounts, transaction.Amount)
+			}
+			assert.Equal(t, []float32{100, 25, -5}, amounts)
+
+			assert.NoError(t, listErr)
+			page := handler.WalletPage{}
+			json.Unmarshal([]byte(listed), &page)
+			assert.Len(t, page.Items, 2)
+			assert.Equal(t, "John Doe", page.Items[0].Name)
+			assert.Empty(t, page.NextCursor)
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	t.Run("Direct", func(t *testing.T) {
+		// arrange
+		dir := t.TempDir()
+		run := direct(dir)
+		run("open", "John Doe", "100")
+		// act
+		out, err := run("reconcile")
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "checked 1, mismatched 0, adjusted 0\n", out)
+		log, _ := os.ReadFile(filepath.Join(dir, "audit.log"))
+		assert.Contains(t, string(log), `"actor":"alice"`)
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		// arrange
+		run := remote(t)
+		// act
+		_, err := run("reconcile")
+		// assert
+		assert.ErrorIs(t, err, walletctl.ErrUnsupported)
+	})
+}
+
+func TestErrors(t *testing.T) {
+	run := direct(t.TempDir())
+
+	t.Run("Usage", func(t *testing.T) {
+		for _, args := range [][]string{
+			{},
+			{"launch"},
+			{"get"},
+			{"get", "one"},
+			{"deposit", "1", "lots"},
+			{"deposit", "1", "NaN"},
+			{"deposit", "1", "Inf"},
+			{"withdraw", "1", "-5"},
+			{"withdraw", "1", "0"},
+			{"deposit", "1", "1e39"},
+			{"open", "John", "-100"},
+			{"list", "-min-balance", "NaN"},
+			{"-o", "yaml", "get", "1"},
+			{"list", "extra"},
+		} {
+			// act
+			_, err := run(args...)
+			// assert
+			assert.ErrorIs(t, err, walletctl.ErrUsage, strings.Join(args, " "))
+		}
+	})
+
+	t.Run("Wallet Not Found", func(t *testing.T) {
+		// act
+		_, err := run("get", "9")
+		// assert
+		assert.ErrorIs(t, err, service.ErrWalletNotFound)
+	})
+
+	t.Run("API Error", func(t *testing.T) {
+		// arrange
+		run := remote(t)
+		// act
+		_, err := run("get", "9")
+		// assert
+		assert.Equal(t, walletctl.APIError{StatusCode: 404, Message: "WALLET NOT FOUND"}, err)
+	})
+}
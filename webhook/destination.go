@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenDestination is returned for webhook URLs that point into the
+// service's own network, which would let subscribers probe it.
+var ErrForbiddenDestination = errors.New("webhook destination not allowed")
+
+// Public reports whether deliveries may connect to addr: loopback, private,
+// link-local, multicast and unspecified addresses are refused.
+func Public(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified()
+}
+
+// CheckHost refuses a host that is, or resolves to, an address allow refuses.
+// A name that does not resolve yet is let through: the dispatcher checks
+// every address again when it connects, which also covers names that change
+// after the subscription was made.
+func CheckHost(ctx context.Context, host string, allow func(netip.Addr) bool) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !allow(addr) {
+			return fmt.Errorf("%w: %s", ErrForbiddenDestination, host)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !allow(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenDestination, host, addr)
+		}
+	}
+	return nil
+}
+
+// guardedClient only connects to addresses allow accepts, checked after name
+// resolution so DNS cannot point a delivery inward. It ignores proxy
+// settings, which would otherwise hide the real destination from the check.
+func guardedClient(timeout time.Duration, allow func(netip.Addr) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !allow(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrForbiddenDestination, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,62 @@
+// go:build unit
+package webhook_test
+
+import (
+	"context"
+	"gotest/webhook"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublic(t *testing.T) {
+	for _, tc := range []struct {
+		addr   string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+	} {
+		t.Run(tc.addr, func(t *testing.T) {
+			// act
+			public := webhook.Public(netip.MustParseAddr(tc.addr))
+			// assert
+			assert.Equal(t, tc.public, public)
+		})
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	t.Run("Public Address", func(t *testing.T) {
+		// act
+		err := webhook.CheckHost(context.Background(), "93.184.216.34", webhook.Public)
+		// assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Private Address", func(t *testing.T) {
+		// act
+		err := webhook.CheckHost(context.Background(), "169.254.169.254", webhook.Public)
+		// assert
+		assert.ErrorIs(t, err, webhook.ErrForbiddenDestination)
+	})
+
+	t.Run("Name Resolving To Loopback", func(t *testing.T) {
+		// act
+		err := webhook.CheckHost(context.Background(), "localhost", webhook.Public)
+		// assert
+		assert.ErrorIs(t, err, webhook.ErrForbiddenDestination)
+	})
+}
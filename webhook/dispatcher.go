@@ -0,0 +1,257 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gotest/repository"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by Publish once Close has been called.
+var ErrClosed = errors.New("webhook dispatcher closed")
+
+// Dispatcher is an event.Publisher that posts every event to the matching
+// subscriptions. Each subscription has a queue and a goroutine of its own, so
+// Publish returns once the deliveries are queued and a slow or broken
+// endpoint only delays its own events. Failed deliveries are retried with
+// exponential backoff and end up in the dead-letter store, as do events for
+// a subscription whose queue is full.
+type Dispatcher struct {
+	store       Store
+	client      *http.Client
+	now         func() time.Time
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	QueueSize   int
+	// Allow decides which addresses deliveries may connect to; it defaults
+	// to Public.
+	Allow func(addr netip.Addr) bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	mu     sync.Mutex
+	closed bool
+	queues map[uint64]chan job
+	wg     sync.WaitGroup
+}
+
+type job struct {
+	sub   Subscription
+	event repository.Event
+	body  []byte
+}
+
+func NewDispatcher(store Store) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		store:       store,
+		now:         time.Now,
+		MaxAttempts: 5,
+		Backoff:     500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+		QueueSize:   1000,
+		Allow:       Public,
+		ctx:         ctx,
+		cancel:      cancel,
+		queues:      map[uint64]chan job{},
+	}
+	d.client = guardedClient(10*time.Second, func(addr netip.Addr) bool {
+		return d.Allow(addr)
+	})
+	return d
+}
+
+// Publish queues e for every subscription that accepts it. It only fails
+// when the store does.
+func (d *Dispatcher) Publish(e repository.Event) error {
+	subs, err := d.store.ListSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return ErrClosed
+	}
+
+	for _, sub := range subs {
+		if !sub.Accepts(e.Type) {
+			continue
+		}
+
+		select {
+		case d.queue(sub.ID) <- job{sub: sub, event: e, body: body}:
+		default:
+			if err := d.deadLetter(sub, e, 0, "delivery queue full"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// queue returns the queue of a subscription, starting its goroutine on
+// first use. d.mu must be held.
+func (d *Dispatcher) queue(subscriptionID uint64) chan job {
+	queue, ok := d.queues[subscriptionID]
+	if !ok {
+		queue = make(chan job, d.QueueSize)
+		d.queues[subscriptionID] = queue
+		d.wg.Add(1)
+		go d.loop(subscriptionID, queue)
+	}
+	return queue
+}
+
+// loop delivers a subscription's events in order. Once the subscription is
+// deleted it skips the rest of its queue and stops.
+func (d *Dispatcher) loop(subscriptionID uint64, queue chan job) {
+	defer d.wg.Done()
+	for j := range queue {
+		if _, err := d.store.GetSubscription(subscriptionID); errors.Is(err, ErrSubscriptionNotFound) {
+			if d.stop(subscriptionID, queue) {
+				return
+			}
+			continue
+		}
+		// Store errors have nowhere to go; the delivery log simply misses
+		// the attempt.
+		d.deliver(j)
+	}
+}
+
+// stop removes an empty queue so Publish starts afresh if the subscription
+// ID ever comes back.
+func (d *Dispatcher) stop(subscriptionID uint64, queue chan job) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(queue) > 0 || d.closed {
+		return false
+	}
+	delete(d.queues, subscriptionID)
+	return true
+}
+
+// Close stops accepting events and waits until the queued ones have been
+// delivered or dead-lettered. When ctx ends first, pending requests and
+// backoff waits are cut short and the remaining events are dead-lettered.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, queue := range d.queues {
+			close(queue)
+		}
+	}
+	d.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-drained
+		return ctx.Err()
+	}
+}
+
+// deliver only returns store errors; endpoint failures are dead-lettered.
+func (d *Dispatcher) deliver(j job) error {
+	backoff := d.Backoff
+	lastErr := ""
+	for attempt := 1; attempt <= d.MaxAttempts; attempt++ {
+		if d.ctx.Err() != nil {
+			return d.deadLetter(j.sub, j.event, attempt-1, strings.TrimSuffix("dispatcher closed; "+lastErr, "; "))
+		}
+
+		delivery := d.attempt(j.sub, j.event, j.body)
+		delivery.Attempt = attempt
+		if err := d.store.AddDelivery(&delivery); err != nil {
+			return err
+		}
+		if delivery.Succeeded {
+			return nil
+		}
+		lastErr = delivery.Error
+
+		if attempt < d.MaxAttempts {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-d.ctx.Done():
+				timer.Stop()
+			}
+			backoff *= 2
+			if backoff > d.MaxBackoff {
+				backoff = d.MaxBackoff
+			}
+		}
+	}
+
+	return d.deadLetter(j.sub, j.event, d.MaxAttempts, lastErr)
+}
+
+func (d *Dispatcher) deadLetter(sub Subscription, e repository.Event, attempts int, lastErr string) error {
+	return d.store.AddDeadLetter(&DeadLetter{
+		SubscriptionID: sub.ID,
+		Event:          e,
+		Attempts:       attempts,
+		LastError:      lastErr,
+		FailedAt:       d.now().UTC(),
+	})
+}
+
+func (d *Dispatcher) attempt(sub Subscription, e repository.Event, body []byte) Delivery {
+	start := d.now()
+	delivery := Delivery{
+		SubscriptionID: sub.ID,
+		EventID:        e.ID,
+		AttemptedAt:    start.UTC(),
+	}
+
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-ID", strconv.FormatUint(e.ID, 10))
+	req.Header.Set("X-Webhook-Event", e.Type)
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, start, body))
+
+	resp, err := d.client.Do(req)
+	delivery.Duration = d.now().Sub(start)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	resp.Body.Close()
+
+	delivery.StatusCode = resp.StatusCode
+	delivery.Succeeded = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !delivery.Succeeded {
+		delivery.Error = fmt.Sprintf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return delivery
+}
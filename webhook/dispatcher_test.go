@@ -0,0 +1,212 @@
+// go:build unit
+package webhook_test
+
+import (
+	"context"
+	"gotest/repository"
+	"gotest/webhook"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newDispatcher(store webhook.Store) *webhook.Dispatcher {
+	dispatcher := webhook.NewDispatcher(store)
+	dispatcher.MaxAttempts = 3
+	dispatcher.Backoff = time.Millisecond
+	dispatcher.Allow = func(netip.Addr) bool { return true }
+	return dispatcher
+}
+
+func TestDispatcherPublish(t *testing.T) {
+	e := repository.Event{ID: 7, Type: "FundsDeposited", WalletID: 1, Payload: []byte(`{"amount":100,"balance":1100}`)}
+
+	t.Run("Signed Delivery", func(t *testing.T) {
+		// arrange
+		var verifyErr error
+		var eventHeader string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			verifyErr = webhook.VerifySignature("secret", r.Header.Get(webhook.SignatureHeader), body, time.Minute, time.Now())
+			eventHeader = r.Header.Get("X-Webhook-Event")
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		store := webhook.NewMemoryStore()
+		sub := webhook.Subscription{URL: server.URL, Secret: "secret"}
+		store.CreateSubscription(&sub)
+		// act
+		dispatcher := newDispatcher(store)
+		err := dispatcher.Publish(e)
+		dispatcher.Close(context.Background())
+		// assert
+		assert.NoError(t, err)
+		assert.NoError(t, verifyErr)
+		assert.Equal(t, "FundsDeposited", eventHeader)
+		deliveries, _ := store.ListDeliveries(sub.ID)
+		assert.Len(t, deliveries, 1)
+		assert.True(t, deliveries[0].Succeeded)
+	})
+
+	t.Run("Retry Until Success", func(t *testing.T) {
+		// arrange
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		store := webhook.NewMemoryStore()
+		sub := webhook.Subscription{URL: server.URL, Secret: "secret"}
+		store.CreateSubscription(&sub)
+		// act
+		dispatcher := newDispatcher(store)
+		err := dispatcher.Publish(e)
+		dispatcher.Close(context.Background())
+		// assert
+		assert.NoError(t, err)
+		deliveries, _ := store.ListDeliveries(sub.ID)
+		assert.Len(t, deliveries, 3)
+		assert.Equal(t, 503, deliveries[0].StatusCode)
+		assert.True(t, deliveries[2].Succeeded)
+		letters, _ := store.ListDeadLetters(sub.ID)
+		assert.Empty(t, letters)
+	})
+
+	t.Run("Dead Letter After Attempts Exhausted", func(t *testing.T) {
+		// arrange
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		store := webhook.NewMemoryStore()
+		sub := webhook.Subscription{URL: server.URL, Secret: "secret"}
+		store.CreateSubscription(&sub)
+		// act
+		dispatcher := newDispatcher(store)
+		err := dispatcher.Publish(e)
+		dispatcher.Close(context.Background())
+		// assert
+		assert.NoError(t, err)
+		letters, _ := store.ListDeadLetters(sub.ID)
+		assert.Len(t, letters, 1)
+		assert.Equal(t, e.ID, letters[0].Event.ID)
+		assert.Equal(t, 3, letters[0].Attempts)
+	})
+
+	t.Run("Refuse Loopback Destination", func(t *testing.T) {
+		// arrange
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+		}))
+		defer server.Close()
+
+		store := webhook.NewMemoryStore()
+		sub := webhook.Subscription{URL: server.URL, Secret: "secret"}
+		store.CreateSubscription(&sub)
+		dispatcher := newDispatcher(store)
+		dispatcher.Allow = webhook.Public
+		// act
+		err := dispatcher.Publish(e)
+		dispatcher.Close(context.Background())
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+		letters, _ := store.ListDeadLetters(sub.ID)
+		assert.Len(t, letters, 1)
+		assert.Contains(t, letters[0].LastError, webhook.ErrForbiddenDestination.Error())
+	})
+
+	t.Run("Skip Unsubscribed Event Types", func(t *testing.T) {
+		// arrange
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+		}))
+		defer server.Close()
+
+		store := webhook.NewMemoryStore()
+		sub := webhook.Subscription{URL: server.URL, Secret: "secret", EventTypes: []string{"WalletOpened"}}
+		store.CreateSubscription(&sub)
+		// act
+		dispatcher := newDispatcher(store)
+		err := dispatcher.Publish(e)
+		dispatcher.Close(context.Background())
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("Slow Endpoint Does Not Hold Back Others", func(t *testing.T) {
+		// arrange
+		release := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer slow.Close()
+		delivered := make(chan struct{}, 1)
+		fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			delivered <- struct{}{}
+		}))
+		defer fast.Close()
+
+		store := webhook.NewMemoryStore()
+		store.CreateSubscription(&webhook.Subscription{URL: slow.URL, Secret: "secret"})
+		store.CreateSubscription(&webhook.Subscription{URL: fast.URL, Secret: "secret"})
+		dispatcher := newDispatcher(store)
+		// act
+		start := time.Now()
+		err := dispatcher.Publish(e)
+		published := time.Since(start)
+		// assert
+		assert.NoError(t, err)
+		assert.Less(t, published, time.Second)
+		select {
+		case <-delivered:
+		case <-time.After(time.Second):
+			t.Error("fast endpoint not delivered while the slow one hangs")
+		}
+		close(release)
+		dispatcher.Close(context.Background())
+	})
+
+	t.Run("Close Cuts Backoff Short", func(t *testing.T) {
+		// arrange
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		store := webhook.NewMemoryStore()
+		sub := webhook.Subscription{URL: server.URL, Secret: "secret"}
+		store.CreateSubscription(&sub)
+		dispatcher := newDispatcher(store)
+		dispatcher.Backoff = time.Hour
+		dispatcher.Publish(e)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		// act
+		start := time.Now()
+		err := dispatcher.Close(ctx)
+		// assert
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+		letters, _ := store.ListDeadLetters(sub.ID)
+		assert.Len(t, letters, 1)
+		assert.Equal(t, 1, letters[0].Attempts)
+		assert.ErrorIs(t, dispatcher.Publish(e), webhook.ErrClosed)
+	})
+}
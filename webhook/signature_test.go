@@ -0,0 +1,44 @@
+// go:build unit
+package webhook_test
+
+import (
+	"gotest/webhook"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":1}`)
+	header := webhook.Sign("secret", now, body)
+
+	t.Run("Successful", func(t *testing.T) {
+		// act
+		err := webhook.VerifySignature("secret", header, body, 5*time.Minute, now.Add(time.Minute))
+		// assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Wrong Secret", func(t *testing.T) {
+		// act
+		err := webhook.VerifySignature("other", header, body, 5*time.Minute, now)
+		// assert
+		assert.ErrorIs(t, err, webhook.ErrInvalidSignature)
+	})
+
+	t.Run("Modified Body", func(t *testing.T) {
+		// act
+		err := webhook.VerifySignature("secret", header, []byte(`{"id":2}`), 5*time.Minute, now)
+		// assert
+		assert.ErrorIs(t, err, webhook.ErrInvalidSignature)
+	})
+
+	t.Run("Expired Timestamp", func(t *testing.T) {
+		// act
+		err := webhook.VerifySignature("secret", header, body, 5*time.Minute, now.Add(time.Hour))
+		// assert
+		assert.ErrorIs(t, err, webhook.ErrInvalidSignature)
+	})
+}
@@ -0,0 +1,187 @@
+package webhook
+
+import (
+	"errors"
+	"gotest/repository"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+type Subscription struct {
+	ID         uint64    `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (s Subscription) Accepts(eventType string) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type Delivery struct {
+	ID             uint64        `json:"id"`
+	SubscriptionID uint64        `json:"subscription_id"`
+	EventID        uint64        `json:"event_id"`
+	Attempt        int           `json:"attempt"`
+	StatusCode     int           `json:"status_code,omitempty"`
+	Error          string        `json:"error,omitempty"`
+	Succeeded      bool          `json:"succeeded"`
+	Duration       time.Duration `json:"duration"`
+	AttemptedAt    time.Time     `json:"attempted_at"`
+}
+
+type DeadLetter struct {
+	ID             uint64           `json:"id"`
+	SubscriptionID uint64           `json:"subscription_id"`
+	Event          repository.Event `json:"event"`
+	Attempts       int              `json:"attempts"`
+	LastError      string           `json:"last_error"`
+	FailedAt       time.Time        `json:"failed_at"`
+}
+
+type Store interface {
+	CreateSubscription(sub *Subscription) error
+	GetSubscription(id uint64) (*Subscription, error)
+	ListSubscriptions() ([]Subscription, error)
+	DeleteSubscription(id uint64) error
+	AddDelivery(delivery *Delivery) error
+	ListDeliveries(subscriptionID uint64) ([]Delivery, error)
+	AddDeadLetter(letter *DeadLetter) error
+	ListDeadLetters(subscriptionID uint64) ([]DeadLetter, error)
+}
+
+// The memory store keeps only the most recent deliveries and dead letters,
+// across all subscriptions, so a failing endpoint cannot grow it forever.
+const (
+	DeliveryLimit   = 1000
+	DeadLetterLimit = 1000
+)
+
+type memoryStore struct {
+	mu               sync.RWMutex
+	subscriptions    map[uint64]Subscription
+	deliveries       []Delivery
+	deadLetters      []DeadLetter
+	lastID           uint64
+	lastDeliveryID   uint64
+	lastDeadLetterID uint64
+}
+
+// NewMemoryStore keeps everything in process memory: subscriptions are lost
+// on restart and are not shared between instances, so subscribers have to
+// register again after a deploy.
+func NewMemoryStore() *memoryStore {
+	return &memoryStore{subscriptions: map[uint64]Subscription{}}
+}
+
+func (s *memoryStore) CreateSubscription(sub *Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	sub.ID = s.lastID
+	s.subscriptions[sub.ID] = *sub
+	return nil
+}
+
+func (s *memoryStore) GetSubscription(id uint64) (*Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sub, ok := s.subscriptions[id]
+	if !ok {
+		return nil, ErrSubscriptionNotFound
+	}
+	return &sub, nil
+}
+
+func (s *memoryStore) ListSubscriptions() ([]Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subs := []Subscription{}
+	for id := uint64(1); id <= s.lastID; id++ {
+		if sub, ok := s.subscriptions[id]; ok {
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
+func (s *memoryStore) DeleteSubscription(id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscriptions[id]; !ok {
+		return ErrSubscriptionNotFound
+	}
+	delete(s.subscriptions, id)
+	s.deliveries = slices.DeleteFunc(s.deliveries, func(d Delivery) bool { return d.SubscriptionID == id })
+	s.deadLetters = slices.DeleteFunc(s.deadLetters, func(l DeadLetter) bool { return l.SubscriptionID == id })
+	return nil
+}
+
+func (s *memoryStore) AddDelivery(delivery *Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastDeliveryID++
+	delivery.ID = s.lastDeliveryID
+	s.deliveries = keepLast(append(s.deliveries, *delivery), DeliveryLimit)
+	return nil
+}
+
+func (s *memoryStore) ListDeliveries(subscriptionID uint64) ([]Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := []Delivery{}
+	for _, delivery := range s.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (s *memoryStore) AddDeadLetter(letter *DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastDeadLetterID++
+	letter.ID = s.lastDeadLetterID
+	s.deadLetters = keepLast(append(s.deadLetters, *letter), DeadLetterLimit)
+	return nil
+}
+
+func (s *memoryStore) ListDeadLetters(subscriptionID uint64) ([]DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	letters := []DeadLetter{}
+	for _, letter := range s.deadLetters {
+		if letter.SubscriptionID == subscriptionID {
+			letters = append(letters, letter)
+		}
+	}
+	return letters, nil
+}
+
+func keepLast[T any](items []T, limit int) []T {
+	if len(items) <= limit {
+		return items
+	}
+	return append(items[:0], items[len(items)-limit:]...)
+}
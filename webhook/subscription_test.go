@@ -0,0 +1,58 @@
+// go:build unit
+package webhook_test
+
+import (
+	"gotest/webhook"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	t.Run("Keep Latest Deliveries", func(t *testing.T) {
+		// arrange
+		store := webhook.NewMemoryStore()
+		sub := webhook.Subscription{URL: "https://partner.example/hooks"}
+		store.CreateSubscription(&sub)
+		// act
+		for i := 0; i < webhook.DeliveryLimit+10; i++ {
+			store.AddDelivery(&webhook.Delivery{SubscriptionID: sub.ID})
+		}
+		// assert
+		deliveries, _ := store.ListDeliveries(sub.ID)
+		assert.Len(t, deliveries, webhook.DeliveryLimit)
+		assert.Equal(t, uint64(11), deliveries[0].ID)
+		assert.Equal(t, uint64(webhook.DeliveryLimit+10), deliveries[len(deliveries)-1].ID)
+	})
+
+	t.Run("Keep Latest Dead Letters", func(t *testing.T) {
+		// arrange
+		store := webhook.NewMemoryStore()
+		sub := webhook.Subscription{URL: "https://partner.example/hooks"}
+		store.CreateSubscription(&sub)
+		// act
+		for i := 0; i < webhook.DeadLetterLimit+1; i++ {
+			store.AddDeadLetter(&webhook.DeadLetter{SubscriptionID: sub.ID})
+		}
+		// assert
+		letters, _ := store.ListDeadLetters(sub.ID)
+		assert.Len(t, letters, webhook.DeadLetterLimit)
+		assert.Equal(t, uint64(2), letters[0].ID)
+	})
+
+	t.Run("Delete Drops Delivery Log", func(t *testing.T) {
+		// arrange
+		store := webhook.NewMemoryStore()
+		sub := webhook.Subscription{URL: "https://partner.example/hooks"}
+		store.CreateSubscription(&sub)
+		store.AddDelivery(&webhook.Delivery{SubscriptionID: sub.ID})
+		store.AddDeadLetter(&webhook.DeadLetter{SubscriptionID: sub.ID})
+		// act
+		store.DeleteSubscription(sub.ID)
+		// assert
+		deliveries, _ := store.ListDeliveries(sub.ID)
+		letters, _ := store.ListDeadLetters(sub.ID)
+		assert.Empty(t, deliveries)
+		assert.Empty(t, letters)
+	})
+}